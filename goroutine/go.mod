@@ -2,4 +2,4 @@ module golang-knowledge/goroutine
 
 go 1.25.4
 
-require github.com/google/uuid v1.6.0 // indirect
+require github.com/google/uuid v1.6.0
@@ -45,6 +45,9 @@ func main() {
 	defer cancel()
 	_ = app.HTTP.Stop(shutdownCtx)
 	<-srvDone
+	if err := app.Close(); err != nil {
+		app.Log.Error("failed to close app", zap.Error(err))
+	}
 	_ = app.Log.Sync()
 	os.Exit(0)
 }
@@ -0,0 +1,71 @@
+// Command migrate applies or reverts the im-server database schema.
+//
+//	migrate -dsn postgres://... up
+//	migrate -dsn postgres://... down [steps]
+//	migrate -dsn postgres://... version
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/db"
+	"github.com/jackc/pgx/v5"
+)
+
+func main() {
+	dsn := flag.String("dsn", os.Getenv("POSTGRES_DSN"), "PostgreSQL connection string")
+	flag.Parse()
+	if err := run(context.Background(), *dsn, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, dsn string, args []string) error {
+	if dsn == "" || len(args) == 0 {
+		return fmt.Errorf("usage: migrate -dsn DSN up | down [steps] | version")
+	}
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("applied %d migrations\n", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("bad step count %q", args[1])
+			}
+		}
+		n, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migrations\n", n)
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println(version)
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	return nil
+}
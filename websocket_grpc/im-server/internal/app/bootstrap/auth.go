@@ -0,0 +1,100 @@
+package bootstrap
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/token"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+)
+
+// newAuth builds the token service used for login and the authenticator
+// guarding the websocket endpoint according to cfg.AuthMode.
+func newAuth(cfg *Config, tokenRepository token.TokenRepository) (*auth.TokenService, auth.Authenticator, error) {
+	var tokens *auth.TokenService
+	switch cfg.AuthMode {
+	case "", AuthModeOpaque:
+		tokens = auth.NewTokenService(tokenRepository, cfg.TokenTTL)
+	case AuthModeJWT:
+		keys := make([]auth.Key, 0, len(cfg.JWT.Keys))
+		for _, kc := range cfg.JWT.Keys {
+			k, err := kc.key()
+			if err != nil {
+				return nil, nil, err
+			}
+			keys = append(keys, k)
+		}
+		signer, err := auth.NewJWTAuthenticator(auth.JWTOptions{
+			Issuer:       cfg.JWT.Issuer,
+			Audience:     cfg.JWT.Audience,
+			Keys:         keys,
+			SigningKeyID: cfg.JWT.SigningKeyID,
+			Leeway:       cfg.JWT.Leeway,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		tokens = auth.NewSignedTokenService(tokenRepository, cfg.TokenTTL, signer)
+	default:
+		return nil, nil, fmt.Errorf("unknown auth mode %q", cfg.AuthMode)
+	}
+
+	if cfg.AllowDummyAuth {
+		return tokens, auth.ChainAuthenticator{tokens, auth.NewDummyAuthenticator()}, nil
+	}
+	return tokens, tokens, nil
+}
+
+func (kc JWTKeyConfig) key() (auth.Key, error) {
+	decode := func(field, s string) ([]byte, error) {
+		if s == "" {
+			return nil, nil
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: bad %s: %w", kc.ID, field, err)
+		}
+		return b, nil
+	}
+
+	switch kc.Algorithm {
+	case auth.AlgHS256:
+		secret, err := decode("secret", kc.Secret)
+		if err != nil {
+			return auth.Key{}, err
+		}
+		if len(secret) < 32 {
+			return auth.Key{}, fmt.Errorf("jwt key %q: HS256 secret must be at least 32 bytes", kc.ID)
+		}
+		return auth.NewHMACKey(kc.ID, secret), nil
+	case auth.AlgEdDSA:
+		pub, err := decode("public_key", kc.PublicKey)
+		if err != nil {
+			return auth.Key{}, err
+		}
+		priv, err := decode("private_key", kc.PrivateKey)
+		if err != nil {
+			return auth.Key{}, err
+		}
+		var privKey ed25519.PrivateKey
+		switch len(priv) {
+		case 0:
+		case ed25519.SeedSize:
+			privKey = ed25519.NewKeyFromSeed(priv)
+		case ed25519.PrivateKeySize:
+			privKey = ed25519.PrivateKey(priv)
+		default:
+			return auth.Key{}, fmt.Errorf("jwt key %q: bad private_key size", kc.ID)
+		}
+		if pub != nil && len(pub) != ed25519.PublicKeySize {
+			return auth.Key{}, fmt.Errorf("jwt key %q: bad public_key size", kc.ID)
+		}
+		if pub == nil && privKey == nil {
+			return auth.Key{}, fmt.Errorf("jwt key %q: public_key or private_key is required", kc.ID)
+		}
+		return auth.NewEd25519Key(kc.ID, pub, privKey), nil
+	default:
+		return auth.Key{}, fmt.Errorf("jwt key %q: unsupported alg %q", kc.ID, kc.Algorithm)
+	}
+}
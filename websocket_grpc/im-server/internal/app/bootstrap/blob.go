@@ -0,0 +1,33 @@
+package bootstrap
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/filesystem"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+)
+
+// newBlobs builds the attachment store of cfg, or returns nil when
+// attachments are disabled.
+func newBlobs(cfg *Config) (*blob.BlobService, error) {
+	if cfg.Blob.Dir == "" {
+		return nil, nil
+	}
+	store, err := filesystem.NewBlobStore(cfg.Blob.Dir)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.Blob.SigningKey)
+	if err != nil {
+		return nil, fmt.Errorf("blob signing key: %w", err)
+	}
+	return blob.NewBlobService(store, blob.Options{
+		SigningKey:     key,
+		BaseURL:        strings.TrimSuffix(cfg.Blob.BaseURL, "/"),
+		URLTTL:         cfg.Blob.URLTTL,
+		MaxUploadBytes: cfg.Blob.MaxUploadBytes,
+		UserQuotaBytes: cfg.Blob.UserQuotaBytes,
+	})
+}
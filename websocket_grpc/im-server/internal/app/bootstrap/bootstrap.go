@@ -2,34 +2,189 @@ package bootstrap
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/admin"
+	blobtransport "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/blob"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/amqp"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/sqlite"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/pkg/httpx"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/pkg/observability"
-	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/dedup"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/presence"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/receipt"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/revision"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/user"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
 type App struct {
-	HTTP *httpx.Server
-	WS   *ws.Server
-	Log  *zap.Logger
+	HTTP   *httpx.Server
+	WS     *ws.Server
+	Log    *zap.Logger
+	Bus    cluster.Bus
+	Push   *push.PushService
+	DB     *pgxpool.Pool // nil unless PostgresDSN is set
+	SQLite *sql.DB       // nil unless SQLitePath is used
 }
 
 func New(ctx context.Context, cfg *Config) (*App, error) {
 	log := observability.NewLogger()
 
-	reg := ws.NewRegistry()
-	authn := auth.NewDummyAuthenticator()
+	var (
+		pool *pgxpool.Pool
+		db   *sql.DB
+	)
+	switch {
+	case cfg.PostgresDSN != "":
+		var err error
+		if pool, err = newPool(ctx, cfg, log); err != nil {
+			return nil, err
+		}
+	case cfg.SQLitePath != "":
+		var err error
+		if db, err = sqlite.Open(cfg.SQLitePath); err != nil {
+			return nil, err
+		}
+	}
+	repos := newRepositories(pool, db)
+
+	directory := repos.directory
+	// Drop the sessions a crash of this node left behind in a shared directory.
+	if err := directory.ClearNode(ctx, cfg.NodeID); err != nil {
+		return nil, err
+	}
+	local, err := newRegistry(cfg)
+	if err != nil {
+		return nil, err
+	}
+	reg := cluster.NewPresenceRegistry(local, directory, cfg.NodeID)
+	tokens, authn, err := newAuth(cfg, repos.tokens)
+	if err != nil {
+		return nil, err
+	}
+	userSvc := user.NewUserService(repos.users, tokens, user.Options{})
+	messageRepo := repos.messages
+	inboxRepo := repos.inboxes
+	inboxSvc := inbox.NewInboxService(inboxRepo, messageRepo, repos.transactor)
+	friendSvc := friend.NewFriendService(repos.friends, repos.users, repos.transactor)
+	groupSvc := group.NewGroupService(repos.groups, repos.users, repos.transactor)
+	historySvc := history.NewHistoryService(messageRepo, groupSvc)
+	receiptSvc := receipt.NewReceiptService(repos.readCursors, messageRepo, historySvc, groupSvc)
+	revisionSvc := revision.NewRevisionService(messageRepo, inboxRepo, repos.transactor, revision.Options{
+		RecallWindow: cfg.RecallWindow,
+		EditWindow:   cfg.EditWindow,
+	})
+	dedupSvc := dedup.NewDedupService(repos.clientSends, dedup.Options{
+		Window:       cfg.SendDedupWindow,
+		MaxPerSender: cfg.SendDedupMax,
+		Logger:       log,
+	})
+	blobSvc, err := newBlobs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	pushSvc, err := newPush(cfg, groupSvc, log)
+	if err != nil {
+		return nil, err
+	}
+
+	var bus cluster.Bus
+	if cfg.BusURL != "" {
+		if bus, err = amqp.NewBus(cfg.BusURL, log); err != nil {
+			return nil, err
+		}
+	}
+	router := cluster.NewRouter(cluster.RouterOptions{
+		NodeID:    cfg.NodeID,
+		Registry:  reg,
+		Directory: directory,
+		Bus:       bus,
+		Logger:    log,
+	})
+	if err := router.Start(ctx); err != nil {
+		if bus != nil {
+			_ = bus.Close()
+		}
+		if pushSvc != nil {
+			_ = pushSvc.Close()
+		}
+		if pool != nil {
+			pool.Close()
+		}
+		if db != nil {
+			_ = db.Close()
+		}
+		return nil, err
+	}
+	// The router only looks sessions up, so it keeps the plain registry while
+	// the ws server binds through the one reporting presence changes.
+	presenceSvc := presence.NewPresenceService(router, friendSvc, presence.PresenceOptions{
+		Grace:  cfg.PresenceGrace,
+		Logger: log,
+	})
+	wsReg := presence.NewRegistry(reg, presenceSvc, directory)
+	typingSvc := presence.NewTypingService(historySvc, groupSvc, friendSvc, presence.TypingOptions{
+		Interval:     cfg.TypingInterval,
+		MaxGroupSize: cfg.TypingMaxGroupSize,
+	})
+
+	dispatcher := dispatch.NewDefaultDispatcher(dispatch.Deps{
+		Registry:  reg,
+		Router:    router,
+		Inbox:     inboxSvc,
+		Sends:     dedupSvc,
+		Friends:   friendSvc,
+		Groups:    groupSvc,
+		Users:     userSvc,
+		History:   historySvc,
+		Receipts:  receiptSvc,
+		Typing:    typingSvc,
+		Revisions: revisionSvc,
+		Content: dispatch.ContentLimits{
+			MaxTextBytes:   cfg.Content.MaxTextBytes,
+			MaxMentions:    cfg.Content.MaxMentions,
+			MaxCustomBytes: cfg.Content.MaxCustomBytes,
+			MaxImageBytes:  cfg.Content.MaxImageBytes,
+			MaxFileBytes:   cfg.Content.MaxFileBytes,
+			MaxAudioBytes:  cfg.Content.MaxAudioBytes,
+		},
+		Blobs: blobSvc,
+		Push:  pushSvc,
+	})
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
+	mux.Handle("/readyz", readyHandler(pool, bus, log))
 	mux.Handle("/metrics", observability.MetricsHandler())
+	if cfg.AdminToken != "" {
+		mux.Handle("/admin/", admin.NewHandler(admin.Options{
+			Token:  cfg.AdminToken,
+			Tokens: tokens,
+			Router: router,
+			Logger: log,
+		}))
+	}
+
+	if blobSvc != nil {
+		mux.Handle(blob.PathPrefix, blobtransport.NewHandler(blobtransport.Options{Blobs: blobSvc, Logger: log}))
+	}
 
 	wsServer := ws.NewServer(ws.ServerOptions{
 		NodeID:         cfg.NodeID,
@@ -40,8 +195,16 @@ func New(ctx context.Context, cfg *Config) (*App, error) {
 		PingInterval:   cfg.PingInterval,
 		PongWait:       cfg.PongWait,
 		Logger:         log,
-		Registry:       reg,
+		Registry:       wsReg,
+		Router:         router,
 		Authenticator:  authn,
+		AllowAnonymous: cfg.AllowAnonymous,
+		Inbox:          inboxSvc,
+		Retransmit: ws.RetransmitPolicy{
+			InitialBackoff: cfg.RetransmitInitialBackoff,
+			MaxBackoff:     cfg.RetransmitMaxBackoff,
+		},
+		Dispatch: dispatcher,
 	})
 	mux.Handle(cfg.WSPath, wsServer)
 
@@ -51,5 +214,25 @@ func New(ctx context.Context, cfg *Config) (*App, error) {
 		Logger:  log,
 	})
 
-	return &App{HTTP: httpServer, WS: wsServer, Log: log}, nil
+	return &App{HTTP: httpServer, WS: wsServer, Log: log, Bus: bus, Push: pushSvc, DB: pool, SQLite: db}, nil
+}
+
+// Close releases the connections opened by New. Call it after the HTTP server stopped.
+func (a *App) Close() error {
+	var errs []error
+	// Flush the notifications of the last messages before leaving.
+	if a.Push != nil {
+		errs = append(errs, a.Push.Close())
+	}
+	if a.Bus != nil {
+		errs = append(errs, a.Bus.Close())
+	}
+	// Last, as closing waits for the connections still in use.
+	if a.DB != nil {
+		a.DB.Close()
+	}
+	if a.SQLite != nil {
+		errs = append(errs, a.SQLite.Close())
+	}
+	return errors.Join(errs...)
 }
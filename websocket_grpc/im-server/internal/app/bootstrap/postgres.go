@@ -0,0 +1,114 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/db"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultConnectTimeout = 5 * time.Second
+	defaultConnectBackoff = time.Second
+)
+
+// newPool connects to cfg.PostgresDSN, retrying while the database comes up,
+// and migrates it to the embedded schema.
+func newPool(ctx context.Context, cfg *Config, log *zap.Logger) (*pgxpool.Pool, error) {
+	pc, err := pgxpool.ParseConfig(cfg.PostgresDSN)
+	if err != nil {
+		return nil, err
+	}
+	opts := cfg.Postgres
+	if opts.MaxConns > 0 {
+		pc.MaxConns = opts.MaxConns
+	}
+	if opts.MinConns > 0 {
+		pc.MinConns = opts.MinConns
+	}
+	if opts.MaxConnLifetime > 0 {
+		pc.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		pc.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+	if opts.HealthCheckPeriod > 0 {
+		pc.HealthCheckPeriod = opts.HealthCheckPeriod
+	}
+	if opts.ConnectTimeout <= 0 {
+		opts.ConnectTimeout = defaultConnectTimeout
+	}
+	pc.ConnConfig.ConnectTimeout = opts.ConnectTimeout
+
+	// The pool connects lazily, so this does not touch the database yet.
+	pool, err := pgxpool.NewWithConfig(ctx, pc)
+	if err != nil {
+		return nil, err
+	}
+	if err := waitForDatabase(ctx, pool, opts, log); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	if err := migrate(ctx, pool, log); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// waitForDatabase pings pool until it answers, doubling the wait between
+// attempts.
+func waitForDatabase(ctx context.Context, pool *pgxpool.Pool, opts PostgresConfig, log *zap.Logger) error {
+	attempts := max(opts.ConnectAttempts, 1)
+	backoff := opts.ConnectBackoff
+	if backoff <= 0 {
+		backoff = defaultConnectBackoff
+	}
+	for attempt := 1; ; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, opts.ConnectTimeout)
+		err := pool.Ping(pingCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			return fmt.Errorf("database unreachable after %d attempts: %w", attempt, err)
+		}
+		log.Warn("database unreachable, retrying",
+			zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// migrate brings the schema up to date. It holds one connection throughout
+// because the migration lock belongs to the session.
+func migrate(ctx context.Context, pool *pgxpool.Pool, log *zap.Logger) error {
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	migrator, err := db.NewMigrator(conn.Conn())
+	if err != nil {
+		return err
+	}
+	n, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info("database schema ready", zap.Int("applied", n), zap.Int64("version", version))
+	return nil
+}
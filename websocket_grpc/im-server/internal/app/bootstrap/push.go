@@ -0,0 +1,36 @@
+package bootstrap
+
+import (
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/filesystem"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/webhook"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
+	"go.uber.org/zap"
+)
+
+// newPush builds the push notifier of cfg, or returns nil when push is
+// disabled.
+func newPush(cfg *Config, groups *group.GroupService, log *zap.Logger) (*push.PushService, error) {
+	var notifier push.Notifier
+	switch {
+	case cfg.Push.WebhookURL != "":
+		notifier = webhook.NewNotifier(webhook.Options{
+			URL:    cfg.Push.WebhookURL,
+			Secret: []byte(cfg.Push.WebhookSecret),
+		})
+	case cfg.Push.File != "":
+		var err error
+		if notifier, err = filesystem.NewPushLog(cfg.Push.File); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+	return push.NewPushService(notifier, groups, push.Options{
+		BatchSize:     cfg.Push.BatchSize,
+		FlushInterval: cfg.Push.FlushInterval,
+		DedupWindow:   cfg.Push.DedupWindow,
+		MaxAttempts:   cfg.Push.MaxAttempts,
+		Logger:        log,
+	}), nil
+}
@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+const readyTimeout = 2 * time.Second
+
+// readyHandler answers /readyz: unlike /healthz it fails while the database
+// does not answer or the bus to the other nodes is down, so load balancers
+// stop sending clients here. A node without them is always ready.
+func readyHandler(pool *pgxpool.Pool, bus cluster.Bus, log *zap.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if pool != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+			defer cancel()
+			if err := pool.Ping(ctx); err != nil {
+				log.Warn("readiness check failed", zap.Error(err))
+				http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		if bus != nil {
+			if err := bus.Ready(); err != nil {
+				log.Warn("readiness check failed", zap.Error(err))
+				http.Error(w, "bus unavailable", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}
+}
@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"go.uber.org/zap"
+)
+
+// downBus is a bus whose broker connection is lost.
+type downBus struct {
+	cluster.Bus
+	err error
+}
+
+func (b *downBus) Ready() error {
+	return b.err
+}
+
+func TestReadyHandler_Bus(t *testing.T) {
+	bus := &downBus{err: errors.New("reconnecting")}
+	h := readyHandler(nil, bus, zap.NewNop())
+
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 while the bus is down, got %d", rec.Code)
+	}
+
+	bus.err = nil
+	rec = httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 once the bus is back, got %d", rec.Code)
+	}
+}
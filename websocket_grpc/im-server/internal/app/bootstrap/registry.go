@@ -0,0 +1,47 @@
+package bootstrap
+
+import (
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+)
+
+// newRegistry builds the node-local session registry enforcing the device
+// policies of cfg.
+func newRegistry(cfg *Config) (ws.Registry, error) {
+	def, err := cfg.DevicePolicy.policy()
+	if err != nil {
+		return nil, err
+	}
+	perUser := make(map[string]ws.DevicePolicy, len(cfg.UserDevicePolicies))
+	for userID, pc := range cfg.UserDevicePolicies {
+		p, err := pc.policy()
+		if err != nil {
+			return nil, fmt.Errorf("device policy of %s: %w", userID, err)
+		}
+		perUser[userID] = p
+	}
+
+	return ws.NewRegistryWithPolicy(func(userID string) ws.DevicePolicy {
+		if p, ok := perUser[userID]; ok {
+			return p
+		}
+		return def
+	}), nil
+}
+
+func (c DevicePolicyConfig) policy() (ws.DevicePolicy, error) {
+	if c.MaxDevices < 0 {
+		return ws.DevicePolicy{}, fmt.Errorf("max_devices must not be negative")
+	}
+	switch c.Mode {
+	case "", DeviceModeMulti:
+		return ws.DevicePolicy{Mode: ws.DeviceModeMulti, MaxDevices: c.MaxDevices}, nil
+	case DeviceModePerPlatform:
+		return ws.DevicePolicy{Mode: ws.DeviceModePerPlatform}, nil
+	case DeviceModeSingle:
+		return ws.DevicePolicy{Mode: ws.DeviceModeSingle}, nil
+	default:
+		return ws.DevicePolicy{}, fmt.Errorf("unknown device mode %q", c.Mode)
+	}
+}
@@ -0,0 +1,72 @@
+package bootstrap
+
+import (
+	"database/sql"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/postgres"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/sqlite"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	friendrepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/friend"
+	grouprepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/group"
+	inboxrepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+	messagerepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	presencerepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/presence"
+	receiptrepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/receipt"
+	tokenrepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/token"
+	userrepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type repositories struct {
+	users       userrepo.UserRepository
+	tokens      tokenrepo.TokenRepository
+	friends     friendrepo.FriendRepository
+	groups      grouprepo.GroupRepository
+	messages    messagerepo.MessageRepository
+	inboxes     inboxrepo.InboxRepository
+	readCursors receiptrepo.ReadCursorRepository
+	clientSends messagerepo.ClientSendRepository
+	directory   presencerepo.Directory
+	transactor  respository.Transactor
+}
+
+// newRepositories stores everything in pool when it is set. Otherwise db,
+// when set, holds users, groups, messages and inboxes, and the rest is kept
+// in memory.
+func newRepositories(pool *pgxpool.Pool, db *sql.DB) repositories {
+	if pool == nil {
+		repos := repositories{
+			users:       memory.NewUserRepository(),
+			tokens:      memory.NewTokenRepository(),
+			friends:     memory.NewFriendRepository(),
+			groups:      memory.NewGroupRepository(),
+			messages:    memory.NewMessageRepository(),
+			inboxes:     memory.NewInboxRepository(),
+			readCursors: memory.NewReadCursorRepository(),
+			clientSends: memory.NewClientSendRepository(),
+			directory:   memory.NewPresenceDirectory(),
+			transactor:  memory.NewTransactor(),
+		}
+		if db != nil {
+			repos.users = sqlite.NewUserRepository(db)
+			repos.groups = sqlite.NewGroupRepository(db)
+			repos.messages = sqlite.NewMessageRepository(db)
+			repos.inboxes = sqlite.NewInboxRepository(db)
+			repos.transactor = sqlite.NewTransactor(db)
+		}
+		return repos
+	}
+	return repositories{
+		users:       postgres.NewUserRepository(pool),
+		tokens:      postgres.NewTokenRepository(pool),
+		friends:     postgres.NewFriendRepository(pool),
+		groups:      postgres.NewGroupRepository(pool),
+		messages:    postgres.NewMessageRepository(pool),
+		inboxes:     postgres.NewInboxRepository(pool),
+		readCursors: postgres.NewReadCursorRepository(pool),
+		clientSends: postgres.NewClientSendRepository(pool),
+		directory:   postgres.NewPresenceDirectory(pool),
+		transactor:  postgres.NewTransactor(pool),
+	}
+}
@@ -0,0 +1,80 @@
+// Package admin serves operator endpoints on the HTTP mux. Every request
+// must carry the configured admin token as a bearer token.
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"go.uber.org/zap"
+)
+
+type Options struct {
+	Token  string // bearer token required on every request
+	Tokens *auth.TokenService
+	Router contract.Router
+	Logger *zap.Logger
+}
+
+type Handler struct {
+	options Options
+	mux     *http.ServeMux
+}
+
+func NewHandler(options Options) *Handler {
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	h := &Handler{options: options, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /admin/revoke", h.revoke)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || h.options.Token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.options.Token)) == 1
+}
+
+// revoke invalidates the tokens of user_id, or only of device_id if given,
+// and closes the matching sessions on every node.
+func (h *Handler) revoke(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	deviceID := r.URL.Query().Get("device_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := h.options.Tokens.RevokeAll(r.Context(), userID, deviceID)
+	if err != nil {
+		h.options.Logger.Error("failed to revoke tokens", zap.String("user_id", userID), zap.Error(err))
+		http.Error(w, "failed to revoke tokens", http.StatusInternalServerError)
+		return
+	}
+	if h.options.Router != nil {
+		if err := h.options.Router.Disconnect(r.Context(), userID, deviceID, protocol.CloseRevoked, "token revoked"); err != nil {
+			h.options.Logger.Warn("failed to disconnect sessions", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+	h.options.Logger.Info("revoked tokens",
+		zap.String("user_id", userID), zap.String("device_id", deviceID), zap.Int64("revoked", revoked))
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]int64{"revoked": revoked})
+}
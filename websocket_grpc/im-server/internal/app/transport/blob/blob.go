@@ -0,0 +1,113 @@
+// Package blob serves the signed attachment URLs handed out over the
+// websocket. The URL itself is the credential: requests carry no token.
+package blob
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"go.uber.org/zap"
+)
+
+// defaultTransferTimeout replaces the server-wide read and write timeouts,
+// which are far too short for large attachments.
+const defaultTransferTimeout = 10 * time.Minute
+
+type Options struct {
+	Blobs           *blob.BlobService
+	TransferTimeout time.Duration
+	Logger          *zap.Logger
+}
+
+type Handler struct {
+	options Options
+	mux     *http.ServeMux
+}
+
+func NewHandler(options Options) *Handler {
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	if options.TransferTimeout <= 0 {
+		options.TransferTimeout = defaultTransferTimeout
+	}
+	h := &Handler{options: options, mux: http.NewServeMux()}
+	h.mux.HandleFunc("PUT "+blob.PathPrefix+"upload", h.upload)
+	h.mux.HandleFunc("GET "+blob.PathPrefix+"{id}", h.download)
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(h.options.TransferTimeout)
+	_ = rc.SetReadDeadline(deadline)
+	_ = rc.SetWriteDeadline(deadline)
+	h.mux.ServeHTTP(w, r)
+}
+
+// upload stores the request body and answers with the blob as JSON.
+func (h *Handler) upload(w http.ResponseWriter, r *http.Request) {
+	p, err := h.options.Blobs.VerifyUpload(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	b, err := h.options.Blobs.Upload(r.Context(), p.UserID, r.Header.Get("Content-Type"), r.Body)
+	switch {
+	case errors.Is(err, blob.ErrEmptyBlob):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, blob.ErrTooLarge), errors.Is(err, blob.ErrQuotaExceeded):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		h.options.Logger.Error("failed to store blob", zap.String("user_id", p.UserID), zap.Error(err))
+		http.Error(w, "failed to store blob", http.StatusInternalServerError)
+		return
+	}
+	h.options.Logger.Info("stored blob",
+		zap.String("user_id", p.UserID), zap.String("device_id", p.DeviceID),
+		zap.String("blob_id", b.ID), zap.Int64("size", b.Size))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"blob_id":      b.ID,
+		"content_type": b.ContentType,
+		"size":         b.Size,
+	})
+}
+
+// download serves a blob with range support. Blobs never change, so their
+// ID doubles as the ETag.
+func (h *Handler) download(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := h.options.Blobs.VerifyDownload(id, r.URL.Query()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	content, b, err := h.options.Blobs.Open(r.Context(), id)
+	if errors.Is(err, blob.ErrBlobNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.options.Logger.Error("failed to open blob", zap.String("blob_id", id), zap.Error(err))
+		http.Error(w, "failed to open blob", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", b.ContentType)
+	w.Header().Set("ETag", `"`+b.ID+`"`)
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	// User content must never run as a page of this origin.
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "sandbox")
+	http.ServeContent(w, r, "", b.CreatedAt, content)
+}
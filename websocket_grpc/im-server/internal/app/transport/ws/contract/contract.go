@@ -1,21 +1,64 @@
 package contract
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Session is the minimal, framework-agnostic view of a websocket connection
 // that business logic may need.
 type Session interface {
+	// UserID is empty on anonymous connections.
 	UserID() string
 	DeviceID() string
+	// Platform is the client platform the credential was issued for, such as
+	// "ios" or "web". It may be empty.
+	Platform() string
+	// TokenID identifies the credential the connection authenticated with,
+	// for revocation. Empty if there is nothing to revoke.
+	TokenID() string
+	// ExpiresAt is when the credential lapses; zero means never.
+	ExpiresAt() time.Time
+	// Refresh swaps the credential after a token refresh and moves the
+	// session expiry to expiresAt.
+	Refresh(tokenID string, expiresAt time.Time)
 	NodeID() string
 	Send(data []byte) error
+	// Deliver sends a message delivery and keeps retransmitting it until the
+	// client acknowledges messageID or the session closes.
+	Deliver(messageID int64, data []byte) error
+	// Acknowledge stops retransmitting messageID.
+	Acknowledge(messageID int64) bool
+	// Disconnect closes the connection with a websocket close code after
+	// writing the data already queued. It is safe to call from any goroutine.
+	Disconnect(code int, reason string)
 }
 
 // Registry is the minimal interface for looking up online sessions.
 // Keep it small to avoid pulling ws implementation details into business code.
 type Registry interface {
-	Bind(ctx context.Context, session Session) error
-	Unbind(ctx context.Context, userID, deviceID string) error
+	// Bind adds session and returns the sessions it displaced under the
+	// user's device policy, such as an older connection of the same device.
+	// Displaced sessions are already unbound; the caller closes them.
+	Bind(ctx context.Context, session Session) ([]Session, error)
+	// Displace unbinds and returns the sessions of userID the device policy
+	// does not allow next to a session of deviceID on platform that was
+	// bound on another node. The caller closes them.
+	Displace(ctx context.Context, userID, deviceID, platform string) ([]Session, error)
+	// Unbind removes session. It is a no-op if the session was displaced.
+	Unbind(ctx context.Context, session Session) error
 	GetUserSessions(ctx context.Context, userID string) ([]Session, error)
 }
 
+// Router delivers data to every session of a user, wherever it is connected.
+// A non-zero messageID marks a message delivery the client has to acknowledge.
+type Router interface {
+	Deliver(ctx context.Context, userID string, messageID int64, data []byte) error
+	// Online reports whether the user has a session on any node.
+	Online(ctx context.Context, userID string) (bool, error)
+	// Disconnect closes the user's sessions, or only those of deviceID if set.
+	Disconnect(ctx context.Context, userID, deviceID string, code int, reason string) error
+	// Displace kicks the sessions of userID on other nodes that the device
+	// policy does not allow next to a new session of deviceID on platform.
+	Displace(ctx context.Context, userID, deviceID, platform string) error
+}
@@ -6,19 +6,110 @@ import (
 
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
 	wshandler "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/handler"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
 	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/dedup"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/presence"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/receipt"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/revision"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/user"
 )
 
+// anonymousTypes are the only messages accepted before logging in.
+var anonymousTypes = map[imv1.MessageType]bool{
+	imv1.MessageType_ECHO:     true,
+	imv1.MessageType_REGISTER: true,
+	imv1.MessageType_LOGIN:    true,
+}
+
+// ContentLimits bounds the message bodies accepted from clients.
+type ContentLimits = wshandler.ContentLimits
+
+// Deps are the per-app dependencies handed to the default handlers.
+// Optional services may be nil; handlers then skip the related behaviour.
+type Deps struct {
+	Registry contract.Registry
+	// Router defaults to delivering to sessions of Registry only.
+	Router contract.Router
+	Inbox  *inbox.InboxService
+	// Friends, when set, limits direct messages to friends.
+	Friends  *friend.FriendService
+	Groups   *group.GroupService
+	Users    *user.UserService
+	History  *history.HistoryService
+	Receipts *receipt.ReceiptService
+	Typing   *presence.TypingService
+	// Revisions serves message recalls and edits.
+	Revisions *revision.RevisionService
+	// Content limits the messages clients send; zero fields are unlimited.
+	Content ContentLimits
+	// Blobs signs the attachment transfer URLs and, when set, checks that
+	// message attachments are blobs their sender uploaded.
+	Blobs *blob.BlobService
+	// Push alerts recipients that are offline about new messages.
+	Push *push.PushService
+	// Sends recognizes retried message sends by their client_msg_id.
+	Sends *dedup.DedupService
+}
+
 // NewDefaultDispatcher builds a dispatcher using per-app injected dependencies (e.g. registry),
 // avoiding global singletons that could drift from the wiring layer.
-func NewDefaultDispatcher(reg contract.Registry) Dispatcher {
-	store := wshandler.NewGroupStore()
+func NewDefaultDispatcher(deps Deps) Dispatcher {
+	reg := deps.Registry
+	router := deps.Router
+	if router == nil {
+		router = cluster.NewRouter(cluster.RouterOptions{Registry: reg})
+	}
 	d := &dispatcher{handlers: make(map[imv1.MessageType]MessageHandler)}
 	d.RegisterHandler(imv1.MessageType_ECHO, &wshandler.EchoHandler{})
-	d.RegisterHandler(imv1.MessageType_CREATE_GROUP, wshandler.NewGroupHandler(reg, store))
-	d.RegisterHandler(imv1.MessageType_LIST_GROUPS, wshandler.NewGroupHandler(reg, store))
-	d.RegisterHandler(imv1.MessageType_SINGLE_MESSAGE, wshandler.NewSingleMessageHandler(reg))
-	d.RegisterHandler(imv1.MessageType_GROUP_MESSAGE, wshandler.NewGroupMessageHandler(reg, store))
+	accountHandler := wshandler.NewAccountHandler(deps.Users)
+	d.RegisterHandler(imv1.MessageType_REGISTER, accountHandler)
+	d.RegisterHandler(imv1.MessageType_LOGIN, accountHandler)
+	d.RegisterHandler(imv1.MessageType_LOGOUT, accountHandler)
+	d.RegisterHandler(imv1.MessageType_REFRESH_TOKEN, accountHandler)
+	groupHandler := wshandler.NewGroupHandler(router, deps.Groups)
+	d.RegisterHandler(imv1.MessageType_CREATE_GROUP, groupHandler)
+	d.RegisterHandler(imv1.MessageType_LIST_GROUPS, groupHandler)
+	d.RegisterHandler(imv1.MessageType_LIST_GROUP_MEMBER, groupHandler)
+	d.RegisterHandler(imv1.MessageType_INVITE_GROUP_MEMBER, groupHandler)
+	d.RegisterHandler(imv1.MessageType_KICK_GROUP_MEMBER, groupHandler)
+	d.RegisterHandler(imv1.MessageType_SET_GROUP_ROLE, groupHandler)
+	d.RegisterHandler(imv1.MessageType_RENAME_GROUP, groupHandler)
+	d.RegisterHandler(imv1.MessageType_DISSOLVE_GROUP, groupHandler)
+	d.RegisterHandler(imv1.MessageType_SET_GROUP_NOTIFY, groupHandler)
+	groupJoinHandler := wshandler.NewGroupJoinHandler(router, deps.Groups)
+	d.RegisterHandler(imv1.MessageType_APPLY_FOR_GROUP, groupJoinHandler)
+	d.RegisterHandler(imv1.MessageType_ACCEPT_GROUP, groupJoinHandler)
+	d.RegisterHandler(imv1.MessageType_REJECT_GROUP, groupJoinHandler)
+	d.RegisterHandler(imv1.MessageType_SINGLE_MESSAGE, wshandler.NewSingleMessageHandler(router, deps.Friends, deps.Inbox, deps.Sends, deps.Push, deps.Content, deps.Blobs))
+	d.RegisterHandler(imv1.MessageType_GROUP_MESSAGE, wshandler.NewGroupMessageHandler(router, deps.Groups, deps.Inbox, deps.Sends, deps.Push, deps.Content, deps.Blobs))
+	d.RegisterHandler(imv1.MessageType_DELIVERY_ACK, wshandler.NewDeliveryAckHandler(deps.Inbox))
+	revisionHandler := wshandler.NewRevisionHandler(router, deps.Revisions, deps.Content, deps.Blobs)
+	d.RegisterHandler(imv1.MessageType_RECALL_MESSAGE, revisionHandler)
+	d.RegisterHandler(imv1.MessageType_EDIT_MESSAGE, revisionHandler)
+	blobHandler := wshandler.NewBlobHandler(deps.Blobs)
+	d.RegisterHandler(imv1.MessageType_CREATE_UPLOAD_URL, blobHandler)
+	d.RegisterHandler(imv1.MessageType_CREATE_DOWNLOAD_URL, blobHandler)
+	historyHandler := wshandler.NewHistoryHandler(deps.History)
+	d.RegisterHandler(imv1.MessageType_PULL_HISTORY, historyHandler)
+	d.RegisterHandler(imv1.MessageType_SYNC, historyHandler)
+	receiptHandler := wshandler.NewReceiptHandler(router, deps.Receipts)
+	d.RegisterHandler(imv1.MessageType_MARK_READ, receiptHandler)
+	d.RegisterHandler(imv1.MessageType_GET_UNREAD, receiptHandler)
+	typingHandler := wshandler.NewTypingHandler(router, deps.Typing)
+	d.RegisterHandler(imv1.MessageType_TYPING_START, typingHandler)
+	d.RegisterHandler(imv1.MessageType_TYPING_STOP, typingHandler)
+	friendHandler := wshandler.NewFriendHandler(router, deps.Friends)
+	d.RegisterHandler(imv1.MessageType_APPLY_FOR_FRIEND, friendHandler)
+	d.RegisterHandler(imv1.MessageType_ACCEPT_FRIEND, friendHandler)
+	d.RegisterHandler(imv1.MessageType_REJECT_FRIEND, friendHandler)
 	return d
 }
 
@@ -47,6 +138,10 @@ func (d *dispatcher) RegisterHandler(messageType imv1.MessageType, handler Messa
 }
 
 func (d *dispatcher) Dispatch(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if sess.UserID() == "" && !anonymousTypes[msg.GetType()] {
+		return protocol.NewError(protocol.CodeUnauthorized, "login required")
+	}
+
 	// Prefer explicit name-based routing (works well across languages),
 	// but fall back to payload type when name is empty.
 	if msg.GetType() != imv1.MessageType_UNSPECIFIED {
@@ -2,6 +2,7 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 	"sync"
@@ -9,10 +10,13 @@ import (
 
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	wshandler "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/handler"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domaintoken "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/token"
 	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/pkg/observability"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
@@ -26,10 +30,18 @@ type HandlerOptions struct {
 	PongWait       time.Duration
 	Logger         *zap.Logger
 	Registry       contract.Registry
+	Router         contract.Router
 	Authenticator  auth.Authenticator
+	AllowAnonymous bool
 	Dispatch       dispatch.Dispatcher
+	Inbox          *inbox.InboxService
+	Retransmit     RetransmitPolicy
 }
 
+// replayBatchSize bounds how many inbox entries are loaded per round trip
+// while replaying on connect.
+const replayBatchSize = 100
+
 type Handler struct {
 	options  HandlerOptions
 	upgrader websocket.Upgrader
@@ -37,7 +49,11 @@ type Handler struct {
 
 func NewHandler(options HandlerOptions) *Handler {
 	if options.Dispatch == nil {
-		options.Dispatch = dispatch.NewDefaultDispatcher(options.Registry)
+		options.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: options.Registry,
+			Router:   options.Router,
+			Inbox:    options.Inbox,
+		})
 	}
 	return &Handler{
 		options: options,
@@ -67,16 +83,27 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.options.Logger.Info("Handling WebSocket connection")
 	ctx := r.Context()
 	token := bearerTokenFromRequest(r)
-	if token == "" {
+	var user *auth.Principal
+	switch {
+	case token != "":
+		var err error
+		if user, err = h.options.Authenticator.Authenticate(ctx, token); err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+	case h.options.AllowAnonymous:
+		user = &auth.Principal{}
+	default:
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
 		return
 	}
-
-	user, err := h.options.Authenticator.Authenticate(ctx, token)
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	// The platform comes with the credential so a client cannot pick one to
+	// slip past the per-platform device policy.
+	if !domaintoken.ValidPlatform(user.Platform) {
+		http.Error(w, "Unknown platform", http.StatusUnauthorized)
 		return
 	}
+	anonymous := user.UserID == ""
 
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
@@ -91,8 +118,35 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		_ = conn.SetReadDeadline(time.Now().Add(h.options.PongWait))
 		return nil
 	})
-	s := NewSession(user.UserID, user.DeviceID, h.options.NodeID, conn, h.options.ReadLimitBytes, h.options.WriteTimeout)
-	h.options.Registry.Bind(ctx, s)
+	s := NewSession(user.UserID, user.DeviceID, h.options.NodeID, conn, h.options.SendQueueSize, h.options.WriteTimeout, h.options.Retransmit)
+	s.tokenID, s.expiresAt = user.TokenID, user.ExpiresAt
+	s.platform = user.Platform
+	replay := !anonymous && h.options.Inbox != nil
+	if replay {
+		// Live deliveries wait for the replay, so the user gets the
+		// messages of each conversation in order.
+		s.hold()
+	}
+	if !anonymous {
+		displaced, err := h.options.Registry.Bind(ctx, s)
+		for _, old := range displaced {
+			h.kick(old, s)
+		}
+		if err != nil {
+			// An unbound session never receives deliveries, so do not keep it.
+			h.options.Logger.Error("failed to bind session", zap.String("user_id", user.UserID), zap.Error(err))
+			_ = h.options.Registry.Unbind(ctx, s)
+			h.writeClose(s, websocket.CloseInternalServerErr, "session unavailable")
+			_ = s.Close()
+			observability.WSConnections.Dec()
+			return
+		}
+		if h.options.Router != nil {
+			if err := h.options.Router.Displace(ctx, user.UserID, user.DeviceID, user.Platform); err != nil {
+				h.options.Logger.Warn("failed to displace sessions on other nodes", zap.String("user_id", user.UserID), zap.Error(err))
+			}
+		}
+	}
 
 	connCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -102,8 +156,15 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	go func() {
 		defer wg.Done()
 		defer cancel()
+		// Closing the connection unblocks the read loop.
+		defer s.Close()
 		h.writeLoop(connCtx, s)
 	}()
+	// Flush what the user missed while offline before reading live traffic.
+	if replay {
+		h.replayInbox(connCtx, s)
+		s.release()
+	}
 	go func() {
 		defer wg.Done()
 		defer cancel()
@@ -111,7 +172,9 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}()
 	wg.Wait()
 	_ = s.Close()
-	h.options.Registry.Unbind(ctx, user.UserID, user.DeviceID)
+	if !anonymous {
+		h.options.Registry.Unbind(ctx, s)
+	}
 	observability.WSConnections.Dec()
 }
 
@@ -119,8 +182,64 @@ func (h *Handler) writeLoop(ctx context.Context, s *Session) {
 	ticker := time.NewTicker(h.options.PingInterval)
 	defer ticker.Stop()
 
+	var retransmitC <-chan time.Time
+	if s.retx.enabled() {
+		retransmitTicker := time.NewTicker(s.retx.tick())
+		defer retransmitTicker.Stop()
+		retransmitC = retransmitTicker.C
+	}
+
+	// The expiry timer is armed for token-authenticated sessions and
+	// re-armed whenever the token is refreshed.
+	var (
+		expiryTimer *time.Timer
+		expiryC     <-chan time.Time
+	)
+	armExpiry := func() {
+		exp := s.ExpiresAt()
+		if exp.IsZero() {
+			return
+		}
+		if expiryTimer == nil {
+			expiryTimer = time.NewTimer(time.Until(exp))
+		} else {
+			expiryTimer.Reset(time.Until(exp))
+		}
+		expiryC = expiryTimer.C
+	}
+	armExpiry()
+	defer func() {
+		if expiryTimer != nil {
+			expiryTimer.Stop()
+		}
+	}()
+
 	for {
 		select {
+		case <-expiryC:
+			if time.Now().Before(s.ExpiresAt()) {
+				armExpiry()
+				continue
+			}
+			h.writeClose(s, protocol.CloseTokenExpired, "token expired")
+			return
+		case <-s.refreshed:
+			armExpiry()
+		case <-s.done:
+			if code, reason := s.closeReason(); code != 0 {
+				h.flush(s)
+				h.writeClose(s, code, reason)
+			}
+			return
+		case now := <-retransmitC:
+			for _, payload := range s.retx.due(now) {
+				observability.WSRetransmits.Inc()
+				_ = s.conn.SetWriteDeadline(time.Now().Add(h.options.WriteTimeout))
+				if err := s.conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
+					h.options.Logger.Info("failed to retransmit message", zap.Error(err))
+					return
+				}
+			}
 		case <-ticker.C:
 			_ = s.conn.SetWriteDeadline(time.Now().Add(h.options.WriteTimeout))
 			if err := s.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -129,20 +248,46 @@ func (h *Handler) writeLoop(ctx context.Context, s *Session) {
 			}
 		case <-ctx.Done():
 			return
-		case payload, ok := <-s.send:
-			if !ok {
+		case payload := <-s.send:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(h.options.WriteTimeout))
+			if err := s.conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
+				h.options.Logger.Info("failed to write message", zap.Error(err))
 				return
 			}
+		}
+	}
+}
 
+// flush writes what is left in the send queue without waiting for more.
+func (h *Handler) flush(s *Session) {
+	for {
+		select {
+		case payload := <-s.send:
 			_ = s.conn.SetWriteDeadline(time.Now().Add(h.options.WriteTimeout))
 			if err := s.conn.WriteMessage(websocket.BinaryMessage, payload); err != nil {
-				h.options.Logger.Info("failed to write message", zap.Error(err))
 				return
 			}
+		default:
+			return
 		}
 	}
 }
 
+// kick tells a session displaced by by why it ends, then closes it.
+func (h *Handler) kick(old contract.Session, by *Session) {
+	h.options.Logger.Info("session displaced",
+		zap.String("user_id", old.UserID()), zap.String("device_id", old.DeviceID()), zap.String("by_device_id", by.DeviceID()))
+	protocol.Kick(old, by.DeviceID(), by.Platform())
+}
+
+// writeClose sends a close frame; the caller closes the connection afterwards.
+func (h *Handler) writeClose(s *Session, code int, reason string) {
+	msg := websocket.FormatCloseMessage(code, reason)
+	if err := s.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(h.options.WriteTimeout)); err != nil {
+		h.options.Logger.Info("failed to write close", zap.Error(err))
+	}
+}
+
 func (h *Handler) readLoop(ctx context.Context, s *Session) {
 	for {
 		select {
@@ -156,25 +301,69 @@ func (h *Handler) readLoop(ctx context.Context, s *Session) {
 			}
 			if mt != websocket.BinaryMessage {
 				h.options.Logger.Info("receive unexpect data", zap.Any("type", mt), zap.Any("data", payload))
-				_ = h.SendError(ctx, s, "", "BAD_REQUEST", "Invalid message type")
+				_ = h.SendError(ctx, s, "", protocol.CodeBadRequest, "Invalid message type")
 				continue
 			}
 
 			env, err := protocol.DecodeClientMessage(payload)
 			if err != nil {
 				observability.WSBadProto.Inc()
-				_ = h.SendError(ctx, s, "", "BAD_PROTO", "Protocol error")
+				_ = h.SendError(ctx, s, "", protocol.CodeBadProto, "Protocol error")
 				continue
 			}
 			err = h.options.Dispatch.Dispatch(ctx, s, env)
 			if err != nil {
-				_ = h.SendError(ctx, s, env.TraceId, "INTERNAL", "dispatch failed")
+				var perr *protocol.Error
+				if errors.As(err, &perr) {
+					_ = h.SendError(ctx, s, env.TraceId, perr.Code, perr.Message)
+					continue
+				}
+				_ = h.SendError(ctx, s, env.TraceId, protocol.CodeInternal, "dispatch failed")
 				continue
 			}
 		}
 	}
 }
 
+// replayInbox queues every undelivered inbox entry of the session's user.
+// Entries stay undelivered until the client acknowledges them, so whatever
+// cannot be queued now is replayed again on the next connection. A message
+// that arrives during the replay may be both replayed and delivered live;
+// clients tell the copies apart by message ID, as with retransmits.
+//
+// The inbox belongs to the user, not to a device: once any device
+// acknowledges an entry it is not replayed to the others. A device that was
+// offline meanwhile catches up with SYNC.
+func (h *Handler) replayInbox(ctx context.Context, s *Session) {
+	if h.options.Inbox == nil {
+		return
+	}
+
+	var after int64
+	for {
+		entries, err := h.options.Inbox.Pending(ctx, s.UserID(), after, replayBatchSize)
+		if err != nil {
+			h.options.Logger.Error("failed to load inbox", zap.String("user_id", s.UserID()), zap.Error(err))
+			return
+		}
+		if len(entries) == 0 {
+			return
+		}
+
+		for _, e := range entries {
+			payload, err := protocol.EncodeServerMessage(wshandler.NewDeliveryEnvelope("", e.Message, s.UserID()))
+			if err != nil {
+				h.options.Logger.Error("failed to encode inbox entry", zap.Int64("inbox_id", e.Inbox.ID), zap.Error(err))
+				return
+			}
+			if err := s.deliver(e.Message.ID, payload); err != nil {
+				return
+			}
+			after = e.Inbox.ID
+		}
+	}
+}
+
 func (h *Handler) SendError(ctx context.Context, s *Session, traceID, code, message string) error {
 	resp := &imv1.ServerEnvelope{
 		TraceId: traceID,
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domaintoken "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/token"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/user"
+)
+
+// defaultDeviceID is used when a login does not name its device.
+const defaultDeviceID = "default"
+
+// AccountHandler serves REGISTER, LOGIN, LOGOUT and REFRESH_TOKEN. REGISTER
+// and LOGIN are also accepted on anonymous connections.
+type AccountHandler struct {
+	users *user.UserService
+}
+
+func NewAccountHandler(users *user.UserService) *AccountHandler {
+	return &AccountHandler{users: users}
+}
+
+func (h *AccountHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.users == nil {
+		return fmt.Errorf("user service is nil")
+	}
+	switch msg.GetType() {
+	case imv1.MessageType_REGISTER:
+		return h.register(ctx, sess, msg)
+	case imv1.MessageType_LOGIN:
+		return h.login(ctx, sess, msg)
+	case imv1.MessageType_LOGOUT:
+		return h.logout(ctx, sess, msg)
+	case imv1.MessageType_REFRESH_TOKEN:
+		return h.refresh(ctx, sess, msg)
+	default:
+		return fmt.Errorf("unsupported message type: %v", msg.GetType())
+	}
+}
+
+func (h *AccountHandler) register(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetRegister()
+	if p == nil {
+		return fmt.Errorf("missing register payload")
+	}
+	if p.GetUuid() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid is required")
+	}
+
+	if _, err := h.users.Register(ctx, p.GetUuid(), p.GetEmail(), p.GetPhone(), p.GetName(), p.GetPassword()); err != nil {
+		return accountError(err)
+	}
+	return replyAck(sess, msg.GetTraceId())
+}
+
+func (h *AccountHandler) login(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetLogin()
+	if p == nil {
+		return fmt.Errorf("missing login payload")
+	}
+	var by user.LoginBy
+	switch p.GetType() {
+	case imv1.LoginType_EMAIL:
+		by = user.LoginByEmail
+	case imv1.LoginType_PHONE:
+		by = user.LoginByPhone
+	default:
+		return protocol.NewError(protocol.CodeBadRequest, "login type must be EMAIL or PHONE")
+	}
+	deviceID := p.GetDeviceId()
+	if deviceID == "" {
+		deviceID = defaultDeviceID
+	}
+
+	if !domaintoken.ValidPlatform(p.GetPlatform()) {
+		return protocol.NewError(protocol.CodeBadRequest, "unknown platform")
+	}
+
+	raw, t, err := h.users.Login(ctx, by, p.GetField(), p.GetPassword(), deviceID, p.GetPlatform())
+	if err != nil {
+		return accountError(err)
+	}
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_LoginResp{
+			LoginResp: &imv1.LoginResp{Uuid: t.UserID, Token: raw, ExpiresAt: t.ExpiresAt.Unix()},
+		},
+	})
+}
+
+// logout revokes the token the connection authenticated with. The
+// connection itself stays open until the client closes it.
+func (h *AccountHandler) logout(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetLogout()
+	if p != nil && p.GetUuid() != "" && p.GetUuid() != sess.UserID() {
+		return protocol.NewError(protocol.CodeForbidden, "cannot log out another user")
+	}
+	if sess.TokenID() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "connection was not opened with a revocable token")
+	}
+
+	if err := h.users.Logout(ctx, principal(sess)); err != nil {
+		return err
+	}
+	return replyAck(sess, msg.GetTraceId())
+}
+
+// refresh swaps the connection's token for a new one and moves the session
+// expiry to the new token's.
+func (h *AccountHandler) refresh(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if sess.TokenID() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "connection was not opened with a refreshable token")
+	}
+
+	raw, t, err := h.users.Refresh(ctx, principal(sess))
+	if err != nil {
+		return accountError(err)
+	}
+	sess.Refresh(t.Hash, t.ExpiresAt)
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_LoginResp{
+			LoginResp: &imv1.LoginResp{Uuid: t.UserID, Token: raw, ExpiresAt: t.ExpiresAt.Unix()},
+		},
+	})
+}
+
+// principal is the credential the connection authenticated with.
+func principal(sess contract.Session) *auth.Principal {
+	return &auth.Principal{
+		UserID:    sess.UserID(),
+		DeviceID:  sess.DeviceID(),
+		Platform:  sess.Platform(),
+		TokenID:   sess.TokenID(),
+		ExpiresAt: sess.ExpiresAt(),
+	}
+}
+
+func accountError(err error) error {
+	switch {
+	case errors.Is(err, user.ErrMissingIdentity),
+		errors.Is(err, auth.ErrWeakPassword):
+		return protocol.NewError(protocol.CodeBadRequest, err.Error())
+	case errors.Is(err, user.ErrUserExists),
+		errors.Is(err, user.ErrEmailTaken),
+		errors.Is(err, user.ErrPhoneTaken):
+		return protocol.NewError(protocol.CodeConflict, err.Error())
+	case errors.Is(err, user.ErrInvalidCredentials):
+		return protocol.NewError(protocol.CodeUnauthorized, err.Error())
+	default:
+		return err
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+)
+
+// BlobHandler hands out the signed URLs used to transfer attachments over
+// HTTP, since the bytes do not fit in a websocket frame.
+type BlobHandler struct {
+	blobs *blob.BlobService
+}
+
+func NewBlobHandler(blobs *blob.BlobService) *BlobHandler {
+	return &BlobHandler{blobs: blobs}
+}
+
+func (h *BlobHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.blobs == nil {
+		return fmt.Errorf("blob service is nil")
+	}
+	p := auth.Principal{UserID: sess.UserID(), DeviceID: sess.DeviceID()}
+
+	var (
+		url       string
+		expiresAt time.Time
+		err       error
+	)
+	switch msg.GetType() {
+	case imv1.MessageType_CREATE_UPLOAD_URL:
+		url, expiresAt = h.blobs.UploadURL(p)
+	case imv1.MessageType_CREATE_DOWNLOAD_URL:
+		blobID := msg.GetCreateDownloadUrl().GetBlobId()
+		if blobID == "" {
+			return protocol.NewError(protocol.CodeBadRequest, "blob_id is required")
+		}
+		url, expiresAt, err = h.blobs.DownloadURL(ctx, p, blobID)
+		if errors.Is(err, blob.ErrBlobNotFound) {
+			return protocol.NewError(protocol.CodeNotFound, err.Error())
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported message type: %v", msg.GetType())
+	}
+
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_BlobUrl{
+			BlobUrl: &imv1.BlobUrl{Url: url, ExpiresAt: expiresAt.UnixMilli()},
+		},
+	})
+}
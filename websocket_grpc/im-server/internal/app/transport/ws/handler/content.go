@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentLimits bounds the message bodies accepted from clients. Attachment
+// limits apply to the blob size the client declares, which checkAttachments
+// compares with the stored blob. A zero field disables its check.
+type ContentLimits struct {
+	// MaxTextBytes bounds text, and the names and excerpts of other types.
+	MaxTextBytes int
+	MaxMentions  int
+	// MaxCustomBytes bounds custom JSON and untyped message bytes.
+	MaxCustomBytes int
+	MaxImageBytes  int64
+	MaxFileBytes   int64
+	MaxAudioBytes  int64
+}
+
+// encodeBody validates the body of a send or an edit, which is either
+// untyped bytes or a typed content, and returns it in its stored form.
+func encodeBody(raw []byte, content *imv1.Content, limits ContentLimits) (string, []byte, error) {
+	if content == nil {
+		if len(raw) == 0 {
+			return "", nil, badContent("message or content is required")
+		}
+		if exceeds(len(raw), limits.MaxCustomBytes) {
+			return "", nil, badContent("message is too large")
+		}
+		return domainmessage.ContentUntyped, raw, nil
+	}
+	if len(raw) > 0 {
+		return "", nil, badContent("message and content are exclusive")
+	}
+
+	contentType, err := validateContent(content, limits)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := proto.Marshal(content)
+	if err != nil {
+		return "", nil, err
+	}
+	return contentType, data, nil
+}
+
+// decodeBody is the inverse of encodeBody. A typed content that cannot be
+// decoded is dropped rather than failing the whole delivery.
+func decodeBody(m *domainmessage.Message) ([]byte, *imv1.Content) {
+	if m.ContentType == domainmessage.ContentUntyped {
+		return m.Content, nil
+	}
+	var content imv1.Content
+	if err := proto.Unmarshal(m.Content, &content); err != nil {
+		return nil, nil
+	}
+	return nil, &content
+}
+
+func validateContent(c *imv1.Content, limits ContentLimits) (string, error) {
+	if r := c.GetReplyTo(); r != nil {
+		if r.GetMessageId() <= 0 {
+			return "", badContent("reply_to.message_id is required")
+		}
+		if exceeds(len(r.GetExcerpt()), limits.MaxTextBytes) {
+			return "", badContent("reply_to.excerpt is too large")
+		}
+	}
+
+	switch body := c.GetBody().(type) {
+	case *imv1.Content_Text:
+		return domainmessage.ContentText, validateText(body.Text, limits)
+	case *imv1.Content_Image:
+		if err := validateAttachments(c, limits); err != nil {
+			return "", err
+		}
+		if body.Image.GetWidth() < 0 || body.Image.GetHeight() < 0 {
+			return "", badContent("image dimensions must not be negative")
+		}
+		return domainmessage.ContentImage, nil
+	case *imv1.Content_File:
+		return domainmessage.ContentFile, validateAttachments(c, limits)
+	case *imv1.Content_Audio:
+		if body.Audio.GetDurationMs() < 0 {
+			return "", badContent("audio duration must not be negative")
+		}
+		return domainmessage.ContentAudio, validateAttachments(c, limits)
+	case *imv1.Content_Location:
+		return domainmessage.ContentLocation, validateLocation(body.Location, limits)
+	case *imv1.Content_Custom:
+		return domainmessage.ContentCustom, validateCustom(body.Custom, limits)
+	default:
+		return "", badContent("content body is required")
+	}
+}
+
+func validateText(t *imv1.TextContent, limits ContentLimits) error {
+	text := t.GetText()
+	if text == "" {
+		return badContent("text is required")
+	}
+	if exceeds(len(text), limits.MaxTextBytes) {
+		return badContent("text is too large")
+	}
+	if !utf8.ValidString(text) {
+		return badContent("text is not valid UTF-8")
+	}
+	if exceeds(len(t.GetMentions()), limits.MaxMentions) {
+		return badContent("too many mentions")
+	}
+	for _, m := range t.GetMentions() {
+		if (m.GetUser() == "") != m.GetAll() {
+			return badContent("a mention names either a user or all")
+		}
+		if m.GetOffset() < 0 || m.GetLength() <= 0 || int(m.GetOffset())+int(m.GetLength()) > len(text) {
+			return badContent("mention is outside the text")
+		}
+	}
+	return nil
+}
+
+// attachment is an attachment of a content with the rules it follows.
+type attachment struct {
+	kind       string
+	a          *imv1.Attachment
+	mimePrefix string
+	maxBytes   int64
+}
+
+// attachmentsOf returns the attachments of c. An image thumbnail is only
+// listed when present.
+func attachmentsOf(c *imv1.Content, limits ContentLimits) []attachment {
+	switch body := c.GetBody().(type) {
+	case *imv1.Content_Image:
+		as := []attachment{{"image", body.Image.GetAttachment(), "image/", limits.MaxImageBytes}}
+		if t := body.Image.GetThumbnail(); t != nil {
+			as = append(as, attachment{"image thumbnail", t, "image/", limits.MaxImageBytes})
+		}
+		return as
+	case *imv1.Content_File:
+		return []attachment{{"file", body.File.GetAttachment(), "", limits.MaxFileBytes}}
+	case *imv1.Content_Audio:
+		return []attachment{{"audio", body.Audio.GetAttachment(), "audio/", limits.MaxAudioBytes}}
+	default:
+		return nil
+	}
+}
+
+func validateAttachments(c *imv1.Content, limits ContentLimits) error {
+	for _, at := range attachmentsOf(c, limits) {
+		a := at.a
+		if a == nil {
+			return badContent(at.kind + " attachment is required")
+		}
+		if a.GetBlobId() == "" {
+			return badContent(at.kind + " blob_id is required")
+		}
+		if a.GetMimeType() == "" || !strings.HasPrefix(a.GetMimeType(), at.mimePrefix) {
+			return badContent(fmt.Sprintf("%s mime_type must start with %q", at.kind, at.mimePrefix))
+		}
+		if a.GetSize() <= 0 {
+			return badContent(at.kind + " size is required")
+		}
+		if at.maxBytes > 0 && a.GetSize() > at.maxBytes {
+			return badContent(fmt.Sprintf("%s is larger than %d bytes", at.kind, at.maxBytes))
+		}
+	}
+	return nil
+}
+
+// checkAttachments makes sure the attachments of a validated content are
+// blobs that userID uploaded, of the declared size and a matching type, so
+// neither the limits nor other users' blobs can be got at by lying about
+// them. Without a blob service the declarations are trusted.
+func checkAttachments(ctx context.Context, blobs *blob.BlobService, userID string, c *imv1.Content, limits ContentLimits) error {
+	if blobs == nil {
+		return nil
+	}
+	for _, at := range attachmentsOf(c, limits) {
+		b, err := blobs.StatOwned(ctx, userID, at.a.GetBlobId())
+		if errors.Is(err, blob.ErrBlobNotFound) {
+			return badContent(at.kind + " blob not found")
+		}
+		if err != nil {
+			return err
+		}
+		if b.Size != at.a.GetSize() {
+			return badContent(at.kind + " size does not match the blob")
+		}
+		if !strings.HasPrefix(b.ContentType, at.mimePrefix) {
+			return badContent(fmt.Sprintf("%s blob is not of type %q", at.kind, at.mimePrefix))
+		}
+	}
+	return nil
+}
+
+func validateLocation(l *imv1.LocationContent, limits ContentLimits) error {
+	// Written so that NaN fails too.
+	if !(l.GetLatitude() >= -90 && l.GetLatitude() <= 90) || !(l.GetLongitude() >= -180 && l.GetLongitude() <= 180) {
+		return badContent("location is out of range")
+	}
+	if exceeds(len(l.GetName()), limits.MaxTextBytes) || exceeds(len(l.GetAddress()), limits.MaxTextBytes) {
+		return badContent("location name or address is too large")
+	}
+	return nil
+}
+
+func validateCustom(c *imv1.CustomContent, limits ContentLimits) error {
+	if c.GetType() == "" {
+		return badContent("custom type is required")
+	}
+	if exceeds(len(c.GetJson()), limits.MaxCustomBytes) {
+		return badContent("custom json is too large")
+	}
+	if !json.Valid([]byte(c.GetJson())) {
+		return badContent("custom json is invalid")
+	}
+	return nil
+}
+
+// mentionsAll reports whether c mentions every member of the group.
+func mentionsAll(c *imv1.Content) bool {
+	for _, m := range c.GetText().GetMentions() {
+		if m.GetAll() {
+			return true
+		}
+	}
+	return false
+}
+
+// isMentioned reports whether the text of c mentions userID, by name or
+// with @all.
+func isMentioned(c *imv1.Content, userID string) bool {
+	for _, m := range c.GetText().GetMentions() {
+		if m.GetAll() || m.GetUser() == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// exceeds reports whether n is over limit; a zero limit never is.
+func exceeds(n, limit int) bool {
+	return limit > 0 && n > limit
+}
+
+func badContent(msg string) error {
+	return protocol.NewError(protocol.CodeBadRequest, msg)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/dedup"
+)
+
+// maxClientMsgIDBytes bounds the client_msg_id of a send.
+const maxClientMsgIDBytes = 64
+
+func validateClientMsgID(id string) error {
+	if len(id) > maxClientMsgIDBytes {
+		return protocol.NewError(protocol.CodeBadRequest, "client_msg_id is too long")
+	}
+	return nil
+}
+
+// storeOnce runs store, which persists m, unless the sender of m already
+// sent clientMsgID. It reports whether the send is such a retry; m.ID is
+// then the ID of the original message. Untagged sends are always stored.
+func storeOnce(ctx context.Context, sends *dedup.DedupService, clientMsgID string, m *domainmessage.Message, store func() error) (bool, error) {
+	if sends == nil || clientMsgID == "" {
+		return false, store()
+	}
+	id, duplicate, err := sends.Send(ctx, m.FromUser, clientMsgID, func() (int64, error) {
+		if err := store(); err != nil {
+			return 0, err
+		}
+		return m.ID, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	m.ID = id
+	return duplicate, nil
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+)
+
+// NewDeliveryEnvelope builds the server -> client event that carries m to
+// recipient. Edited and recalled messages carry their markers, so the same
+// envelope updates a copy the client already has.
+func NewDeliveryEnvelope(traceID string, m *domainmessage.Message, recipient string) *imv1.ServerEnvelope {
+	editedAt := unixMilli(m.EditedAt)
+	raw, content := decodeBody(m)
+	if m.IsGroup() {
+		return &imv1.ServerEnvelope{
+			TraceId: traceID,
+			Payload: &imv1.ServerEnvelope_DeliverGroupMessage{
+				DeliverGroupMessage: &imv1.DeliverGroupMessage{
+					GroupUuid: m.GroupUUID,
+					From:      m.FromUser,
+					Message:   raw,
+					Content:   content,
+					MessageId: m.ID,
+					Seq:       m.Seq,
+					EditedAt:  editedAt,
+					Recalled:  m.IsRecalled(),
+					Mentioned: isMentioned(content, recipient),
+				},
+			},
+		}
+	}
+	return &imv1.ServerEnvelope{
+		TraceId: traceID,
+		Payload: &imv1.ServerEnvelope_DeliverSingleMessage{
+			DeliverSingleMessage: &imv1.DeliverSingleMessage{
+				From:      m.FromUser,
+				Message:   raw,
+				Content:   content,
+				MessageId: m.ID,
+				Seq:       m.Seq,
+				EditedAt:  editedAt,
+				Recalled:  m.IsRecalled(),
+			},
+		},
+	}
+}
+
+// deliver sends m to the sessions of every recipient on any node. The
+// envelopes only differ in whether m mentions the recipient, so at most two
+// payloads are encoded.
+func deliver(ctx context.Context, router contract.Router, traceID string, m *domainmessage.Message, recipients []string) error {
+	_, content := decodeBody(m)
+	payloads := make(map[bool][]byte, 2)
+	for _, uid := range recipients {
+		mentioned := m.IsGroup() && isMentioned(content, uid)
+		data, ok := payloads[mentioned]
+		if !ok {
+			var err error
+			if data, err = protocol.EncodeServerMessage(NewDeliveryEnvelope(traceID, m, uid)); err != nil {
+				return err
+			}
+			payloads[mentioned] = data
+		}
+		if err := router.Deliver(ctx, uid, m.ID, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unixMilli converts an optional time to the wire format, 0 for nil.
+func unixMilli(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+)
+
+// DeliveryAckHandler stops retransmission of an acknowledged delivery and
+// marks the user's inbox entry as delivered. Acks are not answered.
+type DeliveryAckHandler struct {
+	inbox *inbox.InboxService
+}
+
+func NewDeliveryAckHandler(inbox *inbox.InboxService) *DeliveryAckHandler {
+	return &DeliveryAckHandler{inbox: inbox}
+}
+
+func (h *DeliveryAckHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetDeliveryAck()
+	if p == nil {
+		return fmt.Errorf("missing delivery_ack payload")
+	}
+	if p.GetMessageId() <= 0 {
+		return fmt.Errorf("message_id is required")
+	}
+
+	sess.Acknowledge(p.GetMessageId())
+	if h.inbox == nil {
+		return nil
+	}
+	return h.inbox.MarkDelivered(ctx, sess.UserID(), p.GetMessageId())
+}
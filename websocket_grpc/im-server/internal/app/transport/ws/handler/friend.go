@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domainfriend "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/friend"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+)
+
+// FriendHandler serves APPLY_FOR_FRIEND, ACCEPT_FRIEND and REJECT_FRIEND and
+// pushes a FriendEvent to the other party's online sessions.
+type FriendHandler struct {
+	router  contract.Router
+	friends *friend.FriendService
+}
+
+func NewFriendHandler(router contract.Router, friends *friend.FriendService) *FriendHandler {
+	return &FriendHandler{router: router, friends: friends}
+}
+
+func (h *FriendHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.friends == nil {
+		return fmt.Errorf("friend service is nil")
+	}
+
+	var (
+		req    *domainfriend.FriendRequest
+		kind   imv1.FriendEvent_Kind
+		notify string
+		err    error
+	)
+	switch msg.GetType() {
+	case imv1.MessageType_APPLY_FOR_FRIEND:
+		p := msg.GetApplyForFriend()
+		if p == nil {
+			return fmt.Errorf("missing apply_for_friend payload")
+		}
+		if p.GetRequestId() == "" || p.GetUuid() == "" {
+			return protocol.NewError(protocol.CodeBadRequest, "request_id and uuid are required")
+		}
+		req, err = h.friends.Apply(ctx, p.GetRequestId(), sess.UserID(), p.GetUuid())
+		kind = imv1.FriendEvent_APPLIED
+	case imv1.MessageType_ACCEPT_FRIEND:
+		p := msg.GetAcceptFriend()
+		if p == nil {
+			return fmt.Errorf("missing accept_friend payload")
+		}
+		req, err = h.friends.Accept(ctx, sess.UserID(), p.GetRequestId())
+		kind = imv1.FriendEvent_ACCEPTED
+	case imv1.MessageType_REJECT_FRIEND:
+		p := msg.GetRejectFriend()
+		if p == nil {
+			return fmt.Errorf("missing reject_friend payload")
+		}
+		req, err = h.friends.Reject(ctx, sess.UserID(), p.GetRequestId())
+		kind = imv1.FriendEvent_REJECTED
+	default:
+		return fmt.Errorf("unsupported message type: %v", msg.GetType())
+	}
+	if err != nil {
+		return friendError(err)
+	}
+
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+
+	notify = req.FromUser
+	if kind == imv1.FriendEvent_APPLIED {
+		notify = req.ToUser
+	}
+	event, err := protocol.EncodeServerMessage(&imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_FriendEvent{
+			FriendEvent: &imv1.FriendEvent{
+				Kind:      kind,
+				RequestId: req.RequestID,
+				From:      req.FromUser,
+				To:        req.ToUser,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if h.router == nil {
+		return nil
+	}
+	return h.router.Deliver(ctx, notify, 0, event)
+}
+
+func friendError(err error) error {
+	switch {
+	case errors.Is(err, friend.ErrSelfRequest):
+		return protocol.NewError(protocol.CodeBadRequest, err.Error())
+	case errors.Is(err, friend.ErrRequestNotFound),
+		errors.Is(err, friend.ErrUserNotFound):
+		return protocol.NewError(protocol.CodeNotFound, err.Error())
+	case errors.Is(err, friend.ErrNotRecipient):
+		return protocol.NewError(protocol.CodeForbidden, err.Error())
+	case errors.Is(err, friend.ErrAlreadyFriends),
+		errors.Is(err, friend.ErrDuplicateRequest),
+		errors.Is(err, friend.ErrRequestResolved):
+		return protocol.NewError(protocol.CodeConflict, err.Error())
+	default:
+		return err
+	}
+}
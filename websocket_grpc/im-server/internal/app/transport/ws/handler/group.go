@@ -2,28 +2,51 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
 	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
 )
 
+// GroupHandler serves group creation, listing and the admin operations.
+// Admin operations are authorized by the group policy and announced to the
+// affected users as a GroupEvent.
 type GroupHandler struct {
-	registry contract.Registry
-	store    *GroupStore
+	router contract.Router
+	groups *group.GroupService
 }
 
-func NewGroupHandler(registry contract.Registry, store *GroupStore) *GroupHandler {
-	return &GroupHandler{registry: registry, store: store}
+func NewGroupHandler(router contract.Router, groups *group.GroupService) *GroupHandler {
+	return &GroupHandler{router: router, groups: groups}
 }
 
 func (h *GroupHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.groups == nil {
+		return fmt.Errorf("group service is nil")
+	}
 	switch msg.GetType() {
 	case imv1.MessageType_CREATE_GROUP:
 		return h.createGroup(ctx, sess, msg)
 	case imv1.MessageType_LIST_GROUPS:
 		return h.listGroup(ctx, sess, msg)
+	case imv1.MessageType_LIST_GROUP_MEMBER:
+		return h.listGroupMember(ctx, sess, msg)
+	case imv1.MessageType_INVITE_GROUP_MEMBER:
+		return h.inviteMember(ctx, sess, msg)
+	case imv1.MessageType_KICK_GROUP_MEMBER:
+		return h.kickMember(ctx, sess, msg)
+	case imv1.MessageType_SET_GROUP_ROLE:
+		return h.setRole(ctx, sess, msg)
+	case imv1.MessageType_RENAME_GROUP:
+		return h.rename(ctx, sess, msg)
+	case imv1.MessageType_DISSOLVE_GROUP:
+		return h.dissolve(ctx, sess, msg)
+	case imv1.MessageType_SET_GROUP_NOTIFY:
+		return h.setNotify(ctx, sess, msg)
 	default:
 		return fmt.Errorf("unsupported message type: %v", msg.GetType())
 	}
@@ -35,38 +58,102 @@ func (h *GroupHandler) createGroup(ctx context.Context, sess contract.Session, m
 		return fmt.Errorf("missing create_group payload")
 	}
 	if p.GetUuid() == "" {
-		return fmt.Errorf("uuid is required")
+		return protocol.NewError(protocol.CodeBadRequest, "uuid is required")
 	}
 
-	// MVP: in-memory membership only.
-	if h.store != nil {
-		h.store.AddMember(p.GetUuid(), sess.UserID())
+	if _, err := h.groups.Create(ctx, sess.UserID(), p.GetUuid(), p.GetName()); err != nil {
+		return groupError(err)
 	}
+	return replyAck(sess, msg.GetTraceId())
+}
 
-	resp := &imv1.ServerEnvelope{
-		TraceId: msg.GetTraceId(),
-		Payload: &imv1.ServerEnvelope_AckResp{
-			AckResp: &imv1.AckResp{Status: 0, Message: "ok"},
-		},
-	}
-	out, err := protocol.EncodeServerMessage(resp)
+// listGroup returns the groups of the caller; the uuid field is ignored so
+// users cannot enumerate other users' memberships.
+func (h *GroupHandler) listGroup(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetListGroups()
+	groups, err := h.groups.ListForUser(ctx, sess.UserID(), int(p.GetPage()), int(p.GetPageSize()))
 	if err != nil {
 		return err
 	}
-	return sess.Send(out)
-}
 
-func (h *GroupHandler) listGroup(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
-	// TODO: wire real group repository and return ListGroupsResp.
-	resp := &imv1.ServerEnvelope{
+	infos := make([]*imv1.GroupInfo, 0, len(groups))
+	for _, g := range groups {
+		infos = append(infos, &imv1.GroupInfo{Uuid: g.UUID, Name: g.Name, Owner: g.Owner})
+	}
+	return reply(sess, &imv1.ServerEnvelope{
 		TraceId: msg.GetTraceId(),
-		Payload: &imv1.ServerEnvelope_AckResp{
-			AckResp: &imv1.AckResp{Status: 0, Message: "ok"},
+		Payload: &imv1.ServerEnvelope_ListGroupsResp{
+			ListGroupsResp: &imv1.ListGroupsResp{Status: 0, Message: "ok", GroupInfo: infos},
 		},
+	})
+}
+
+func (h *GroupHandler) listGroupMember(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetListGroupMemeber()
+	if p == nil {
+		return fmt.Errorf("missing list_group_memeber payload")
+	}
+	if p.GetUuid() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid is required")
+	}
+
+	// Only members see who else is in the group, like listGroup.
+	if _, err := h.groups.Authorize(ctx, p.GetUuid(), sess.UserID(), domaingroup.ActionRead); err != nil {
+		return groupError(err)
 	}
-	out, err := protocol.EncodeServerMessage(resp)
+	members, err := h.groups.Members(ctx, p.GetUuid(), int(p.GetPage()), int(p.GetPageSize()))
 	if err != nil {
+		return groupError(err)
+	}
+	out := make([]*imv1.GroupMember, 0, len(members))
+	for _, m := range members {
+		out = append(out, &imv1.GroupMember{Uuid: m.UserID, Role: imv1.GroupRole(m.Role)})
+	}
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_ListGroupMemeberResp{
+			ListGroupMemeberResp: &imv1.ListGroupMemberResp{Status: 0, GroupMember: out},
+		},
+	})
+}
+
+// setNotify stores the caller's own notification preference; it is not
+// announced to anybody.
+func (h *GroupHandler) setNotify(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetSetGroupNotify()
+	if p == nil {
+		return fmt.Errorf("missing set_group_notify payload")
+	}
+	if p.GetUuid() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid is required")
+	}
+
+	// imv1.GroupNotify mirrors the numbering of domaingroup.NotifyLevel.
+	if err := h.groups.SetNotify(ctx, p.GetUuid(), sess.UserID(), domaingroup.NotifyLevel(p.GetLevel())); err != nil {
+		return groupError(err)
+	}
+	return replyAck(sess, msg.GetTraceId())
+}
+
+func groupError(err error) error {
+	switch {
+	case errors.Is(err, group.ErrGroupNotFound),
+		errors.Is(err, group.ErrRequestNotFound),
+		errors.Is(err, group.ErrUserNotFound):
+		return protocol.NewError(protocol.CodeNotFound, err.Error())
+	case errors.Is(err, group.ErrNotMember):
+		return protocol.NewError(protocol.CodeNotMember, err.Error())
+	case errors.Is(err, group.ErrPermissionDenied):
+		return protocol.NewError(protocol.CodePermissionDenied, err.Error())
+	case errors.Is(err, group.ErrInvalidRole),
+		errors.Is(err, group.ErrInvalidNotify):
+		return protocol.NewError(protocol.CodeBadRequest, err.Error())
+	case errors.Is(err, group.ErrGroupExists),
+		errors.Is(err, group.ErrAlreadyMember),
+		errors.Is(err, group.ErrDuplicateRequest),
+		errors.Is(err, group.ErrRequestResolved):
+		return protocol.NewError(protocol.CodeConflict, err.Error())
+	default:
 		return err
 	}
-	return sess.Send(out)
 }
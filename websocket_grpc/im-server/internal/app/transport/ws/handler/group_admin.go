@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+)
+
+func (h *GroupHandler) inviteMember(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetInviteGroupMember()
+	if p == nil {
+		return fmt.Errorf("missing invite_group_member payload")
+	}
+	if p.GetUuid() == "" || p.GetUser() == "" || p.GetRequestId() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid, user and request_id are required")
+	}
+
+	// The invitee joins once they accept the request.
+	req, g, err := h.groups.Invite(ctx, p.GetRequestId(), sess.UserID(), p.GetUuid(), p.GetUser())
+	if err != nil {
+		return groupError(err)
+	}
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+	return h.notify(ctx, msg.GetTraceId(), []string{p.GetUser()}, &imv1.GroupEvent{
+		Kind:      imv1.GroupEvent_INVITED,
+		RequestId: req.RequestID,
+		GroupUuid: g.UUID,
+		User:      p.GetUser(),
+		Operator:  sess.UserID(),
+	})
+}
+
+func (h *GroupHandler) kickMember(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetKickGroupMember()
+	if p == nil {
+		return fmt.Errorf("missing kick_group_member payload")
+	}
+	if p.GetUuid() == "" || p.GetUser() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid and user are required")
+	}
+
+	g, err := h.groups.Kick(ctx, sess.UserID(), p.GetUuid(), p.GetUser())
+	if err != nil {
+		return groupError(err)
+	}
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+	return h.notify(ctx, msg.GetTraceId(), []string{p.GetUser()}, &imv1.GroupEvent{
+		Kind:      imv1.GroupEvent_KICKED,
+		GroupUuid: g.UUID,
+		User:      p.GetUser(),
+		Operator:  sess.UserID(),
+	})
+}
+
+func (h *GroupHandler) setRole(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetSetGroupRole()
+	if p == nil {
+		return fmt.Errorf("missing set_group_role payload")
+	}
+	if p.GetUuid() == "" || p.GetUser() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid and user are required")
+	}
+
+	// imv1.GroupRole mirrors the numbering of domaingroup.Role.
+	g, err := h.groups.SetRole(ctx, sess.UserID(), p.GetUuid(), p.GetUser(), domaingroup.Role(p.GetRole()))
+	if err != nil {
+		return groupError(err)
+	}
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+	return h.notify(ctx, msg.GetTraceId(), []string{p.GetUser()}, &imv1.GroupEvent{
+		Kind:      imv1.GroupEvent_ROLE_CHANGED,
+		GroupUuid: g.UUID,
+		User:      p.GetUser(),
+		Operator:  sess.UserID(),
+		Role:      p.GetRole(),
+	})
+}
+
+func (h *GroupHandler) rename(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetRenameGroup()
+	if p == nil {
+		return fmt.Errorf("missing rename_group payload")
+	}
+	if p.GetUuid() == "" || p.GetName() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid and name are required")
+	}
+
+	g, err := h.groups.Rename(ctx, sess.UserID(), p.GetUuid(), p.GetName())
+	if err != nil {
+		return groupError(err)
+	}
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+	memberIDs, err := h.groups.MemberIDs(ctx, g.UUID)
+	if err != nil {
+		return err
+	}
+	return h.notify(ctx, msg.GetTraceId(), memberIDs, &imv1.GroupEvent{
+		Kind:      imv1.GroupEvent_RENAMED,
+		GroupUuid: g.UUID,
+		Operator:  sess.UserID(),
+		Name:      g.Name,
+	})
+}
+
+func (h *GroupHandler) dissolve(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetDissolveGroup()
+	if p == nil {
+		return fmt.Errorf("missing dissolve_group payload")
+	}
+	if p.GetUuid() == "" {
+		return protocol.NewError(protocol.CodeBadRequest, "uuid is required")
+	}
+
+	g, memberIDs, err := h.groups.Dissolve(ctx, sess.UserID(), p.GetUuid())
+	if err != nil {
+		return groupError(err)
+	}
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+	return h.notify(ctx, msg.GetTraceId(), memberIDs, &imv1.GroupEvent{
+		Kind:      imv1.GroupEvent_DISSOLVED,
+		GroupUuid: g.UUID,
+		Operator:  sess.UserID(),
+	})
+}
+
+// notify pushes event to the online sessions of every user in userIDs.
+func (h *GroupHandler) notify(ctx context.Context, traceID string, userIDs []string, event *imv1.GroupEvent) error {
+	if h.router == nil || len(userIDs) == 0 {
+		return nil
+	}
+	data, err := protocol.EncodeServerMessage(&imv1.ServerEnvelope{
+		TraceId: traceID,
+		Payload: &imv1.ServerEnvelope_GroupEvent{GroupEvent: event},
+	})
+	if err != nil {
+		return err
+	}
+	for _, uid := range userIDs {
+		if err := h.router.Deliver(ctx, uid, 0, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
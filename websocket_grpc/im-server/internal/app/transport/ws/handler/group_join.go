@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+)
+
+// GroupJoinHandler serves APPLY_FOR_GROUP, ACCEPT_GROUP and REJECT_GROUP.
+// Applications are pushed to the group owner as a GroupEvent and the outcome
+// is pushed back to the applicant. ACCEPT_GROUP and REJECT_GROUP also answer
+// invitations, whose outcome goes to the inviter.
+type GroupJoinHandler struct {
+	router contract.Router
+	groups *group.GroupService
+}
+
+func NewGroupJoinHandler(router contract.Router, groups *group.GroupService) *GroupJoinHandler {
+	return &GroupJoinHandler{router: router, groups: groups}
+}
+
+func (h *GroupJoinHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.groups == nil {
+		return fmt.Errorf("group service is nil")
+	}
+
+	var (
+		req  *domaingroup.GroupRequest
+		g    *domaingroup.Group
+		kind imv1.GroupEvent_Kind
+		err  error
+	)
+	switch msg.GetType() {
+	case imv1.MessageType_APPLY_FOR_GROUP:
+		p := msg.GetApplyForGroup()
+		if p == nil {
+			return fmt.Errorf("missing apply_for_group payload")
+		}
+		if p.GetRequestId() == "" || p.GetUuid() == "" {
+			return protocol.NewError(protocol.CodeBadRequest, "request_id and uuid are required")
+		}
+		req, g, err = h.groups.Apply(ctx, p.GetRequestId(), sess.UserID(), p.GetUuid())
+		kind = imv1.GroupEvent_JOIN_APPLIED
+	case imv1.MessageType_ACCEPT_GROUP:
+		p := msg.GetAcceptGroup()
+		if p == nil {
+			return fmt.Errorf("missing accept_group payload")
+		}
+		req, g, err = h.groups.Accept(ctx, sess.UserID(), p.GetRequestId())
+		kind = imv1.GroupEvent_JOIN_ACCEPTED
+	case imv1.MessageType_REJECT_GROUP:
+		p := msg.GetRejectGroup()
+		if p == nil {
+			return fmt.Errorf("missing reject_group payload")
+		}
+		req, g, err = h.groups.Reject(ctx, sess.UserID(), p.GetRequestId())
+		kind = imv1.GroupEvent_JOIN_REJECTED
+	default:
+		return fmt.Errorf("unsupported message type: %v", msg.GetType())
+	}
+	if err != nil {
+		return groupError(err)
+	}
+
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+
+	notify := req.UserID
+	switch {
+	case kind == imv1.GroupEvent_JOIN_APPLIED:
+		notify = g.Owner
+	case req.InvitedBy != "":
+		notify = req.InvitedBy
+	}
+	event, err := protocol.EncodeServerMessage(&imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_GroupEvent{
+			GroupEvent: &imv1.GroupEvent{
+				Kind:      kind,
+				RequestId: req.RequestID,
+				GroupUuid: g.UUID,
+				User:      req.UserID,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if h.router == nil {
+		return nil
+	}
+	return h.router.Deliver(ctx, notify, 0, event)
+}
@@ -3,19 +3,31 @@ package handler
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
-	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
 	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/dedup"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
 )
 
 type GroupMessageHandler struct {
-	reg   contract.Registry
-	store *GroupStore
+	router contract.Router
+	groups *group.GroupService
+	inbox  *inbox.InboxService
+	sends  *dedup.DedupService
+	pushes *push.PushService
+	limits ContentLimits
+	blobs  *blob.BlobService
 }
 
-func NewGroupMessageHandler(reg contract.Registry, store *GroupStore) *GroupMessageHandler {
-	return &GroupMessageHandler{reg: reg, store: store}
+func NewGroupMessageHandler(router contract.Router, groups *group.GroupService, inbox *inbox.InboxService, sends *dedup.DedupService, pushes *push.PushService, limits ContentLimits, blobs *blob.BlobService) *GroupMessageHandler {
+	return &GroupMessageHandler{router: router, groups: groups, inbox: inbox, sends: sends, pushes: pushes, limits: limits, blobs: blobs}
 }
 
 func (h *GroupMessageHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
@@ -26,63 +38,70 @@ func (h *GroupMessageHandler) HandleMessage(ctx context.Context, sess contract.S
 	if p.GetUuid() == "" {
 		return fmt.Errorf("uuid is required")
 	}
-	if h.reg == nil {
-		return fmt.Errorf("registry is nil")
+	if h.router == nil {
+		return fmt.Errorf("router is nil")
 	}
-
-	// Ack to sender.
-	ack := &imv1.ServerEnvelope{
-		TraceId: msg.GetTraceId(),
-		Payload: &imv1.ServerEnvelope_AckResp{
-			AckResp: &imv1.AckResp{Status: 0, Message: "ok"},
-		},
+	if h.groups == nil {
+		return fmt.Errorf("group service is nil")
 	}
-	ackBytes, err := protocol.EncodeServerMessage(ack)
+	contentType, content, err := encodeBody(p.GetMessage(), p.GetContent(), h.limits)
 	if err != nil {
 		return err
 	}
-	if err := sess.Send(ackBytes); err != nil {
+	if err := checkAttachments(ctx, h.blobs, sess.UserID(), p.GetContent(), h.limits); err != nil {
 		return err
 	}
-
-	if h.store == nil {
-		// No membership resolver wired yet.
-		return nil
+	if err := validateClientMsgID(p.GetClientMsgId()); err != nil {
+		return err
 	}
 
-	memberIDs := h.store.ListMembers(p.GetUuid())
-	if len(memberIDs) == 0 {
-		return nil
+	if _, err := h.groups.Authorize(ctx, p.GetUuid(), sess.UserID(), domaingroup.ActionPost); err != nil {
+		return groupError(err)
+	}
+	// @all alerts every member, so it is reserved to admins.
+	if mentionsAll(p.GetContent()) {
+		if _, err := h.groups.Authorize(ctx, p.GetUuid(), sess.UserID(), domaingroup.ActionMentionAll); err != nil {
+			return groupError(err)
+		}
+	}
+	memberIDs, err := h.groups.MemberIDs(ctx, p.GetUuid())
+	if err != nil {
+		return groupError(err)
 	}
 
-	// Deliver to each member's online sessions.
-	deliver := &imv1.ServerEnvelope{
-		TraceId: msg.GetTraceId(),
-		Payload: &imv1.ServerEnvelope_DeliverGroupMessage{
-			DeliverGroupMessage: &imv1.DeliverGroupMessage{
-				GroupUuid: p.GetUuid(),
-				From:      sess.UserID(),
-				Message:   p.GetMessage(),
-			},
-		},
+	m := &domainmessage.Message{
+		FromUser:    sess.UserID(),
+		GroupUUID:   p.GetUuid(),
+		ContentType: contentType,
+		Content:     content,
+		CreatedAt:   time.Now(),
 	}
-	deliverBytes, err := protocol.EncodeServerMessage(deliver)
+
+	// Persist before acking so an ack means the message can no longer be lost.
+	duplicate, err := storeOnce(ctx, h.sends, p.GetClientMsgId(), m, func() error {
+		if h.inbox == nil || len(memberIDs) == 0 {
+			return nil
+		}
+		_, err := h.inbox.Store(ctx, m, memberIDs)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	for _, uid := range memberIDs {
-		targetSessions, err := h.reg.GetUserSessions(ctx, uid)
-		if err != nil {
-			return err
-		}
-		for _, ts := range targetSessions {
-			if ts == nil {
-				continue
-			}
-			_ = ts.Send(deliverBytes)
-		}
+	// Ack to sender. A retry was delivered the first time around.
+	if err := replySent(sess, msg.GetTraceId(), m.ID, duplicate); err != nil || duplicate {
+		return err
+	}
+
+	if len(memberIDs) == 0 {
+		return nil
+	}
+
+	// Deliver to each member's online sessions, and push to those without any.
+	if err := deliver(ctx, h.router, msg.GetTraceId(), m, memberIDs); err != nil {
+		return err
 	}
+	notifyOffline(ctx, h.router, h.pushes, m, memberIDs)
 	return nil
 }
-
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+)
+
+// HistoryHandler serves PULL_HISTORY and SYNC.
+type HistoryHandler struct {
+	history *history.HistoryService
+}
+
+func NewHistoryHandler(history *history.HistoryService) *HistoryHandler {
+	return &HistoryHandler{history: history}
+}
+
+func (h *HistoryHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.history == nil {
+		return fmt.Errorf("history service is nil")
+	}
+	switch msg.GetType() {
+	case imv1.MessageType_PULL_HISTORY:
+		return h.pull(ctx, sess, msg)
+	case imv1.MessageType_SYNC:
+		return h.sync(ctx, sess, msg)
+	default:
+		return fmt.Errorf("unsupported message type: %v", msg.GetType())
+	}
+}
+
+func (h *HistoryHandler) pull(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetPullHistory()
+	if p == nil {
+		return fmt.Errorf("missing pull_history payload")
+	}
+
+	cursor := message.Cursor{After: p.GetCursor(), Direction: message.Backward, Limit: int(p.GetLimit())}
+	if p.GetDirection() == imv1.PullHistory_FORWARD {
+		cursor.Direction = message.Forward
+	}
+	page, err := h.history.Pull(ctx, sess.UserID(), p.GetPeer(), p.GetGroupUuid(), cursor)
+	if err != nil {
+		return historyError(err)
+	}
+
+	resp := &imv1.HistoryResp{Messages: historyMessages(page.Messages), HasMore: page.HasMore}
+	if n := len(page.Messages); n > 0 && page.HasMore {
+		resp.NextCursor = page.Messages[n-1].ID
+	}
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_HistoryResp{HistoryResp: resp},
+	})
+}
+
+func (h *HistoryHandler) sync(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetSync()
+	if p == nil {
+		return fmt.Errorf("missing sync payload")
+	}
+
+	page, lastSeq, err := h.history.Sync(ctx, sess.UserID(), p.GetPeer(), p.GetGroupUuid(), p.GetFromSeq(), p.GetToSeq(), int(p.GetLimit()))
+	if err != nil {
+		return historyError(err)
+	}
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_SyncResp{
+			SyncResp: &imv1.SyncResp{Messages: historyMessages(page.Messages), LastSeq: lastSeq, HasMore: page.HasMore},
+		},
+	})
+}
+
+func historyMessages(messages []*domainmessage.Message) []*imv1.HistoryMessage {
+	out := make([]*imv1.HistoryMessage, 0, len(messages))
+	for _, m := range messages {
+		raw, content := decodeBody(m)
+		out = append(out, &imv1.HistoryMessage{
+			MessageId: m.ID,
+			From:      m.FromUser,
+			To:        m.ToUser,
+			GroupUuid: m.GroupUUID,
+			Message:   raw,
+			Content:   content,
+			CreatedAt: m.CreatedAt.UnixMilli(),
+			Seq:       m.Seq,
+			EditedAt:  unixMilli(m.EditedAt),
+			Recalled:  m.IsRecalled(),
+		})
+	}
+	return out
+}
+
+func historyError(err error) error {
+	if errors.Is(err, history.ErrInvalidConversation) {
+		return protocol.NewError(protocol.CodeBadRequest, err.Error())
+	}
+	return groupError(err)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
+)
+
+// maxPreviewBytes bounds the text shown in a push notification.
+const maxPreviewBytes = 100
+
+// notifyOffline hands the recipients of m without a session on any node to
+// pushes. The sender is never alerted about its own message. Push is best
+// effort, so a recipient whose presence cannot be looked up is skipped.
+func notifyOffline(ctx context.Context, router contract.Router, pushes *push.PushService, m *domainmessage.Message, recipients []string) {
+	if pushes == nil {
+		return
+	}
+	_, content := decodeBody(m)
+	var notifications []push.Notification
+	for _, uid := range recipients {
+		if uid == m.FromUser {
+			continue
+		}
+		if online, err := router.Online(ctx, uid); err != nil || online {
+			continue
+		}
+		notifications = append(notifications, push.Notification{
+			UserID:      uid,
+			MessageID:   m.ID,
+			From:        m.FromUser,
+			GroupUUID:   m.GroupUUID,
+			ContentType: m.ContentType,
+			Preview:     preview(content),
+			Mentioned:   m.IsGroup() && isMentioned(content, uid),
+			SentAt:      m.CreatedAt.UnixMilli(),
+		})
+	}
+	if len(notifications) > 0 {
+		pushes.Enqueue(ctx, notifications)
+	}
+}
+
+// preview returns the beginning of a text content, cut at a rune boundary.
+func preview(c *imv1.Content) string {
+	text := c.GetText().GetText()
+	if len(text) <= maxPreviewBytes {
+		return text
+	}
+	end := maxPreviewBytes
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/receipt"
+)
+
+// maxUnreadConversations caps the conversations of one GET_UNREAD.
+const maxUnreadConversations = 200
+
+// ReceiptHandler serves MARK_READ and GET_UNREAD.
+type ReceiptHandler struct {
+	router   contract.Router
+	receipts *receipt.ReceiptService
+}
+
+func NewReceiptHandler(router contract.Router, receipts *receipt.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{router: router, receipts: receipts}
+}
+
+func (h *ReceiptHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.receipts == nil {
+		return fmt.Errorf("receipt service is nil")
+	}
+	switch msg.GetType() {
+	case imv1.MessageType_MARK_READ:
+		return h.markRead(ctx, sess, msg)
+	case imv1.MessageType_GET_UNREAD:
+		return h.getUnread(ctx, sess, msg)
+	default:
+		return fmt.Errorf("unsupported message type: %v", msg.GetType())
+	}
+}
+
+func (h *ReceiptHandler) markRead(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetMarkRead()
+	if p == nil {
+		return fmt.Errorf("missing mark_read payload")
+	}
+	c := p.GetConversation()
+
+	seq, notify, err := h.receipts.MarkRead(ctx, sess.UserID(), c.GetPeer(), c.GetGroupUuid(), p.GetSeq())
+	if err != nil {
+		return receiptError(err)
+	}
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+	if h.router == nil {
+		return nil
+	}
+
+	for _, uid := range notify {
+		// A 1:1 receipt names the conversation from the receiver's side.
+		conversation := &imv1.Conversation{GroupUuid: c.GetGroupUuid()}
+		if c.GetGroupUuid() == "" {
+			conversation.Peer = sess.UserID()
+			if uid == sess.UserID() {
+				conversation.Peer = c.GetPeer()
+			}
+		}
+		data, err := protocol.EncodeServerMessage(&imv1.ServerEnvelope{
+			TraceId: msg.GetTraceId(),
+			Payload: &imv1.ServerEnvelope_ReadReceipt{
+				ReadReceipt: &imv1.ReadReceipt{Reader: sess.UserID(), Conversation: conversation, Seq: seq},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if err := h.router.Deliver(ctx, uid, 0, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *ReceiptHandler) getUnread(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	p := msg.GetGetUnread()
+	if p == nil {
+		return fmt.Errorf("missing get_unread payload")
+	}
+	if len(p.GetConversations()) > maxUnreadConversations {
+		return protocol.NewError(protocol.CodeBadRequest, fmt.Sprintf("at most %d conversations per request", maxUnreadConversations))
+	}
+
+	counts := make([]*imv1.UnreadCount, 0, len(p.GetConversations()))
+	for _, c := range p.GetConversations() {
+		u, err := h.receipts.Unread(ctx, sess.UserID(), c.GetPeer(), c.GetGroupUuid())
+		if err != nil {
+			return receiptError(err)
+		}
+		counts = append(counts, &imv1.UnreadCount{
+			Conversation: c,
+			Unread:       u.Count,
+			ReadSeq:      u.ReadSeq,
+			LastSeq:      u.LastSeq,
+		})
+	}
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: msg.GetTraceId(),
+		Payload: &imv1.ServerEnvelope_UnreadResp{UnreadResp: &imv1.UnreadResp{Counts: counts}},
+	})
+}
+
+func receiptError(err error) error {
+	if errors.Is(err, receipt.ErrInvalidSeq) {
+		return protocol.NewError(protocol.CodeBadRequest, err.Error())
+	}
+	return historyError(err)
+}
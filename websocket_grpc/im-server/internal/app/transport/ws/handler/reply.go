@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+)
+
+// reply encodes env and queues it on sess.
+func reply(sess contract.Session, env *imv1.ServerEnvelope) error {
+	out, err := protocol.EncodeServerMessage(env)
+	if err != nil {
+		return err
+	}
+	return sess.Send(out)
+}
+
+// replyAck answers a request with the generic "ok" acknowledgement.
+func replyAck(sess contract.Session, traceID string) error {
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: traceID,
+		Payload: &imv1.ServerEnvelope_AckResp{
+			AckResp: &imv1.AckResp{Status: 0, Message: "ok"},
+		},
+	})
+}
+
+// replySent acknowledges a message send with the ID of the stored message.
+func replySent(sess contract.Session, traceID string, messageID int64, duplicate bool) error {
+	return reply(sess, &imv1.ServerEnvelope{
+		TraceId: traceID,
+		Payload: &imv1.ServerEnvelope_AckResp{
+			AckResp: &imv1.AckResp{Status: 0, Message: "ok", MessageId: messageID, Duplicate: duplicate},
+		},
+	})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/revision"
+)
+
+// RevisionHandler serves RECALL_MESSAGE and EDIT_MESSAGE. The changed
+// message is delivered again under its original ID to every recipient, and
+// stays pending in their inbox until acknowledged.
+type RevisionHandler struct {
+	router    contract.Router
+	revisions *revision.RevisionService
+	limits    ContentLimits
+	blobs     *blob.BlobService
+}
+
+func NewRevisionHandler(router contract.Router, revisions *revision.RevisionService, limits ContentLimits, blobs *blob.BlobService) *RevisionHandler {
+	return &RevisionHandler{router: router, revisions: revisions, limits: limits, blobs: blobs}
+}
+
+func (h *RevisionHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.revisions == nil {
+		return fmt.Errorf("revision service is nil")
+	}
+
+	var (
+		m          *domainmessage.Message
+		recipients []string
+		err        error
+	)
+	switch msg.GetType() {
+	case imv1.MessageType_RECALL_MESSAGE:
+		p := msg.GetRecallMessage()
+		if p == nil {
+			return fmt.Errorf("missing recall_message payload")
+		}
+		m, recipients, err = h.revisions.Recall(ctx, sess.UserID(), p.GetMessageId())
+	case imv1.MessageType_EDIT_MESSAGE:
+		p := msg.GetEditMessage()
+		if p == nil {
+			return fmt.Errorf("missing edit_message payload")
+		}
+		contentType, content, encErr := encodeBody(p.GetMessage(), p.GetContent(), h.limits)
+		if encErr != nil {
+			return encErr
+		}
+		if err := checkAttachments(ctx, h.blobs, sess.UserID(), p.GetContent(), h.limits); err != nil {
+			return err
+		}
+		// @all alerts once, when the message is sent.
+		if mentionsAll(p.GetContent()) {
+			return protocol.NewError(protocol.CodeBadRequest, "an edit cannot mention all")
+		}
+		m, recipients, err = h.revisions.Edit(ctx, sess.UserID(), p.GetMessageId(), contentType, content)
+	default:
+		return fmt.Errorf("unsupported message type: %v", msg.GetType())
+	}
+	if err != nil {
+		return revisionError(err)
+	}
+
+	if err := replyAck(sess, msg.GetTraceId()); err != nil {
+		return err
+	}
+	if len(recipients) == 0 || h.router == nil {
+		return nil
+	}
+
+	return deliver(ctx, h.router, msg.GetTraceId(), m, recipients)
+}
+
+func revisionError(err error) error {
+	switch {
+	case errors.Is(err, revision.ErrMessageNotFound):
+		return protocol.NewError(protocol.CodeNotFound, err.Error())
+	case errors.Is(err, revision.ErrNotSender):
+		return protocol.NewError(protocol.CodePermissionDenied, err.Error())
+	case errors.Is(err, revision.ErrWindowClosed),
+		errors.Is(err, revision.ErrRecalled):
+		return protocol.NewError(protocol.CodeConflict, err.Error())
+	case errors.Is(err, revision.ErrEmptyContent):
+		return protocol.NewError(protocol.CodeBadRequest, err.Error())
+	default:
+		return err
+	}
+}
@@ -3,18 +3,34 @@ package handler
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
 	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/dedup"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
 )
 
 type SingleMessageHandler struct {
-	reg contract.Registry
+	router  contract.Router
+	friends *friend.FriendService
+	inbox   *inbox.InboxService
+	sends   *dedup.DedupService
+	pushes  *push.PushService
+	limits  ContentLimits
+	blobs   *blob.BlobService
 }
 
-func NewSingleMessageHandler(reg contract.Registry) *SingleMessageHandler {
-	return &SingleMessageHandler{reg: reg}
+// NewSingleMessageHandler returns the direct message handler. With friends
+// set, users can only message their friends; with blobs set, attachments
+// must be blobs the sender uploaded.
+func NewSingleMessageHandler(router contract.Router, friends *friend.FriendService, inbox *inbox.InboxService, sends *dedup.DedupService, pushes *push.PushService, limits ContentLimits, blobs *blob.BlobService) *SingleMessageHandler {
+	return &SingleMessageHandler{router: router, friends: friends, inbox: inbox, sends: sends, pushes: pushes, limits: limits, blobs: blobs}
 }
 
 func (h *SingleMessageHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
@@ -25,50 +41,61 @@ func (h *SingleMessageHandler) HandleMessage(ctx context.Context, sess contract.
 	if p.GetTo() == "" {
 		return fmt.Errorf("to is required")
 	}
-	if h.reg == nil {
-		return fmt.Errorf("registry is nil")
+	if h.router == nil {
+		return fmt.Errorf("router is nil")
 	}
-
-	// Ack to sender.
-	ack := &imv1.ServerEnvelope{
-		TraceId: msg.GetTraceId(),
-		Payload: &imv1.ServerEnvelope_AckResp{
-			AckResp: &imv1.AckResp{Status: 0, Message: "ok"},
-		},
-	}
-	ackBytes, err := protocol.EncodeServerMessage(ack)
+	contentType, content, err := encodeBody(p.GetMessage(), p.GetContent(), h.limits)
 	if err != nil {
 		return err
 	}
-	if err := sess.Send(ackBytes); err != nil {
+	if err := checkAttachments(ctx, h.blobs, sess.UserID(), p.GetContent(), h.limits); err != nil {
+		return err
+	}
+	if mentionsAll(p.GetContent()) {
+		return protocol.NewError(protocol.CodeBadRequest, "mentioning all needs a group")
+	}
+	if err := validateClientMsgID(p.GetClientMsgId()); err != nil {
 		return err
 	}
+	if h.friends != nil && p.GetTo() != sess.UserID() {
+		ok, err := h.friends.IsFriend(ctx, sess.UserID(), p.GetTo())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return protocol.NewError(protocol.CodeForbidden, "recipient is not a friend")
+		}
+	}
 
-	// Deliver to recipient's online sessions.
-	deliver := &imv1.ServerEnvelope{
-		TraceId: msg.GetTraceId(),
-		Payload: &imv1.ServerEnvelope_DeliverSingleMessage{
-			DeliverSingleMessage: &imv1.DeliverSingleMessage{
-				From:    sess.UserID(),
-				Message: p.GetMessage(),
-			},
-		},
+	m := &domainmessage.Message{
+		FromUser:    sess.UserID(),
+		ToUser:      p.GetTo(),
+		ContentType: contentType,
+		Content:     content,
+		CreatedAt:   time.Now(),
 	}
-	deliverBytes, err := protocol.EncodeServerMessage(deliver)
+
+	// Persist before acking so an ack means the message can no longer be lost.
+	duplicate, err := storeOnce(ctx, h.sends, p.GetClientMsgId(), m, func() error {
+		if h.inbox == nil {
+			return nil
+		}
+		_, err := h.inbox.Store(ctx, m, []string{p.GetTo()})
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
-	targetSessions, err := h.reg.GetUserSessions(ctx, p.GetTo())
-	if err != nil {
+	// Ack to sender. A retry was delivered the first time around.
+	if err := replySent(sess, msg.GetTraceId(), m.ID, duplicate); err != nil || duplicate {
 		return err
 	}
-	for _, ts := range targetSessions {
-		if ts == nil {
-			continue
-		}
-		_ = ts.Send(deliverBytes)
+
+	// Deliver to recipient's online sessions on any node, or push if there are none.
+	if err := deliver(ctx, h.router, msg.GetTraceId(), m, []string{p.GetTo()}); err != nil {
+		return err
 	}
+	notifyOffline(ctx, h.router, h.pushes, m, []string{p.GetTo()})
 	return nil
 }
-
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/presence"
+)
+
+// TypingHandler serves TYPING_START and TYPING_STOP. Indicators are best
+// effort: the sender gets no ack.
+type TypingHandler struct {
+	router contract.Router
+	typing *presence.TypingService
+}
+
+func NewTypingHandler(router contract.Router, typing *presence.TypingService) *TypingHandler {
+	return &TypingHandler{router: router, typing: typing}
+}
+
+func (h *TypingHandler) HandleMessage(ctx context.Context, sess contract.Session, msg *imv1.ClientEnvelope) error {
+	if h.typing == nil {
+		return fmt.Errorf("typing service is nil")
+	}
+	p := msg.GetTyping()
+	if p == nil {
+		return fmt.Errorf("missing typing payload")
+	}
+	c := p.GetConversation()
+	typing := msg.GetType() == imv1.MessageType_TYPING_START
+
+	recipients, err := h.typing.Typing(ctx, sess.UserID(), c.GetPeer(), c.GetGroupUuid(), typing)
+	if errors.Is(err, presence.ErrNotFriend) {
+		return protocol.NewError(protocol.CodeForbidden, err.Error())
+	}
+	if err != nil {
+		return historyError(err)
+	}
+	if len(recipients) == 0 || h.router == nil {
+		return nil
+	}
+
+	// A 1:1 indicator names the conversation from the receiver's side.
+	conversation := &imv1.Conversation{GroupUuid: c.GetGroupUuid()}
+	if c.GetGroupUuid() == "" {
+		conversation.Peer = sess.UserID()
+	}
+	data, err := protocol.EncodeServerMessage(&imv1.ServerEnvelope{
+		Payload: &imv1.ServerEnvelope_TypingEvent{
+			TypingEvent: &imv1.TypingEvent{User: sess.UserID(), Conversation: conversation, Typing: typing},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	for _, uid := range recipients {
+		if err := h.router.Deliver(ctx, uid, 0, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
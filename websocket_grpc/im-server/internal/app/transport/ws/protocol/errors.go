@@ -0,0 +1,42 @@
+package protocol
+
+import "fmt"
+
+// Error codes carried by imv1.Error.
+const (
+	CodeBadRequest = "BAD_REQUEST"
+	CodeBadProto   = "BAD_PROTO"
+	CodeInternal   = "INTERNAL"
+	CodeNotFound   = "NOT_FOUND"
+	CodeConflict   = "CONFLICT"
+	CodeForbidden  = "FORBIDDEN"
+	// CodeUnauthorized rejects bad credentials and requests that need a
+	// logged-in connection.
+	CodeUnauthorized = "UNAUTHORIZED"
+	// CodeNotMember and CodePermissionDenied are group authorization
+	// failures: the caller is not in the group, or their role is too low.
+	CodeNotMember        = "NOT_MEMBER"
+	CodePermissionDenied = "PERMISSION_DENIED"
+)
+
+// Error is returned by handlers to answer the client with a specific code
+// instead of a generic internal error.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func NewError(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Websocket close codes sent when the server ends a session.
+const (
+	CloseTokenExpired = 4001
+	CloseKicked       = 4002
+	CloseRevoked      = 4003
+)
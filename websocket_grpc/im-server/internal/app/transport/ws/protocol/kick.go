@@ -0,0 +1,24 @@
+package protocol
+
+import (
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+)
+
+// Kick tells old that a session of deviceID on platform displaced it and
+// closes it.
+func Kick(old contract.Session, deviceID, platform string) {
+	reason := imv1.Kicked_OTHER_DEVICE
+	if old.DeviceID() == deviceID {
+		reason = imv1.Kicked_SAME_DEVICE
+	}
+	payload, err := EncodeServerMessage(&imv1.ServerEnvelope{
+		Payload: &imv1.ServerEnvelope_Kicked{
+			Kicked: &imv1.Kicked{Reason: reason, DeviceId: deviceID, Platform: platform},
+		},
+	})
+	if err == nil {
+		_ = old.Send(payload)
+	}
+	old.Disconnect(CloseKicked, "logged in elsewhere")
+}
@@ -10,6 +10,28 @@ import (
 // without importing the ws implementation package.
 type Registry = contract.Registry
 
+// Router is aliased for the same reason as Registry.
+type Router = contract.Router
+
+// DevicePolicy and its modes are aliased so callers configuring a Server do
+// not need the registry package.
+type (
+	DevicePolicy = wsregistry.DevicePolicy
+	DeviceMode   = wsregistry.DeviceMode
+	PolicyFunc   = wsregistry.PolicyFunc
+)
+
+const (
+	DeviceModeMulti       = wsregistry.DeviceModeMulti
+	DeviceModePerPlatform = wsregistry.DeviceModePerPlatform
+	DeviceModeSingle      = wsregistry.DeviceModeSingle
+)
+
 func NewRegistry() Registry {
 	return wsregistry.NewRegistry()
 }
+
+// NewRegistryWithPolicy returns a registry enforcing a device policy per user.
+func NewRegistryWithPolicy(policy PolicyFunc) Registry {
+	return wsregistry.NewRegistryWithPolicy(policy)
+}
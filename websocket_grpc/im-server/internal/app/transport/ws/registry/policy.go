@@ -0,0 +1,42 @@
+package registry
+
+// DeviceMode selects how many sessions a user may keep open at once.
+type DeviceMode int
+
+const (
+	// DeviceModeMulti allows up to MaxDevices sessions; the oldest one is
+	// displaced when another device connects.
+	DeviceModeMulti DeviceMode = iota
+	// DeviceModePerPlatform allows one session per platform.
+	DeviceModePerPlatform
+	// DeviceModeSingle allows one session; a new login displaces it.
+	DeviceModeSingle
+)
+
+// DevicePolicy limits the concurrent sessions of a user. A session always
+// displaces an earlier one of the same device, whatever the policy.
+type DevicePolicy struct {
+	Mode DeviceMode
+	// MaxDevices caps the sessions in DeviceModeMulti; zero means unlimited.
+	// It is counted per node.
+	MaxDevices int
+}
+
+// PolicyFunc returns the policy of userID.
+type PolicyFunc func(userID string) DevicePolicy
+
+// conflicts reports whether existing must make way for a session of
+// deviceID on platform.
+func (p DevicePolicy) conflicts(existing entry, deviceID, platform string) bool {
+	if existing.session.DeviceID() == deviceID {
+		return true
+	}
+	switch p.Mode {
+	case DeviceModeSingle:
+		return true
+	case DeviceModePerPlatform:
+		return existing.session.Platform() == platform
+	default:
+		return false
+	}
+}
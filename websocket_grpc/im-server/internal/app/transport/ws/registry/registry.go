@@ -2,45 +2,116 @@ package registry
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
 )
 
+type entry struct {
+	session contract.Session
+	seq     uint64 // bind order, to find the oldest session
+}
+
 type registry struct {
-	mu sync.Mutex
+	mu     sync.Mutex
+	policy PolicyFunc
+	seq    uint64
 	// user -> device -> session
-	sessions map[string]map[string]contract.Session
+	sessions map[string]map[string]entry
 }
 
+// NewRegistry returns a registry without a device limit.
 func NewRegistry() contract.Registry {
+	return NewRegistryWithPolicy(nil)
+}
+
+// NewRegistryWithPolicy returns a registry enforcing the policy of each user.
+// A nil policy allows any number of devices.
+func NewRegistryWithPolicy(policy PolicyFunc) contract.Registry {
+	if policy == nil {
+		policy = func(string) DevicePolicy { return DevicePolicy{} }
+	}
 	return &registry{
-		sessions: make(map[string]map[string]contract.Session),
+		policy:   policy,
+		sessions: make(map[string]map[string]entry),
 	}
 }
 
-func (r *registry) Bind(ctx context.Context, session contract.Session) error {
+// Bind adds session and removes the sessions the user's policy no longer
+// allows. The caller is responsible for closing the displaced sessions.
+func (r *registry) Bind(ctx context.Context, session contract.Session) ([]contract.Session, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.sessions[session.UserID()]; !ok {
-		r.sessions[session.UserID()] = make(map[string]contract.Session)
+	devices, ok := r.sessions[session.UserID()]
+	if !ok {
+		devices = make(map[string]entry)
+		r.sessions[session.UserID()] = devices
 	}
-	r.sessions[session.UserID()][session.DeviceID()] = session
-	return nil
+	r.seq++
+	incoming := entry{session: session, seq: r.seq}
+	policy := r.policy(session.UserID())
+
+	var displaced []contract.Session
+	for deviceID, e := range devices {
+		if policy.conflicts(e, session.DeviceID(), session.Platform()) {
+			displaced = append(displaced, e.session)
+			delete(devices, deviceID)
+		}
+	}
+	if policy.Mode == DeviceModeMulti && policy.MaxDevices > 0 && len(devices) >= policy.MaxDevices {
+		remaining := make([]entry, 0, len(devices))
+		for _, e := range devices {
+			remaining = append(remaining, e)
+		}
+		sort.Slice(remaining, func(i, j int) bool { return remaining[i].seq < remaining[j].seq })
+		for _, e := range remaining[:len(remaining)-policy.MaxDevices+1] {
+			displaced = append(displaced, e.session)
+			delete(devices, e.session.DeviceID())
+		}
+	}
+	devices[session.DeviceID()] = incoming
+	return displaced, nil
 }
 
-func (r *registry) Unbind(ctx context.Context, userID, DeviceID string) error {
+// Displace removes the sessions of userID that the user's policy does not
+// allow next to a session of deviceID on platform bound on another node.
+// The caller is responsible for closing them.
+func (r *registry) Displace(ctx context.Context, userID, deviceID, platform string) ([]contract.Session, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	devices, ok := r.sessions[userID]
+
+	devices := r.sessions[userID]
+	policy := r.policy(userID)
+	var displaced []contract.Session
+	for id, e := range devices {
+		if policy.conflicts(e, deviceID, platform) {
+			displaced = append(displaced, e.session)
+			delete(devices, id)
+		}
+	}
+	if len(devices) == 0 {
+		delete(r.sessions, userID)
+	}
+	return displaced, nil
+}
+
+// Unbind removes session unless another session of the same device already
+// replaced it.
+func (r *registry) Unbind(ctx context.Context, session contract.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	devices, ok := r.sessions[session.UserID()]
 	if !ok {
 		return nil
 	}
 
-	delete(devices, DeviceID)
+	if e, ok := devices[session.DeviceID()]; ok && e.session == session {
+		delete(devices, session.DeviceID())
+	}
 	if len(devices) == 0 {
-		delete(r.sessions, userID)
+		delete(r.sessions, session.UserID())
 	}
 
 	return nil
@@ -51,8 +122,8 @@ func (r *registry) GetUserSessions(ctx context.Context, userID string) ([]contra
 	defer r.mu.Unlock()
 
 	sessions := make([]contract.Session, 0, len(r.sessions[userID]))
-	for _, session := range r.sessions[userID] {
-		sessions = append(sessions, session)
+	for _, e := range r.sessions[userID] {
+		sessions = append(sessions, e.session)
 	}
 
 	return sessions, nil
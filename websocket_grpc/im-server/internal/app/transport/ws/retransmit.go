@@ -0,0 +1,105 @@
+package ws
+
+import (
+	"sync"
+	"time"
+)
+
+// RetransmitPolicy controls how unacknowledged deliveries are resent.
+// A zero InitialBackoff disables retransmission.
+type RetransmitPolicy struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type pendingDelivery struct {
+	payload []byte
+	backoff time.Duration
+	due     time.Time
+}
+
+// retransmitter tracks the deliveries of one session that the client has not
+// acknowledged yet. It is dropped together with the session; whatever is still
+// pending then stays undelivered in the inbox and is replayed on reconnect.
+type retransmitter struct {
+	policy  RetransmitPolicy
+	mu      sync.Mutex
+	pending map[int64]*pendingDelivery
+}
+
+func newRetransmitter(policy RetransmitPolicy) *retransmitter {
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = policy.InitialBackoff
+	}
+	return &retransmitter{
+		policy:  policy,
+		pending: make(map[int64]*pendingDelivery),
+	}
+}
+
+func (r *retransmitter) enabled() bool {
+	return r.policy.InitialBackoff > 0
+}
+
+// tick is how often the write loop should look for due deliveries.
+func (r *retransmitter) tick() time.Duration {
+	if t := r.policy.InitialBackoff / 2; t > 0 {
+		return t
+	}
+	return r.policy.InitialBackoff
+}
+
+// track schedules payload for retransmission until messageID is acked. A
+// message that is still pending keeps its schedule but resends the newer
+// payload, such as an edited version.
+func (r *retransmitter) track(messageID int64, payload []byte, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if p, ok := r.pending[messageID]; ok {
+		p.payload = payload
+		return
+	}
+	r.pending[messageID] = &pendingDelivery{
+		payload: payload,
+		backoff: r.policy.InitialBackoff,
+		due:     now.Add(r.policy.InitialBackoff),
+	}
+}
+
+// ack stops retransmitting messageID and reports whether it was pending.
+func (r *retransmitter) ack(messageID int64) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.pending[messageID]
+	delete(r.pending, messageID)
+	return ok
+}
+
+// due returns the payloads whose backoff elapsed and schedules their next
+// attempt with a doubled backoff, capped at MaxBackoff.
+func (r *retransmitter) due(now time.Time) [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out [][]byte
+	for _, p := range r.pending {
+		if now.Before(p.due) {
+			continue
+		}
+		out = append(out, p.payload)
+		p.backoff *= 2
+		if p.backoff > r.policy.MaxBackoff {
+			p.backoff = r.policy.MaxBackoff
+		}
+		p.due = now.Add(p.backoff)
+	}
+	return out
+}
+
+func (r *retransmitter) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
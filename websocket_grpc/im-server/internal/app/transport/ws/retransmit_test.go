@@ -0,0 +1,42 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetransmitter_BackoffUntilAck(t *testing.T) {
+	r := newRetransmitter(RetransmitPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second})
+	now := time.Unix(0, 0)
+	r.track(1, []byte("m1"), now)
+
+	if got := r.due(now.Add(500 * time.Millisecond)); len(got) != 0 {
+		t.Fatalf("should not be due yet, got %d", len(got))
+	}
+	// attempts at +1s, +3s (backoff 2s), +6s (backoff capped at 3s)
+	for _, at := range []time.Duration{time.Second, 3 * time.Second, 6 * time.Second} {
+		if got := r.due(now.Add(at)); len(got) != 1 {
+			t.Fatalf("expected retransmit at %v, got %d", at, len(got))
+		}
+	}
+	if got := r.due(now.Add(8 * time.Second)); len(got) != 0 {
+		t.Fatalf("should not be due before +9s, got %d", len(got))
+	}
+
+	if !r.ack(1) {
+		t.Fatalf("ack should report pending delivery")
+	}
+	if r.ack(1) {
+		t.Fatalf("second ack should be a no-op")
+	}
+	if got := r.due(now.Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("acked delivery should not be retransmitted, got %d", len(got))
+	}
+}
+
+func TestRetransmitter_Disabled(t *testing.T) {
+	r := newRetransmitter(RetransmitPolicy{})
+	if r.enabled() {
+		t.Fatalf("zero policy should disable retransmission")
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
 	"go.uber.org/zap"
 )
 
@@ -18,7 +20,18 @@ type ServerOptions struct {
 	PongWait       time.Duration
 	Logger         *zap.Logger
 	Registry       Registry
+	Router         Router // reaches users on other nodes; defaults to Registry only
 	Authenticator  auth.Authenticator
+	// AllowAnonymous accepts connections without a bearer token. They can
+	// only register and log in, and are never reachable by other users.
+	AllowAnonymous bool
+	// Inbox persists messages for offline recipients. Optional.
+	Inbox *inbox.InboxService
+	// Retransmit controls resending of unacknowledged deliveries.
+	Retransmit RetransmitPolicy
+	// Dispatch routes client messages. Optional; defaults to the handlers
+	// that only need Registry, Router and Inbox.
+	Dispatch dispatch.Dispatcher
 }
 
 type Server struct {
@@ -36,7 +49,12 @@ func NewServer(opts ServerOptions) *Server {
 			PongWait:       opts.PongWait,
 			Logger:         opts.Logger,
 			Registry:       opts.Registry,
+			Router:         opts.Router,
 			Authenticator:  opts.Authenticator,
+			AllowAnonymous: opts.AllowAnonymous,
+			Inbox:          opts.Inbox,
+			Retransmit:     opts.Retransmit,
+			Dispatch:       opts.Dispatch,
 		}),
 	}
 }
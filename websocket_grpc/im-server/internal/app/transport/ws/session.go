@@ -2,35 +2,71 @@ package ws
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
-var ErrBackPressure = errors.New("backpressure")
+var (
+	ErrBackPressure  = errors.New("backpressure")
+	ErrSessionClosed = errors.New("session closed")
+)
 
 type Session struct {
 	userID       string
 	deviceID     string
+	platform     string
 	nodeID       string
 	conn         *websocket.Conn
 	send         chan []byte
+	retx         *retransmitter
 	WriteTimeout time.Duration
+
+	// done is closed once the session should stop writing; send is never
+	// closed so late senders cannot panic.
+	done      chan struct{}
+	closeOnce sync.Once
+	// refreshed wakes the write loop to re-arm the expiry timer.
+	refreshed chan struct{}
+
+	mu        sync.Mutex
+	tokenID   string
+	expiresAt time.Time // zero means the session never expires
+	closeCode int
+	closeText string
+	// held keeps live deliveries while the inbox is replayed, so they are
+	// queued after the older replayed messages.
+	holding bool
+	held    []heldDelivery
+}
+
+type heldDelivery struct {
+	messageID int64
+	data      []byte
 }
 
 func NewSession(userID, deviceID, nodeID string,
-	conn *websocket.Conn, sendQueueSize int, writeTimeout time.Duration) *Session {
+	conn *websocket.Conn, sendQueueSize int, writeTimeout time.Duration, retransmit RetransmitPolicy) *Session {
 	return &Session{
 		userID:       userID,
 		deviceID:     deviceID,
 		nodeID:       nodeID,
 		conn:         conn,
 		send:         make(chan []byte, sendQueueSize),
+		retx:         newRetransmitter(retransmit),
 		WriteTimeout: writeTimeout,
+		done:         make(chan struct{}),
+		refreshed:    make(chan struct{}, 1),
 	}
 }
 
 func (s *Session) Send(data []byte) error {
+	select {
+	case <-s.done:
+		return ErrSessionClosed
+	default:
+	}
 	select {
 	case s.send <- data:
 		return nil
@@ -39,11 +75,91 @@ func (s *Session) Send(data []byte) error {
 	}
 }
 
+func (s *Session) Deliver(messageID int64, data []byte) error {
+	s.mu.Lock()
+	if s.holding {
+		defer s.mu.Unlock()
+		if len(s.held) >= cap(s.send) {
+			return ErrBackPressure
+		}
+		s.held = append(s.held, heldDelivery{messageID: messageID, data: data})
+		return nil
+	}
+	s.mu.Unlock()
+	return s.deliver(messageID, data)
+}
+
+// hold makes Deliver keep deliveries until release.
+func (s *Session) hold() {
+	s.mu.Lock()
+	s.holding = true
+	s.mu.Unlock()
+}
+
+// release queues the held deliveries in their order and stops holding. A
+// delivery that no longer fits is dropped; it stays in the inbox.
+func (s *Session) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.held {
+		if err := s.deliver(d.messageID, d.data); err != nil {
+			break
+		}
+	}
+	s.holding, s.held = false, nil
+}
+
+// deliver queues data even while deliveries are held.
+func (s *Session) deliver(messageID int64, data []byte) error {
+	if messageID != 0 && s.retx.enabled() {
+		s.retx.track(messageID, data, time.Now())
+	}
+	return s.Send(data)
+}
+
+func (s *Session) Acknowledge(messageID int64) bool {
+	return s.retx.ack(messageID)
+}
+
+// Disconnect asks the write loop to flush the queued data, send a close frame
+// with code and end the connection. Only the first code is kept.
+func (s *Session) Disconnect(code int, reason string) {
+	s.mu.Lock()
+	if s.closeCode == 0 {
+		s.closeCode, s.closeText = code, reason
+	}
+	s.mu.Unlock()
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// Close stops the session and closes the connection; it may be called more than once.
 func (s *Session) Close() error {
-	close(s.send)
+	s.closeOnce.Do(func() { close(s.done) })
 	return s.conn.Close()
 }
 
+func (s *Session) closeReason() (int, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeCode, s.closeText
+}
+
+func (s *Session) Refresh(tokenID string, expiresAt time.Time) {
+	s.mu.Lock()
+	s.tokenID, s.expiresAt = tokenID, expiresAt
+	s.mu.Unlock()
+	select {
+	case s.refreshed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Session) ExpiresAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expiresAt
+}
+
 func (s *Session) UserID() string {
 	return s.userID
 }
@@ -52,6 +168,16 @@ func (s *Session) DeviceID() string {
 	return s.deviceID
 }
 
+func (s *Session) Platform() string {
+	return s.platform
+}
+
+func (s *Session) TokenID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokenID
+}
+
 func (s *Session) NodeID() string {
 	return s.nodeID
 }
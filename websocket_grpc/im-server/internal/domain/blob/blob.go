@@ -0,0 +1,12 @@
+package blob
+
+import "time"
+
+// Blob is an uploaded attachment. Its ID is the hex SHA-256 of the content,
+// so the same bytes uploaded twice are stored once.
+type Blob struct {
+	ID          string    `json:"id"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
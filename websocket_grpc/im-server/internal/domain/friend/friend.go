@@ -2,10 +2,11 @@ package friend
 
 import "time"
 
+// Friend is one direction of a friendship; accepting a request stores both.
 type Friend struct {
 	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	FriendID  int64     `json:"friend_id"`
+	UserID    string    `json:"user_id"`
+	FriendID  string    `json:"friend_id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -0,0 +1,24 @@
+package friend
+
+import "time"
+
+type RequestStatus int
+
+const (
+	RequestPending RequestStatus = iota + 1
+	RequestAccepted
+	RequestRejected
+)
+
+// FriendRequest is an application from FromUser to become friends with ToUser.
+// RequestID is chosen by the applicant's client and identifies the request
+// in ACCEPT_FRIEND / REJECT_FRIEND.
+type FriendRequest struct {
+	ID        int64         `json:"id"`
+	RequestID string        `json:"request_id"`
+	FromUser  string        `json:"from_user"`
+	ToUser    string        `json:"to_user"`
+	Status    RequestStatus `json:"status"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
@@ -6,6 +6,7 @@ type Group struct {
 	ID        int64     `json:"id"`
 	UUID      string    `json:"uuid"`
 	Name      string    `json:"name"`
+	Owner     string    `json:"owner"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
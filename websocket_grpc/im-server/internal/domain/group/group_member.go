@@ -2,10 +2,51 @@ package group
 
 import "time"
 
+// Role orders a member's privileges within a group; a higher role may
+// manage members of a lower one.
+type Role int
+
+const (
+	RoleMember Role = iota + 1
+	RoleAdmin
+	RoleOwner
+)
+
+// NotifyLevel is a member's notification preference for one group.
+type NotifyLevel int
+
+const (
+	NotifyAll NotifyLevel = iota
+	// NotifyMentions only alerts about messages mentioning the member.
+	NotifyMentions
+	// NotifyNone mutes the group.
+	NotifyNone
+)
+
+// Valid reports whether l is one of the defined levels.
+func (l NotifyLevel) Valid() bool {
+	return l >= NotifyAll && l <= NotifyNone
+}
+
+// Notifies reports whether a member with level l is alerted about a
+// message, given whether the message mentions them.
+func (l NotifyLevel) Notifies(mentioned bool) bool {
+	switch l {
+	case NotifyAll:
+		return true
+	case NotifyMentions:
+		return mentioned
+	default:
+		return false
+	}
+}
+
 type GroupMember struct {
-	ID        int64     `json:"id"`
-	GroupID   int64     `json:"group_id"`
-	UserID    int64     `json:"user_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        int64       `json:"id"`
+	GroupID   int64       `json:"group_id"`
+	UserID    string      `json:"user_id"`
+	Role      Role        `json:"role"`
+	Notify    NotifyLevel `json:"notify"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
 }
@@ -0,0 +1,26 @@
+package group
+
+import "time"
+
+type RequestStatus int
+
+const (
+	RequestPending RequestStatus = iota + 1
+	RequestAccepted
+	RequestRejected
+)
+
+// GroupRequest asks for UserID to join the group GroupID, answered using
+// RequestID. An application by UserID is answered by the group owner; an
+// invitation is answered by UserID.
+type GroupRequest struct {
+	ID        int64  `json:"id"`
+	RequestID string `json:"request_id"`
+	GroupID   int64  `json:"group_id"`
+	UserID    string `json:"user_id"`
+	// InvitedBy is the member who invited UserID; empty for applications.
+	InvitedBy string        `json:"invited_by"`
+	Status    RequestStatus `json:"status"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
@@ -0,0 +1,42 @@
+package group
+
+// Action is an operation a member attempts on a group.
+type Action int
+
+const (
+	ActionPost Action = iota + 1
+	ActionInvite
+	ActionKick
+	ActionRename
+	ActionDissolve
+	ActionSetRole
+	ActionApprove
+	ActionRead
+	ActionMentionAll
+)
+
+// minRole is the lowest role allowed to perform each action.
+var minRole = map[Action]Role{
+	ActionPost:       RoleMember,
+	ActionRead:       RoleMember,
+	ActionInvite:     RoleAdmin,
+	ActionKick:       RoleAdmin,
+	ActionRename:     RoleAdmin,
+	ActionMentionAll: RoleAdmin,
+	ActionDissolve:   RoleOwner,
+	ActionSetRole:    RoleOwner,
+	ActionApprove:    RoleOwner,
+}
+
+// Can reports whether a member with role may perform action.
+func Can(role Role, action Action) bool {
+	min, ok := minRole[action]
+	return ok && role >= min
+}
+
+// CanManage reports whether actor may perform action on a member holding
+// target, e.g. kick them or change their role. Members can only be managed
+// by someone ranked strictly above them, so the owner is never a target.
+func CanManage(actor, target Role, action Action) bool {
+	return Can(actor, action) && actor > target
+}
@@ -2,10 +2,13 @@ package inbox
 
 import "time"
 
+// Inbox links a recipient to a message it should receive. Entries stay
+// undelivered until the message reached one of the recipient's sessions.
 type Inbox struct {
 	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
+	UserID    string    `json:"user_id"`
 	MessageID int64     `json:"message_id"`
+	Delivered bool      `json:"delivered"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
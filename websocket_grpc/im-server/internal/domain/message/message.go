@@ -1,15 +1,81 @@
 package message
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Message is the core domain model for chat messages.
 // Keep this minimal for now; expand as business requirements grow.
 type Message struct {
 	ID        int64
-	FromUser  int64
-	ToUser    int64
-	GroupID   int64
-	Content   string
-	CreatedAt time.Time
+	FromUser  string
+	ToUser    string
+	GroupUUID string
+	// Seq orders the message within its conversation. It starts at 1 and has
+	// no gaps, so a recipient can detect missed messages.
+	Seq int64
+	// ContentType tells how Content is encoded: untyped messages carry
+	// client-defined bytes, typed ones an encoded imv1.Content.
+	ContentType string
+	Content     []byte
+	CreatedAt   time.Time
+	// EditedAt is the time of the last edit, nil if never edited.
+	EditedAt *time.Time
+	// RecalledAt is set when the sender withdrew the message; a recalled
+	// message is kept as a tombstone without content.
+	RecalledAt *time.Time
 }
 
+// ClientSend remembers the message created by a send that the client tagged
+// with ClientMsgID, so a retry of the send is recognized.
+type ClientSend struct {
+	Sender      string
+	ClientMsgID string
+	MessageID   int64
+	CreatedAt   time.Time
+}
+
+// Content types of a message.
+const (
+	ContentUntyped  = ""
+	ContentText     = "text"
+	ContentImage    = "image"
+	ContentFile     = "file"
+	ContentAudio    = "audio"
+	ContentLocation = "location"
+	ContentCustom   = "custom"
+)
+
+// IsGroup reports whether the message was sent to a group conversation.
+func (m *Message) IsGroup() bool {
+	return m.GroupUUID != ""
+}
+
+// IsRecalled reports whether m is a tombstone.
+func (m *Message) IsRecalled() bool {
+	return m.RecalledAt != nil
+}
+
+// ConversationID identifies the conversation m belongs to.
+func (m *Message) ConversationID() string {
+	if m.IsGroup() {
+		return GroupConversation(m.GroupUUID)
+	}
+	return PairConversation(m.FromUser, m.ToUser)
+}
+
+// GroupConversation is the conversation ID of a group.
+func GroupConversation(groupUUID string) string {
+	return "g:" + groupUUID
+}
+
+// PairConversation is the conversation ID of the 1:1 chat between a and b,
+// the same whichever of them sends.
+func PairConversation(a, b string) string {
+	if a > b {
+		a, b = b, a
+	}
+	// The length prefix keeps IDs containing ':' unambiguous.
+	return fmt.Sprintf("p:%d:%s:%s", len(a), a, b)
+}
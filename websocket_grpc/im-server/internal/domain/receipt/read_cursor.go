@@ -0,0 +1,12 @@
+package receipt
+
+import "time"
+
+// ReadCursor is how far a user has read a conversation: every message with a
+// sequence number up to Seq counts as read.
+type ReadCursor struct {
+	UserID         string    `json:"user_id"`
+	ConversationID string    `json:"conversation_id"`
+	Seq            int64     `json:"seq"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,38 @@
+package token
+
+import (
+	"slices"
+	"time"
+)
+
+// Platforms a token may be bound to. Empty means unspecified.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWeb     = "web"
+	PlatformDesktop = "desktop"
+)
+
+// ValidPlatform reports whether platform is empty or one of the known ones.
+func ValidPlatform(platform string) bool {
+	return platform == "" || slices.Contains([]string{PlatformIOS, PlatformAndroid, PlatformWeb, PlatformDesktop}, platform)
+}
+
+// Token is an access token issued at login. Only the SHA-256 hash of the
+// token (of its "jti" claim for signed tokens) is stored, so a leaked table
+// cannot be replayed.
+type Token struct {
+	ID        int64      `json:"id"`
+	Hash      string     `json:"hash"`
+	UserID    string     `json:"user_id"`
+	DeviceID  string     `json:"device_id"`
+	Platform  string     `json:"platform"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Valid reports whether the token may still be used at now.
+func (t *Token) Valid(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
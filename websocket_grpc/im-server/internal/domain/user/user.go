@@ -3,12 +3,14 @@ package user
 import "time"
 
 type User struct {
-	ID        int64     `json:"id"`
-	UUID      string    `json:"uuid"`
-	Email     string    `json:"email"`
-	Phone     string    `json:"phone"`
-	Name      string    `json:"name"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID    int64  `json:"id"`
+	UUID  string `json:"uuid"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	Name  string `json:"name"`
+	// PasswordHash is the bcrypt hash of the password; the plain password is
+	// never stored.
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -24,22 +24,40 @@ const (
 type MessageType int32
 
 const (
-	MessageType_UNSPECIFIED       MessageType = 0
-	MessageType_ECHO              MessageType = 1
-	MessageType_REGISTER          MessageType = 2
-	MessageType_LOGIN             MessageType = 3
-	MessageType_LOGOUT            MessageType = 4
-	MessageType_APPLY_FOR_FRIEND  MessageType = 5
-	MessageType_ACCEPT_FRIEND     MessageType = 6
-	MessageType_REJECT_FRIEND     MessageType = 7
-	MessageType_CREATE_GROUP      MessageType = 8
-	MessageType_APPLY_FOR_GROUP   MessageType = 9
-	MessageType_ACCEPT_GROUP      MessageType = 10
-	MessageType_REJECT_GROUP      MessageType = 11
-	MessageType_LIST_GROUPS       MessageType = 13
-	MessageType_LIST_GROUP_MEMBER MessageType = 14
-	MessageType_SINGLE_MESSAGE    MessageType = 15
-	MessageType_GROUP_MESSAGE     MessageType = 16
+	MessageType_UNSPECIFIED         MessageType = 0
+	MessageType_ECHO                MessageType = 1
+	MessageType_REGISTER            MessageType = 2
+	MessageType_LOGIN               MessageType = 3
+	MessageType_LOGOUT              MessageType = 4
+	MessageType_APPLY_FOR_FRIEND    MessageType = 5
+	MessageType_ACCEPT_FRIEND       MessageType = 6
+	MessageType_REJECT_FRIEND       MessageType = 7
+	MessageType_CREATE_GROUP        MessageType = 8
+	MessageType_APPLY_FOR_GROUP     MessageType = 9
+	MessageType_ACCEPT_GROUP        MessageType = 10
+	MessageType_REJECT_GROUP        MessageType = 11
+	MessageType_LIST_GROUPS         MessageType = 13
+	MessageType_LIST_GROUP_MEMBER   MessageType = 14
+	MessageType_SINGLE_MESSAGE      MessageType = 15
+	MessageType_GROUP_MESSAGE       MessageType = 16
+	MessageType_DELIVERY_ACK        MessageType = 17
+	MessageType_INVITE_GROUP_MEMBER MessageType = 18
+	MessageType_KICK_GROUP_MEMBER   MessageType = 19
+	MessageType_RENAME_GROUP        MessageType = 20
+	MessageType_DISSOLVE_GROUP      MessageType = 21
+	MessageType_SET_GROUP_ROLE      MessageType = 22
+	MessageType_REFRESH_TOKEN       MessageType = 23
+	MessageType_PULL_HISTORY        MessageType = 24
+	MessageType_SYNC                MessageType = 25
+	MessageType_MARK_READ           MessageType = 26
+	MessageType_GET_UNREAD          MessageType = 27
+	MessageType_TYPING_START        MessageType = 28
+	MessageType_TYPING_STOP         MessageType = 29
+	MessageType_RECALL_MESSAGE      MessageType = 30
+	MessageType_EDIT_MESSAGE        MessageType = 31
+	MessageType_CREATE_UPLOAD_URL   MessageType = 32
+	MessageType_CREATE_DOWNLOAD_URL MessageType = 33
+	MessageType_SET_GROUP_NOTIFY    MessageType = 34
 )
 
 // Enum value maps for MessageType.
@@ -61,24 +79,60 @@ var (
 		14: "LIST_GROUP_MEMBER",
 		15: "SINGLE_MESSAGE",
 		16: "GROUP_MESSAGE",
+		17: "DELIVERY_ACK",
+		18: "INVITE_GROUP_MEMBER",
+		19: "KICK_GROUP_MEMBER",
+		20: "RENAME_GROUP",
+		21: "DISSOLVE_GROUP",
+		22: "SET_GROUP_ROLE",
+		23: "REFRESH_TOKEN",
+		24: "PULL_HISTORY",
+		25: "SYNC",
+		26: "MARK_READ",
+		27: "GET_UNREAD",
+		28: "TYPING_START",
+		29: "TYPING_STOP",
+		30: "RECALL_MESSAGE",
+		31: "EDIT_MESSAGE",
+		32: "CREATE_UPLOAD_URL",
+		33: "CREATE_DOWNLOAD_URL",
+		34: "SET_GROUP_NOTIFY",
 	}
 	MessageType_value = map[string]int32{
-		"UNSPECIFIED":       0,
-		"ECHO":              1,
-		"REGISTER":          2,
-		"LOGIN":             3,
-		"LOGOUT":            4,
-		"APPLY_FOR_FRIEND":  5,
-		"ACCEPT_FRIEND":     6,
-		"REJECT_FRIEND":     7,
-		"CREATE_GROUP":      8,
-		"APPLY_FOR_GROUP":   9,
-		"ACCEPT_GROUP":      10,
-		"REJECT_GROUP":      11,
-		"LIST_GROUPS":       13,
-		"LIST_GROUP_MEMBER": 14,
-		"SINGLE_MESSAGE":    15,
-		"GROUP_MESSAGE":     16,
+		"UNSPECIFIED":         0,
+		"ECHO":                1,
+		"REGISTER":            2,
+		"LOGIN":               3,
+		"LOGOUT":              4,
+		"APPLY_FOR_FRIEND":    5,
+		"ACCEPT_FRIEND":       6,
+		"REJECT_FRIEND":       7,
+		"CREATE_GROUP":        8,
+		"APPLY_FOR_GROUP":     9,
+		"ACCEPT_GROUP":        10,
+		"REJECT_GROUP":        11,
+		"LIST_GROUPS":         13,
+		"LIST_GROUP_MEMBER":   14,
+		"SINGLE_MESSAGE":      15,
+		"GROUP_MESSAGE":       16,
+		"DELIVERY_ACK":        17,
+		"INVITE_GROUP_MEMBER": 18,
+		"KICK_GROUP_MEMBER":   19,
+		"RENAME_GROUP":        20,
+		"DISSOLVE_GROUP":      21,
+		"SET_GROUP_ROLE":      22,
+		"REFRESH_TOKEN":       23,
+		"PULL_HISTORY":        24,
+		"SYNC":                25,
+		"MARK_READ":           26,
+		"GET_UNREAD":          27,
+		"TYPING_START":        28,
+		"TYPING_STOP":         29,
+		"RECALL_MESSAGE":      30,
+		"EDIT_MESSAGE":        31,
+		"CREATE_UPLOAD_URL":   32,
+		"CREATE_DOWNLOAD_URL": 33,
+		"SET_GROUP_NOTIFY":    34,
 	}
 )
 
@@ -158,6 +212,328 @@ func (LoginType) EnumDescriptor() ([]byte, []int) {
 	return file_im_v1_im_proto_rawDescGZIP(), []int{1}
 }
 
+type GroupRole int32
+
+const (
+	GroupRole_GROUP_ROLE_UNSPECIFIED GroupRole = 0
+	GroupRole_GROUP_ROLE_MEMBER      GroupRole = 1
+	GroupRole_GROUP_ROLE_ADMIN       GroupRole = 2
+	GroupRole_GROUP_ROLE_OWNER       GroupRole = 3
+)
+
+// Enum value maps for GroupRole.
+var (
+	GroupRole_name = map[int32]string{
+		0: "GROUP_ROLE_UNSPECIFIED",
+		1: "GROUP_ROLE_MEMBER",
+		2: "GROUP_ROLE_ADMIN",
+		3: "GROUP_ROLE_OWNER",
+	}
+	GroupRole_value = map[string]int32{
+		"GROUP_ROLE_UNSPECIFIED": 0,
+		"GROUP_ROLE_MEMBER":      1,
+		"GROUP_ROLE_ADMIN":       2,
+		"GROUP_ROLE_OWNER":       3,
+	}
+)
+
+func (x GroupRole) Enum() *GroupRole {
+	p := new(GroupRole)
+	*p = x
+	return p
+}
+
+func (x GroupRole) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupRole) Descriptor() protoreflect.EnumDescriptor {
+	return file_im_v1_im_proto_enumTypes[2].Descriptor()
+}
+
+func (GroupRole) Type() protoreflect.EnumType {
+	return &file_im_v1_im_proto_enumTypes[2]
+}
+
+func (x GroupRole) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupRole.Descriptor instead.
+func (GroupRole) EnumDescriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{2}
+}
+
+type GroupNotify int32
+
+const (
+	GroupNotify_GROUP_NOTIFY_ALL GroupNotify = 0
+	// Only alert about messages that mention the member.
+	GroupNotify_GROUP_NOTIFY_MENTIONS GroupNotify = 1
+	// Mute the group.
+	GroupNotify_GROUP_NOTIFY_NONE GroupNotify = 2
+)
+
+// Enum value maps for GroupNotify.
+var (
+	GroupNotify_name = map[int32]string{
+		0: "GROUP_NOTIFY_ALL",
+		1: "GROUP_NOTIFY_MENTIONS",
+		2: "GROUP_NOTIFY_NONE",
+	}
+	GroupNotify_value = map[string]int32{
+		"GROUP_NOTIFY_ALL":      0,
+		"GROUP_NOTIFY_MENTIONS": 1,
+		"GROUP_NOTIFY_NONE":     2,
+	}
+)
+
+func (x GroupNotify) Enum() *GroupNotify {
+	p := new(GroupNotify)
+	*p = x
+	return p
+}
+
+func (x GroupNotify) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupNotify) Descriptor() protoreflect.EnumDescriptor {
+	return file_im_v1_im_proto_enumTypes[3].Descriptor()
+}
+
+func (GroupNotify) Type() protoreflect.EnumType {
+	return &file_im_v1_im_proto_enumTypes[3]
+}
+
+func (x GroupNotify) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupNotify.Descriptor instead.
+func (GroupNotify) EnumDescriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{3}
+}
+
+type FriendEvent_Kind int32
+
+const (
+	FriendEvent_KIND_UNSPECIFIED FriendEvent_Kind = 0
+	FriendEvent_APPLIED          FriendEvent_Kind = 1
+	FriendEvent_ACCEPTED         FriendEvent_Kind = 2
+	FriendEvent_REJECTED         FriendEvent_Kind = 3
+)
+
+// Enum value maps for FriendEvent_Kind.
+var (
+	FriendEvent_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "APPLIED",
+		2: "ACCEPTED",
+		3: "REJECTED",
+	}
+	FriendEvent_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"APPLIED":          1,
+		"ACCEPTED":         2,
+		"REJECTED":         3,
+	}
+)
+
+func (x FriendEvent_Kind) Enum() *FriendEvent_Kind {
+	p := new(FriendEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x FriendEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FriendEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_im_v1_im_proto_enumTypes[4].Descriptor()
+}
+
+func (FriendEvent_Kind) Type() protoreflect.EnumType {
+	return &file_im_v1_im_proto_enumTypes[4]
+}
+
+func (x FriendEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FriendEvent_Kind.Descriptor instead.
+func (FriendEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{13, 0}
+}
+
+type GroupEvent_Kind int32
+
+const (
+	GroupEvent_KIND_UNSPECIFIED GroupEvent_Kind = 0
+	GroupEvent_JOIN_APPLIED     GroupEvent_Kind = 1
+	GroupEvent_JOIN_ACCEPTED    GroupEvent_Kind = 2
+	GroupEvent_JOIN_REJECTED    GroupEvent_Kind = 3
+	GroupEvent_INVITED          GroupEvent_Kind = 4
+	GroupEvent_KICKED           GroupEvent_Kind = 5
+	GroupEvent_RENAMED          GroupEvent_Kind = 6
+	GroupEvent_DISSOLVED        GroupEvent_Kind = 7
+	GroupEvent_ROLE_CHANGED     GroupEvent_Kind = 8
+)
+
+// Enum value maps for GroupEvent_Kind.
+var (
+	GroupEvent_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "JOIN_APPLIED",
+		2: "JOIN_ACCEPTED",
+		3: "JOIN_REJECTED",
+		4: "INVITED",
+		5: "KICKED",
+		6: "RENAMED",
+		7: "DISSOLVED",
+		8: "ROLE_CHANGED",
+	}
+	GroupEvent_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"JOIN_APPLIED":     1,
+		"JOIN_ACCEPTED":    2,
+		"JOIN_REJECTED":    3,
+		"INVITED":          4,
+		"KICKED":           5,
+		"RENAMED":          6,
+		"DISSOLVED":        7,
+		"ROLE_CHANGED":     8,
+	}
+)
+
+func (x GroupEvent_Kind) Enum() *GroupEvent_Kind {
+	p := new(GroupEvent_Kind)
+	*p = x
+	return p
+}
+
+func (x GroupEvent_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (GroupEvent_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_im_v1_im_proto_enumTypes[5].Descriptor()
+}
+
+func (GroupEvent_Kind) Type() protoreflect.EnumType {
+	return &file_im_v1_im_proto_enumTypes[5]
+}
+
+func (x GroupEvent_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use GroupEvent_Kind.Descriptor instead.
+func (GroupEvent_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{22, 0}
+}
+
+type Kicked_Reason int32
+
+const (
+	Kicked_REASON_UNSPECIFIED Kicked_Reason = 0
+	// The same device connected again.
+	Kicked_SAME_DEVICE Kicked_Reason = 1
+	// Another device connected and the user's device policy has no room
+	// left for this one.
+	Kicked_OTHER_DEVICE Kicked_Reason = 2
+)
+
+// Enum value maps for Kicked_Reason.
+var (
+	Kicked_Reason_name = map[int32]string{
+		0: "REASON_UNSPECIFIED",
+		1: "SAME_DEVICE",
+		2: "OTHER_DEVICE",
+	}
+	Kicked_Reason_value = map[string]int32{
+		"REASON_UNSPECIFIED": 0,
+		"SAME_DEVICE":        1,
+		"OTHER_DEVICE":       2,
+	}
+)
+
+func (x Kicked_Reason) Enum() *Kicked_Reason {
+	p := new(Kicked_Reason)
+	*p = x
+	return p
+}
+
+func (x Kicked_Reason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Kicked_Reason) Descriptor() protoreflect.EnumDescriptor {
+	return file_im_v1_im_proto_enumTypes[6].Descriptor()
+}
+
+func (Kicked_Reason) Type() protoreflect.EnumType {
+	return &file_im_v1_im_proto_enumTypes[6]
+}
+
+func (x Kicked_Reason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Kicked_Reason.Descriptor instead.
+func (Kicked_Reason) EnumDescriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{45, 0}
+}
+
+type PullHistory_Direction int32
+
+const (
+	// Older than the cursor, newest first.
+	PullHistory_BACKWARD PullHistory_Direction = 0
+	// Newer than the cursor, oldest first.
+	PullHistory_FORWARD PullHistory_Direction = 1
+)
+
+// Enum value maps for PullHistory_Direction.
+var (
+	PullHistory_Direction_name = map[int32]string{
+		0: "BACKWARD",
+		1: "FORWARD",
+	}
+	PullHistory_Direction_value = map[string]int32{
+		"BACKWARD": 0,
+		"FORWARD":  1,
+	}
+)
+
+func (x PullHistory_Direction) Enum() *PullHistory_Direction {
+	p := new(PullHistory_Direction)
+	*p = x
+	return p
+}
+
+func (x PullHistory_Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PullHistory_Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_im_v1_im_proto_enumTypes[7].Descriptor()
+}
+
+func (PullHistory_Direction) Type() protoreflect.EnumType {
+	return &file_im_v1_im_proto_enumTypes[7]
+}
+
+func (x PullHistory_Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PullHistory_Direction.Descriptor instead.
+func (PullHistory_Direction) EnumDescriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{46, 0}
+}
+
 type ClientEnvelope struct {
 	state   protoimpl.MessageState `protogen:"open.v1"`
 	TraceId string                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
@@ -179,6 +555,23 @@ type ClientEnvelope struct {
 	//	*ClientEnvelope_ListGroupMemeber
 	//	*ClientEnvelope_SingleMessage
 	//	*ClientEnvelope_GroupMessage
+	//	*ClientEnvelope_DeliveryAck
+	//	*ClientEnvelope_InviteGroupMember
+	//	*ClientEnvelope_KickGroupMember
+	//	*ClientEnvelope_RenameGroup
+	//	*ClientEnvelope_DissolveGroup
+	//	*ClientEnvelope_SetGroupRole
+	//	*ClientEnvelope_RefreshToken
+	//	*ClientEnvelope_PullHistory
+	//	*ClientEnvelope_Sync
+	//	*ClientEnvelope_MarkRead
+	//	*ClientEnvelope_GetUnread
+	//	*ClientEnvelope_Typing
+	//	*ClientEnvelope_RecallMessage
+	//	*ClientEnvelope_EditMessage
+	//	*ClientEnvelope_CreateUploadUrl
+	//	*ClientEnvelope_CreateDownloadUrl
+	//	*ClientEnvelope_SetGroupNotify
 	Payload       isClientEnvelope_Payload `protobuf_oneof:"payload"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -370,6 +763,159 @@ func (x *ClientEnvelope) GetGroupMessage() *GroupMessage {
 	return nil
 }
 
+func (x *ClientEnvelope) GetDeliveryAck() *DeliveryAck {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_DeliveryAck); ok {
+			return x.DeliveryAck
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetInviteGroupMember() *InviteGroupMember {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_InviteGroupMember); ok {
+			return x.InviteGroupMember
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetKickGroupMember() *KickGroupMember {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_KickGroupMember); ok {
+			return x.KickGroupMember
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetRenameGroup() *RenameGroup {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_RenameGroup); ok {
+			return x.RenameGroup
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetDissolveGroup() *DissolveGroup {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_DissolveGroup); ok {
+			return x.DissolveGroup
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetSetGroupRole() *SetGroupRole {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_SetGroupRole); ok {
+			return x.SetGroupRole
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetRefreshToken() *RefreshToken {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_RefreshToken); ok {
+			return x.RefreshToken
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetPullHistory() *PullHistory {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_PullHistory); ok {
+			return x.PullHistory
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetSync() *Sync {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_Sync); ok {
+			return x.Sync
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetMarkRead() *MarkRead {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_MarkRead); ok {
+			return x.MarkRead
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetGetUnread() *GetUnread {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_GetUnread); ok {
+			return x.GetUnread
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetTyping() *Typing {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_Typing); ok {
+			return x.Typing
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetRecallMessage() *RecallMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_RecallMessage); ok {
+			return x.RecallMessage
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetEditMessage() *EditMessage {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_EditMessage); ok {
+			return x.EditMessage
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetCreateUploadUrl() *CreateUploadUrl {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_CreateUploadUrl); ok {
+			return x.CreateUploadUrl
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetCreateDownloadUrl() *CreateDownloadUrl {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_CreateDownloadUrl); ok {
+			return x.CreateDownloadUrl
+		}
+	}
+	return nil
+}
+
+func (x *ClientEnvelope) GetSetGroupNotify() *SetGroupNotify {
+	if x != nil {
+		if x, ok := x.Payload.(*ClientEnvelope_SetGroupNotify); ok {
+			return x.SetGroupNotify
+		}
+	}
+	return nil
+}
+
 type isClientEnvelope_Payload interface {
 	isClientEnvelope_Payload()
 }
@@ -434,7 +980,75 @@ type ClientEnvelope_GroupMessage struct {
 	GroupMessage *GroupMessage `protobuf:"bytes,24,opt,name=group_message,json=groupMessage,proto3,oneof"`
 }
 
-func (*ClientEnvelope_Echo) isClientEnvelope_Payload() {}
+type ClientEnvelope_DeliveryAck struct {
+	DeliveryAck *DeliveryAck `protobuf:"bytes,25,opt,name=delivery_ack,json=deliveryAck,proto3,oneof"`
+}
+
+type ClientEnvelope_InviteGroupMember struct {
+	InviteGroupMember *InviteGroupMember `protobuf:"bytes,26,opt,name=invite_group_member,json=inviteGroupMember,proto3,oneof"`
+}
+
+type ClientEnvelope_KickGroupMember struct {
+	KickGroupMember *KickGroupMember `protobuf:"bytes,27,opt,name=kick_group_member,json=kickGroupMember,proto3,oneof"`
+}
+
+type ClientEnvelope_RenameGroup struct {
+	RenameGroup *RenameGroup `protobuf:"bytes,28,opt,name=rename_group,json=renameGroup,proto3,oneof"`
+}
+
+type ClientEnvelope_DissolveGroup struct {
+	DissolveGroup *DissolveGroup `protobuf:"bytes,29,opt,name=dissolve_group,json=dissolveGroup,proto3,oneof"`
+}
+
+type ClientEnvelope_SetGroupRole struct {
+	SetGroupRole *SetGroupRole `protobuf:"bytes,30,opt,name=set_group_role,json=setGroupRole,proto3,oneof"`
+}
+
+type ClientEnvelope_RefreshToken struct {
+	RefreshToken *RefreshToken `protobuf:"bytes,31,opt,name=refresh_token,json=refreshToken,proto3,oneof"`
+}
+
+type ClientEnvelope_PullHistory struct {
+	PullHistory *PullHistory `protobuf:"bytes,32,opt,name=pull_history,json=pullHistory,proto3,oneof"`
+}
+
+type ClientEnvelope_Sync struct {
+	Sync *Sync `protobuf:"bytes,33,opt,name=sync,proto3,oneof"`
+}
+
+type ClientEnvelope_MarkRead struct {
+	MarkRead *MarkRead `protobuf:"bytes,34,opt,name=mark_read,json=markRead,proto3,oneof"`
+}
+
+type ClientEnvelope_GetUnread struct {
+	GetUnread *GetUnread `protobuf:"bytes,35,opt,name=get_unread,json=getUnread,proto3,oneof"`
+}
+
+type ClientEnvelope_Typing struct {
+	Typing *Typing `protobuf:"bytes,36,opt,name=typing,proto3,oneof"`
+}
+
+type ClientEnvelope_RecallMessage struct {
+	RecallMessage *RecallMessage `protobuf:"bytes,37,opt,name=recall_message,json=recallMessage,proto3,oneof"`
+}
+
+type ClientEnvelope_EditMessage struct {
+	EditMessage *EditMessage `protobuf:"bytes,38,opt,name=edit_message,json=editMessage,proto3,oneof"`
+}
+
+type ClientEnvelope_CreateUploadUrl struct {
+	CreateUploadUrl *CreateUploadUrl `protobuf:"bytes,39,opt,name=create_upload_url,json=createUploadUrl,proto3,oneof"`
+}
+
+type ClientEnvelope_CreateDownloadUrl struct {
+	CreateDownloadUrl *CreateDownloadUrl `protobuf:"bytes,40,opt,name=create_download_url,json=createDownloadUrl,proto3,oneof"`
+}
+
+type ClientEnvelope_SetGroupNotify struct {
+	SetGroupNotify *SetGroupNotify `protobuf:"bytes,41,opt,name=set_group_notify,json=setGroupNotify,proto3,oneof"`
+}
+
+func (*ClientEnvelope_Echo) isClientEnvelope_Payload() {}
 
 func (*ClientEnvelope_Register) isClientEnvelope_Payload() {}
 
@@ -464,6 +1078,40 @@ func (*ClientEnvelope_SingleMessage) isClientEnvelope_Payload() {}
 
 func (*ClientEnvelope_GroupMessage) isClientEnvelope_Payload() {}
 
+func (*ClientEnvelope_DeliveryAck) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_InviteGroupMember) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_KickGroupMember) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_RenameGroup) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_DissolveGroup) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_SetGroupRole) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_RefreshToken) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_PullHistory) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_Sync) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_MarkRead) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_GetUnread) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_Typing) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_RecallMessage) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_EditMessage) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_CreateUploadUrl) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_CreateDownloadUrl) isClientEnvelope_Payload() {}
+
+func (*ClientEnvelope_SetGroupNotify) isClientEnvelope_Payload() {}
+
 type ServerEnvelope struct {
 	state   protoimpl.MessageState `protogen:"open.v1"`
 	TraceId string                 `protobuf:"bytes,1,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
@@ -471,8 +1119,19 @@ type ServerEnvelope struct {
 	//
 	//	*ServerEnvelope_Echo
 	//	*ServerEnvelope_AckResp
+	//	*ServerEnvelope_LoginResp
+	//	*ServerEnvelope_Kicked
+	//	*ServerEnvelope_HistoryResp
+	//	*ServerEnvelope_SyncResp
+	//	*ServerEnvelope_ReadReceipt
+	//	*ServerEnvelope_UnreadResp
+	//	*ServerEnvelope_TypingEvent
+	//	*ServerEnvelope_PresenceEvent
+	//	*ServerEnvelope_BlobUrl
 	//	*ServerEnvelope_ListGroupsResp
 	//	*ServerEnvelope_ListGroupMemeberResp
+	//	*ServerEnvelope_FriendEvent
+	//	*ServerEnvelope_GroupEvent
 	//	*ServerEnvelope_DeliverSingleMessage
 	//	*ServerEnvelope_DeliverGroupMessage
 	//	*ServerEnvelope_Error
@@ -543,6 +1202,87 @@ func (x *ServerEnvelope) GetAckResp() *AckResp {
 	return nil
 }
 
+func (x *ServerEnvelope) GetLoginResp() *LoginResp {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_LoginResp); ok {
+			return x.LoginResp
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetKicked() *Kicked {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_Kicked); ok {
+			return x.Kicked
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetHistoryResp() *HistoryResp {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_HistoryResp); ok {
+			return x.HistoryResp
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetSyncResp() *SyncResp {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_SyncResp); ok {
+			return x.SyncResp
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetReadReceipt() *ReadReceipt {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_ReadReceipt); ok {
+			return x.ReadReceipt
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetUnreadResp() *UnreadResp {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_UnreadResp); ok {
+			return x.UnreadResp
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetTypingEvent() *TypingEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_TypingEvent); ok {
+			return x.TypingEvent
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetPresenceEvent() *PresenceEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_PresenceEvent); ok {
+			return x.PresenceEvent
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetBlobUrl() *BlobUrl {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_BlobUrl); ok {
+			return x.BlobUrl
+		}
+	}
+	return nil
+}
+
 func (x *ServerEnvelope) GetListGroupsResp() *ListGroupsResp {
 	if x != nil {
 		if x, ok := x.Payload.(*ServerEnvelope_ListGroupsResp); ok {
@@ -561,6 +1301,24 @@ func (x *ServerEnvelope) GetListGroupMemeberResp() *ListGroupMemberResp {
 	return nil
 }
 
+func (x *ServerEnvelope) GetFriendEvent() *FriendEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_FriendEvent); ok {
+			return x.FriendEvent
+		}
+	}
+	return nil
+}
+
+func (x *ServerEnvelope) GetGroupEvent() *GroupEvent {
+	if x != nil {
+		if x, ok := x.Payload.(*ServerEnvelope_GroupEvent); ok {
+			return x.GroupEvent
+		}
+	}
+	return nil
+}
+
 func (x *ServerEnvelope) GetDeliverSingleMessage() *DeliverSingleMessage {
 	if x != nil {
 		if x, ok := x.Payload.(*ServerEnvelope_DeliverSingleMessage); ok {
@@ -600,6 +1358,42 @@ type ServerEnvelope_AckResp struct {
 	AckResp *AckResp `protobuf:"bytes,11,opt,name=ack_resp,json=ackResp,proto3,oneof"`
 }
 
+type ServerEnvelope_LoginResp struct {
+	LoginResp *LoginResp `protobuf:"bytes,12,opt,name=login_resp,json=loginResp,proto3,oneof"`
+}
+
+type ServerEnvelope_Kicked struct {
+	Kicked *Kicked `protobuf:"bytes,13,opt,name=kicked,proto3,oneof"`
+}
+
+type ServerEnvelope_HistoryResp struct {
+	HistoryResp *HistoryResp `protobuf:"bytes,14,opt,name=history_resp,json=historyResp,proto3,oneof"`
+}
+
+type ServerEnvelope_SyncResp struct {
+	SyncResp *SyncResp `protobuf:"bytes,19,opt,name=sync_resp,json=syncResp,proto3,oneof"`
+}
+
+type ServerEnvelope_ReadReceipt struct {
+	ReadReceipt *ReadReceipt `protobuf:"bytes,22,opt,name=read_receipt,json=readReceipt,proto3,oneof"`
+}
+
+type ServerEnvelope_UnreadResp struct {
+	UnreadResp *UnreadResp `protobuf:"bytes,23,opt,name=unread_resp,json=unreadResp,proto3,oneof"`
+}
+
+type ServerEnvelope_TypingEvent struct {
+	TypingEvent *TypingEvent `protobuf:"bytes,24,opt,name=typing_event,json=typingEvent,proto3,oneof"`
+}
+
+type ServerEnvelope_PresenceEvent struct {
+	PresenceEvent *PresenceEvent `protobuf:"bytes,25,opt,name=presence_event,json=presenceEvent,proto3,oneof"`
+}
+
+type ServerEnvelope_BlobUrl struct {
+	BlobUrl *BlobUrl `protobuf:"bytes,26,opt,name=blob_url,json=blobUrl,proto3,oneof"`
+}
+
 type ServerEnvelope_ListGroupsResp struct {
 	ListGroupsResp *ListGroupsResp `protobuf:"bytes,15,opt,name=list_groups_resp,json=listGroupsResp,proto3,oneof"`
 }
@@ -608,6 +1402,14 @@ type ServerEnvelope_ListGroupMemeberResp struct {
 	ListGroupMemeberResp *ListGroupMemberResp `protobuf:"bytes,16,opt,name=list_group_memeber_resp,json=listGroupMemeberResp,proto3,oneof"`
 }
 
+type ServerEnvelope_FriendEvent struct {
+	FriendEvent *FriendEvent `protobuf:"bytes,17,opt,name=friend_event,json=friendEvent,proto3,oneof"`
+}
+
+type ServerEnvelope_GroupEvent struct {
+	GroupEvent *GroupEvent `protobuf:"bytes,18,opt,name=group_event,json=groupEvent,proto3,oneof"`
+}
+
 type ServerEnvelope_DeliverSingleMessage struct {
 	DeliverSingleMessage *DeliverSingleMessage `protobuf:"bytes,20,opt,name=deliver_single_message,json=deliverSingleMessage,proto3,oneof"`
 }
@@ -624,10 +1426,32 @@ func (*ServerEnvelope_Echo) isServerEnvelope_Payload() {}
 
 func (*ServerEnvelope_AckResp) isServerEnvelope_Payload() {}
 
+func (*ServerEnvelope_LoginResp) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_Kicked) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_HistoryResp) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_SyncResp) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_ReadReceipt) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_UnreadResp) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_TypingEvent) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_PresenceEvent) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_BlobUrl) isServerEnvelope_Payload() {}
+
 func (*ServerEnvelope_ListGroupsResp) isServerEnvelope_Payload() {}
 
 func (*ServerEnvelope_ListGroupMemeberResp) isServerEnvelope_Payload() {}
 
+func (*ServerEnvelope_FriendEvent) isServerEnvelope_Payload() {}
+
+func (*ServerEnvelope_GroupEvent) isServerEnvelope_Payload() {}
+
 func (*ServerEnvelope_DeliverSingleMessage) isServerEnvelope_Payload() {}
 
 func (*ServerEnvelope_DeliverGroupMessage) isServerEnvelope_Payload() {}
@@ -755,9 +1579,14 @@ func (x *Register) GetPassword() string {
 }
 
 type AckResp struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Status  int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// message_id is the server ID of an accepted message send.
+	MessageId int64 `protobuf:"varint,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// duplicate is set when a send repeated the client_msg_id of an earlier
+	// one; message_id is then the ID of that message, which is not sent again.
+	Duplicate     bool `protobuf:"varint,4,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -806,11 +1635,31 @@ func (x *AckResp) GetMessage() string {
 	return ""
 }
 
+func (x *AckResp) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *AckResp) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
 type Login struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          LoginType              `protobuf:"varint,1,opt,name=type,proto3,enum=im.v1.LoginType" json:"type,omitempty"`
-	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
-	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Type     LoginType              `protobuf:"varint,1,opt,name=type,proto3,enum=im.v1.LoginType" json:"type,omitempty"`
+	Field    string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`
+	Password string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	// Device the issued token is bound to; defaults to "default".
+	DeviceId string `protobuf:"bytes,4,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	// Platform of the device: "ios", "android", "web", "desktop" or empty.
+	// The token is bound to it, and the per-platform device policy keys off
+	// it.
+	Platform      string `protobuf:"bytes,5,opt,name=platform,proto3" json:"platform,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -845,49 +1694,2747 @@ func (*Login) Descriptor() ([]byte, []int) {
 	return file_im_v1_im_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *Login) GetType() LoginType {
+func (x *Login) GetType() LoginType {
+	if x != nil {
+		return x.Type
+	}
+	return LoginType_UNKNOWN
+}
+
+func (x *Login) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *Login) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Login) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Login) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+// Exchanges the token of the current connection for a new one before it
+// expires. The old token is revoked; the connection stays open.
+type RefreshToken struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshToken) Reset() {
+	*x = RefreshToken{}
+	mi := &file_im_v1_im_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshToken) ProtoMessage() {}
+
+func (x *RefreshToken) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshToken.ProtoReflect.Descriptor instead.
+func (*RefreshToken) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{6}
+}
+
+// Answer to Login and RefreshToken. The token is presented as "Authorization: Bearer <token>"
+// when connecting.
+type LoginResp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Uuid  string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Token string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// Unix seconds.
+	ExpiresAt     int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginResp) Reset() {
+	*x = LoginResp{}
+	mi := &file_im_v1_im_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResp) ProtoMessage() {}
+
+func (x *LoginResp) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResp.ProtoReflect.Descriptor instead.
+func (*LoginResp) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *LoginResp) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *LoginResp) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResp) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type Logout struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Logout) Reset() {
+	*x = Logout{}
+	mi := &file_im_v1_im_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Logout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Logout) ProtoMessage() {}
+
+func (x *Logout) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Logout.ProtoReflect.Descriptor instead.
+func (*Logout) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Logout) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+type ApplyForFriend struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Uuid          string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyForFriend) Reset() {
+	*x = ApplyForFriend{}
+	mi := &file_im_v1_im_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyForFriend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyForFriend) ProtoMessage() {}
+
+func (x *ApplyForFriend) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyForFriend.ProtoReflect.Descriptor instead.
+func (*ApplyForFriend) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ApplyForFriend) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ApplyForFriend) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+type AcceptFriend struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptFriend) Reset() {
+	*x = AcceptFriend{}
+	mi := &file_im_v1_im_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptFriend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptFriend) ProtoMessage() {}
+
+func (x *AcceptFriend) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptFriend.ProtoReflect.Descriptor instead.
+func (*AcceptFriend) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AcceptFriend) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type RejectFriend struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectFriend) Reset() {
+	*x = RejectFriend{}
+	mi := &file_im_v1_im_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectFriend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectFriend) ProtoMessage() {}
+
+func (x *RejectFriend) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectFriend.ProtoReflect.Descriptor instead.
+func (*RejectFriend) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RejectFriend) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type CreateGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateGroup) Reset() {
+	*x = CreateGroup{}
+	mi := &file_im_v1_im_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateGroup) ProtoMessage() {}
+
+func (x *CreateGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateGroup.ProtoReflect.Descriptor instead.
+func (*CreateGroup) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateGroup) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *CreateGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Friend request event: server -> client. APPLIED goes to the addressee,
+// ACCEPTED and REJECTED go back to the applicant.
+type FriendEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          FriendEvent_Kind       `protobuf:"varint,1,opt,name=kind,proto3,enum=im.v1.FriendEvent_Kind" json:"kind,omitempty"`
+	RequestId     string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	From          string                 `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FriendEvent) Reset() {
+	*x = FriendEvent{}
+	mi := &file_im_v1_im_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FriendEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FriendEvent) ProtoMessage() {}
+
+func (x *FriendEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FriendEvent.ProtoReflect.Descriptor instead.
+func (*FriendEvent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FriendEvent) GetKind() FriendEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return FriendEvent_KIND_UNSPECIFIED
+}
+
+func (x *FriendEvent) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *FriendEvent) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *FriendEvent) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type ApplyForGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Uuid          string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyForGroup) Reset() {
+	*x = ApplyForGroup{}
+	mi := &file_im_v1_im_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyForGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyForGroup) ProtoMessage() {}
+
+func (x *ApplyForGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyForGroup.ProtoReflect.Descriptor instead.
+func (*ApplyForGroup) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ApplyForGroup) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ApplyForGroup) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+type AcceptGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcceptGroup) Reset() {
+	*x = AcceptGroup{}
+	mi := &file_im_v1_im_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcceptGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceptGroup) ProtoMessage() {}
+
+func (x *AcceptGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceptGroup.ProtoReflect.Descriptor instead.
+func (*AcceptGroup) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AcceptGroup) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type RejectGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectGroup) Reset() {
+	*x = RejectGroup{}
+	mi := &file_im_v1_im_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectGroup) ProtoMessage() {}
+
+func (x *RejectGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectGroup.ProtoReflect.Descriptor instead.
+func (*RejectGroup) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RejectGroup) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+// Invites user to the group. They join once they answer request_id with
+// ACCEPT_GROUP, or decline it with REJECT_GROUP.
+type InviteGroupMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	RequestId     string                 `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InviteGroupMember) Reset() {
+	*x = InviteGroupMember{}
+	mi := &file_im_v1_im_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InviteGroupMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InviteGroupMember) ProtoMessage() {}
+
+func (x *InviteGroupMember) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InviteGroupMember.ProtoReflect.Descriptor instead.
+func (*InviteGroupMember) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *InviteGroupMember) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *InviteGroupMember) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *InviteGroupMember) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+type KickGroupMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KickGroupMember) Reset() {
+	*x = KickGroupMember{}
+	mi := &file_im_v1_im_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KickGroupMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KickGroupMember) ProtoMessage() {}
+
+func (x *KickGroupMember) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KickGroupMember.ProtoReflect.Descriptor instead.
+func (*KickGroupMember) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *KickGroupMember) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *KickGroupMember) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+type RenameGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenameGroup) Reset() {
+	*x = RenameGroup{}
+	mi := &file_im_v1_im_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenameGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenameGroup) ProtoMessage() {}
+
+func (x *RenameGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenameGroup.ProtoReflect.Descriptor instead.
+func (*RenameGroup) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RenameGroup) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *RenameGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DissolveGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DissolveGroup) Reset() {
+	*x = DissolveGroup{}
+	mi := &file_im_v1_im_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DissolveGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DissolveGroup) ProtoMessage() {}
+
+func (x *DissolveGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DissolveGroup.ProtoReflect.Descriptor instead.
+func (*DissolveGroup) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DissolveGroup) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+// Only MEMBER and ADMIN may be assigned.
+type SetGroupRole struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	Role          GroupRole              `protobuf:"varint,3,opt,name=role,proto3,enum=im.v1.GroupRole" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetGroupRole) Reset() {
+	*x = SetGroupRole{}
+	mi := &file_im_v1_im_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetGroupRole) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetGroupRole) ProtoMessage() {}
+
+func (x *SetGroupRole) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetGroupRole.ProtoReflect.Descriptor instead.
+func (*SetGroupRole) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SetGroupRole) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *SetGroupRole) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *SetGroupRole) GetRole() GroupRole {
+	if x != nil {
+		return x.Role
+	}
+	return GroupRole_GROUP_ROLE_UNSPECIFIED
+}
+
+// Group event: server -> client. JOIN_APPLIED goes to the group owner,
+// JOIN_ACCEPTED and JOIN_REJECTED go back to the applicant, or to the
+// inviter when an invitation is answered. INVITED, KICKED and ROLE_CHANGED
+// go to the affected user; RENAMED and DISSOLVED go to every member.
+type GroupEvent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Kind      GroupEvent_Kind        `protobuf:"varint,1,opt,name=kind,proto3,enum=im.v1.GroupEvent_Kind" json:"kind,omitempty"`
+	RequestId string                 `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	GroupUuid string                 `protobuf:"bytes,3,opt,name=group_uuid,json=groupUuid,proto3" json:"group_uuid,omitempty"`
+	// The applicant or the affected member.
+	User string `protobuf:"bytes,4,opt,name=user,proto3" json:"user,omitempty"`
+	// The member who performed the operation, if any.
+	Operator string `protobuf:"bytes,5,opt,name=operator,proto3" json:"operator,omitempty"`
+	// Set on RENAMED.
+	Name string `protobuf:"bytes,6,opt,name=name,proto3" json:"name,omitempty"`
+	// Set on ROLE_CHANGED.
+	Role          GroupRole `protobuf:"varint,7,opt,name=role,proto3,enum=im.v1.GroupRole" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupEvent) Reset() {
+	*x = GroupEvent{}
+	mi := &file_im_v1_im_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupEvent) ProtoMessage() {}
+
+func (x *GroupEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupEvent.ProtoReflect.Descriptor instead.
+func (*GroupEvent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GroupEvent) GetKind() GroupEvent_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return GroupEvent_KIND_UNSPECIFIED
+}
+
+func (x *GroupEvent) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *GroupEvent) GetGroupUuid() string {
+	if x != nil {
+		return x.GroupUuid
+	}
+	return ""
+}
+
+func (x *GroupEvent) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *GroupEvent) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+func (x *GroupEvent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GroupEvent) GetRole() GroupRole {
+	if x != nil {
+		return x.Role
+	}
+	return GroupRole_GROUP_ROLE_UNSPECIFIED
+}
+
+type ListGroups struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroups) Reset() {
+	*x = ListGroups{}
+	mi := &file_im_v1_im_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroups) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroups) ProtoMessage() {}
+
+func (x *ListGroups) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroups.ProtoReflect.Descriptor instead.
+func (*ListGroups) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListGroups) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *ListGroups) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListGroups) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type GroupInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Owner         string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupInfo) Reset() {
+	*x = GroupInfo{}
+	mi := &file_im_v1_im_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupInfo) ProtoMessage() {}
+
+func (x *GroupInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupInfo.ProtoReflect.Descriptor instead.
+func (*GroupInfo) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GroupInfo) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *GroupInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GroupInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type ListGroupsResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	GroupInfo     []*GroupInfo           `protobuf:"bytes,3,rep,name=group_info,json=groupInfo,proto3" json:"group_info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupsResp) Reset() {
+	*x = ListGroupsResp{}
+	mi := &file_im_v1_im_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupsResp) ProtoMessage() {}
+
+func (x *ListGroupsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupsResp.ProtoReflect.Descriptor instead.
+func (*ListGroupsResp) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListGroupsResp) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *ListGroupsResp) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListGroupsResp) GetGroupInfo() []*GroupInfo {
+	if x != nil {
+		return x.GroupInfo
+	}
+	return nil
+}
+
+type ListGroupMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupMember) Reset() {
+	*x = ListGroupMember{}
+	mi := &file_im_v1_im_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupMember) ProtoMessage() {}
+
+func (x *ListGroupMember) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupMember.ProtoReflect.Descriptor instead.
+func (*ListGroupMember) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListGroupMember) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *ListGroupMember) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ListGroupMember) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type GroupMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Role          GroupRole              `protobuf:"varint,3,opt,name=role,proto3,enum=im.v1.GroupRole" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupMember) Reset() {
+	*x = GroupMember{}
+	mi := &file_im_v1_im_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupMember) ProtoMessage() {}
+
+func (x *GroupMember) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupMember.ProtoReflect.Descriptor instead.
+func (*GroupMember) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GroupMember) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *GroupMember) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GroupMember) GetRole() GroupRole {
+	if x != nil {
+		return x.Role
+	}
+	return GroupRole_GROUP_ROLE_UNSPECIFIED
+}
+
+type ListGroupMemberResp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	GroupMember   []*GroupMember         `protobuf:"bytes,2,rep,name=group_member,json=groupMember,proto3" json:"group_member,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGroupMemberResp) Reset() {
+	*x = ListGroupMemberResp{}
+	mi := &file_im_v1_im_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGroupMemberResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupMemberResp) ProtoMessage() {}
+
+func (x *ListGroupMemberResp) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupMemberResp.ProtoReflect.Descriptor instead.
+func (*ListGroupMemberResp) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ListGroupMemberResp) GetStatus() int32 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *ListGroupMemberResp) GetGroupMember() []*GroupMember {
+	if x != nil {
+		return x.GroupMember
+	}
+	return nil
+}
+
+// A message carries either client-defined bytes in message or a typed
+// content, never both.
+type SingleMessage struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	To      string                 `protobuf:"bytes,1,opt,name=to,proto3" json:"to,omitempty"`
+	Message []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Content *Content               `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	// client_msg_id is generated by the client, unique per sender, so that a
+	// retried send is recognized. Optional, at most 64 bytes.
+	ClientMsgId   string `protobuf:"bytes,4,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SingleMessage) Reset() {
+	*x = SingleMessage{}
+	mi := &file_im_v1_im_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SingleMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SingleMessage) ProtoMessage() {}
+
+func (x *SingleMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SingleMessage.ProtoReflect.Descriptor instead.
+func (*SingleMessage) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SingleMessage) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *SingleMessage) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *SingleMessage) GetContent() *Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *SingleMessage) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+type GroupMessage struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Uuid    string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Message []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Content *Content               `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	// client_msg_id is as in SingleMessage.
+	ClientMsgId   string `protobuf:"bytes,4,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupMessage) Reset() {
+	*x = GroupMessage{}
+	mi := &file_im_v1_im_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupMessage) ProtoMessage() {}
+
+func (x *GroupMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupMessage.ProtoReflect.Descriptor instead.
+func (*GroupMessage) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GroupMessage) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *GroupMessage) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *GroupMessage) GetContent() *Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *GroupMessage) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+// Content is a typed message body, validated by the server against its
+// per-type size limits.
+type Content struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Body:
+	//
+	//	*Content_Text
+	//	*Content_Image
+	//	*Content_File
+	//	*Content_Audio
+	//	*Content_Location
+	//	*Content_Custom
+	Body isContent_Body `protobuf_oneof:"body"`
+	// Set when the message replies to or quotes an earlier message of the
+	// same conversation.
+	ReplyTo       *ReplyTo `protobuf:"bytes,10,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Content) Reset() {
+	*x = Content{}
+	mi := &file_im_v1_im_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Content) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Content) ProtoMessage() {}
+
+func (x *Content) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Content.ProtoReflect.Descriptor instead.
+func (*Content) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Content) GetBody() isContent_Body {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+func (x *Content) GetText() *TextContent {
+	if x != nil {
+		if x, ok := x.Body.(*Content_Text); ok {
+			return x.Text
+		}
+	}
+	return nil
+}
+
+func (x *Content) GetImage() *ImageContent {
+	if x != nil {
+		if x, ok := x.Body.(*Content_Image); ok {
+			return x.Image
+		}
+	}
+	return nil
+}
+
+func (x *Content) GetFile() *FileContent {
+	if x != nil {
+		if x, ok := x.Body.(*Content_File); ok {
+			return x.File
+		}
+	}
+	return nil
+}
+
+func (x *Content) GetAudio() *AudioContent {
+	if x != nil {
+		if x, ok := x.Body.(*Content_Audio); ok {
+			return x.Audio
+		}
+	}
+	return nil
+}
+
+func (x *Content) GetLocation() *LocationContent {
+	if x != nil {
+		if x, ok := x.Body.(*Content_Location); ok {
+			return x.Location
+		}
+	}
+	return nil
+}
+
+func (x *Content) GetCustom() *CustomContent {
+	if x != nil {
+		if x, ok := x.Body.(*Content_Custom); ok {
+			return x.Custom
+		}
+	}
+	return nil
+}
+
+func (x *Content) GetReplyTo() *ReplyTo {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return nil
+}
+
+type isContent_Body interface {
+	isContent_Body()
+}
+
+type Content_Text struct {
+	Text *TextContent `protobuf:"bytes,1,opt,name=text,proto3,oneof"`
+}
+
+type Content_Image struct {
+	Image *ImageContent `protobuf:"bytes,2,opt,name=image,proto3,oneof"`
+}
+
+type Content_File struct {
+	File *FileContent `protobuf:"bytes,3,opt,name=file,proto3,oneof"`
+}
+
+type Content_Audio struct {
+	Audio *AudioContent `protobuf:"bytes,4,opt,name=audio,proto3,oneof"`
+}
+
+type Content_Location struct {
+	Location *LocationContent `protobuf:"bytes,5,opt,name=location,proto3,oneof"`
+}
+
+type Content_Custom struct {
+	Custom *CustomContent `protobuf:"bytes,6,opt,name=custom,proto3,oneof"`
+}
+
+func (*Content_Text) isContent_Body() {}
+
+func (*Content_Image) isContent_Body() {}
+
+func (*Content_File) isContent_Body() {}
+
+func (*Content_Audio) isContent_Body() {}
+
+func (*Content_Location) isContent_Body() {}
+
+func (*Content_Custom) isContent_Body() {}
+
+type TextContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	Mentions      []*Mention             `protobuf:"bytes,2,rep,name=mentions,proto3" json:"mentions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TextContent) Reset() {
+	*x = TextContent{}
+	mi := &file_im_v1_im_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TextContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TextContent) ProtoMessage() {}
+
+func (x *TextContent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TextContent.ProtoReflect.Descriptor instead.
+func (*TextContent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TextContent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TextContent) GetMentions() []*Mention {
+	if x != nil {
+		return x.Mentions
+	}
+	return nil
+}
+
+// Mention marks the span of text that refers to a user, in bytes of the
+// UTF-8 encoded text.
+type Mention struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	User   string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Offset int32                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Length int32                  `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	// Mentions every member of a group instead of user, which is then empty.
+	// Only group admins and the owner may use it.
+	All           bool `protobuf:"varint,4,opt,name=all,proto3" json:"all,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mention) Reset() {
+	*x = Mention{}
+	mi := &file_im_v1_im_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mention) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mention) ProtoMessage() {}
+
+func (x *Mention) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mention.ProtoReflect.Descriptor instead.
+func (*Mention) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Mention) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Mention) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *Mention) GetLength() int32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Mention) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+// Attachment references an uploaded blob; the bytes never travel in the
+// websocket frame.
+type Attachment struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	BlobId   string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
+	MimeType string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	// Size of the blob in bytes.
+	Size int64 `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	// Original file name, for display.
+	Name          string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_im_v1_im_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *Attachment) GetBlobId() string {
+	if x != nil {
+		return x.BlobId
+	}
+	return ""
+}
+
+func (x *Attachment) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Attachment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ImageContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attachment    *Attachment            `protobuf:"bytes,1,opt,name=attachment,proto3" json:"attachment,omitempty"`
+	Width         int32                  `protobuf:"varint,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,3,opt,name=height,proto3" json:"height,omitempty"`
+	Thumbnail     *Attachment            `protobuf:"bytes,4,opt,name=thumbnail,proto3" json:"thumbnail,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageContent) Reset() {
+	*x = ImageContent{}
+	mi := &file_im_v1_im_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageContent) ProtoMessage() {}
+
+func (x *ImageContent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageContent.ProtoReflect.Descriptor instead.
+func (*ImageContent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ImageContent) GetAttachment() *Attachment {
+	if x != nil {
+		return x.Attachment
+	}
+	return nil
+}
+
+func (x *ImageContent) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ImageContent) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ImageContent) GetThumbnail() *Attachment {
+	if x != nil {
+		return x.Thumbnail
+	}
+	return nil
+}
+
+type FileContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attachment    *Attachment            `protobuf:"bytes,1,opt,name=attachment,proto3" json:"attachment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileContent) Reset() {
+	*x = FileContent{}
+	mi := &file_im_v1_im_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileContent) ProtoMessage() {}
+
+func (x *FileContent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileContent.ProtoReflect.Descriptor instead.
+func (*FileContent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *FileContent) GetAttachment() *Attachment {
+	if x != nil {
+		return x.Attachment
+	}
+	return nil
+}
+
+type AudioContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attachment    *Attachment            `protobuf:"bytes,1,opt,name=attachment,proto3" json:"attachment,omitempty"`
+	DurationMs    int32                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AudioContent) Reset() {
+	*x = AudioContent{}
+	mi := &file_im_v1_im_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioContent) ProtoMessage() {}
+
+func (x *AudioContent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioContent.ProtoReflect.Descriptor instead.
+func (*AudioContent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *AudioContent) GetAttachment() *Attachment {
+	if x != nil {
+		return x.Attachment
+	}
+	return nil
+}
+
+func (x *AudioContent) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type LocationContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocationContent) Reset() {
+	*x = LocationContent{}
+	mi := &file_im_v1_im_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocationContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocationContent) ProtoMessage() {}
+
+func (x *LocationContent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocationContent.ProtoReflect.Descriptor instead.
+func (*LocationContent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *LocationContent) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *LocationContent) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *LocationContent) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LocationContent) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+// CustomContent is an application-defined JSON document; type tells
+// clients how to render it.
+type CustomContent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Json          string                 `protobuf:"bytes,2,opt,name=json,proto3" json:"json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CustomContent) Reset() {
+	*x = CustomContent{}
+	mi := &file_im_v1_im_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CustomContent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CustomContent) ProtoMessage() {}
+
+func (x *CustomContent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CustomContent.ProtoReflect.Descriptor instead.
+func (*CustomContent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CustomContent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CustomContent) GetJson() string {
+	if x != nil {
+		return x.Json
+	}
+	return ""
+}
+
+type ReplyTo struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// Optional excerpt of the quoted message.
+	Excerpt       string `protobuf:"bytes,2,opt,name=excerpt,proto3" json:"excerpt,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplyTo) Reset() {
+	*x = ReplyTo{}
+	mi := &file_im_v1_im_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplyTo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplyTo) ProtoMessage() {}
+
+func (x *ReplyTo) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplyTo.ProtoReflect.Descriptor instead.
+func (*ReplyTo) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ReplyTo) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *ReplyTo) GetExcerpt() string {
+	if x != nil {
+		return x.Excerpt
+	}
+	return ""
+}
+
+// Delivery event: server -> client (single chat).
+// The client must answer with a DeliveryAck carrying message_id, otherwise
+// the server retransmits it until the connection closes.
+//
+// A message that is edited or recalled is delivered again with the same
+// message_id; the copy with recalled set or a newer edited_at replaces the
+// one the client has.
+type DeliverSingleMessage struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	From    string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	Message []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Server-assigned message ID.
+	MessageId int64 `protobuf:"varint,3,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// Position in the conversation with the sender, starting at 1 without
+	// gaps. Use Sync to fetch the messages of a gap.
+	Seq int64 `protobuf:"varint,4,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Unix milliseconds of the last edit, 0 if never edited.
+	EditedAt int64 `protobuf:"varint,5,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`
+	// A recalled message is a tombstone without content.
+	Recalled bool `protobuf:"varint,6,opt,name=recalled,proto3" json:"recalled,omitempty"`
+	// Typed content; message is empty when it is set.
+	Content       *Content `protobuf:"bytes,7,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliverSingleMessage) Reset() {
+	*x = DeliverSingleMessage{}
+	mi := &file_im_v1_im_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliverSingleMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliverSingleMessage) ProtoMessage() {}
+
+func (x *DeliverSingleMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliverSingleMessage.ProtoReflect.Descriptor instead.
+func (*DeliverSingleMessage) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *DeliverSingleMessage) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *DeliverSingleMessage) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *DeliverSingleMessage) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *DeliverSingleMessage) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *DeliverSingleMessage) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+func (x *DeliverSingleMessage) GetRecalled() bool {
+	if x != nil {
+		return x.Recalled
+	}
+	return false
+}
+
+func (x *DeliverSingleMessage) GetContent() *Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// Delivery event: server -> client (group chat).
+type DeliverGroupMessage struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	GroupUuid string                 `protobuf:"bytes,1,opt,name=group_uuid,json=groupUuid,proto3" json:"group_uuid,omitempty"`
+	From      string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	Message   []byte                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	MessageId int64                  `protobuf:"varint,4,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// Position in the group conversation, as in DeliverSingleMessage.
+	Seq      int64    `protobuf:"varint,5,opt,name=seq,proto3" json:"seq,omitempty"`
+	EditedAt int64    `protobuf:"varint,6,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`
+	Recalled bool     `protobuf:"varint,7,opt,name=recalled,proto3" json:"recalled,omitempty"`
+	Content  *Content `protobuf:"bytes,8,opt,name=content,proto3" json:"content,omitempty"`
+	// The message mentions the receiving user, directly or with @all.
+	Mentioned     bool `protobuf:"varint,9,opt,name=mentioned,proto3" json:"mentioned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliverGroupMessage) Reset() {
+	*x = DeliverGroupMessage{}
+	mi := &file_im_v1_im_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliverGroupMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliverGroupMessage) ProtoMessage() {}
+
+func (x *DeliverGroupMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliverGroupMessage.ProtoReflect.Descriptor instead.
+func (*DeliverGroupMessage) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DeliverGroupMessage) GetGroupUuid() string {
+	if x != nil {
+		return x.GroupUuid
+	}
+	return ""
+}
+
+func (x *DeliverGroupMessage) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *DeliverGroupMessage) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *DeliverGroupMessage) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *DeliverGroupMessage) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *DeliverGroupMessage) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+func (x *DeliverGroupMessage) GetRecalled() bool {
+	if x != nil {
+		return x.Recalled
+	}
+	return false
+}
+
+func (x *DeliverGroupMessage) GetContent() *Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *DeliverGroupMessage) GetMentioned() bool {
+	if x != nil {
+		return x.Mentioned
+	}
+	return false
+}
+
+// Client -> server acknowledgement of a DeliverSingleMessage/DeliverGroupMessage.
+type DeliveryAck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliveryAck) Reset() {
+	*x = DeliveryAck{}
+	mi := &file_im_v1_im_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryAck) ProtoMessage() {}
+
+func (x *DeliveryAck) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryAck.ProtoReflect.Descriptor instead.
+func (*DeliveryAck) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *DeliveryAck) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_im_v1_im_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Kicked: server -> client, the last message of a session displaced by a
+// newer login of the same user. The connection is then closed with code 4002.
+type Kicked struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Reason Kicked_Reason          `protobuf:"varint,1,opt,name=reason,proto3,enum=im.v1.Kicked_Reason" json:"reason,omitempty"`
+	// Device and platform of the new session.
+	DeviceId      string `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Platform      string `protobuf:"bytes,3,opt,name=platform,proto3" json:"platform,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Kicked) Reset() {
+	*x = Kicked{}
+	mi := &file_im_v1_im_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Kicked) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Kicked) ProtoMessage() {}
+
+func (x *Kicked) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Kicked.ProtoReflect.Descriptor instead.
+func (*Kicked) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *Kicked) GetReason() Kicked_Reason {
+	if x != nil {
+		return x.Reason
+	}
+	return Kicked_REASON_UNSPECIFIED
+}
+
+func (x *Kicked) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Kicked) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+// Pulls stored messages of a 1:1 conversation (peer) or a group
+// (group_uuid); exactly one of them is set.
+type PullHistory struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Peer      string                 `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	GroupUuid string                 `protobuf:"bytes,2,opt,name=group_uuid,json=groupUuid,proto3" json:"group_uuid,omitempty"`
+	// Message ID to page from, exclusive. 0 starts at the newest message
+	// when going BACKWARD and at the oldest when going FORWARD.
+	Cursor    int64                 `protobuf:"varint,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Direction PullHistory_Direction `protobuf:"varint,4,opt,name=direction,proto3,enum=im.v1.PullHistory_Direction" json:"direction,omitempty"`
+	// Page size; the server caps it.
+	Limit         int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullHistory) Reset() {
+	*x = PullHistory{}
+	mi := &file_im_v1_im_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullHistory) ProtoMessage() {}
+
+func (x *PullHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullHistory.ProtoReflect.Descriptor instead.
+func (*PullHistory) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *PullHistory) GetPeer() string {
+	if x != nil {
+		return x.Peer
+	}
+	return ""
+}
+
+func (x *PullHistory) GetGroupUuid() string {
+	if x != nil {
+		return x.GroupUuid
+	}
+	return ""
+}
+
+func (x *PullHistory) GetCursor() int64 {
+	if x != nil {
+		return x.Cursor
+	}
+	return 0
+}
+
+func (x *PullHistory) GetDirection() PullHistory_Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return PullHistory_BACKWARD
+}
+
+func (x *PullHistory) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type HistoryMessage struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	From      string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To        string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	GroupUuid string                 `protobuf:"bytes,4,opt,name=group_uuid,json=groupUuid,proto3" json:"group_uuid,omitempty"`
+	Message   []byte                 `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	// Unix milliseconds.
+	CreatedAt int64 `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Seq       int64 `protobuf:"varint,7,opt,name=seq,proto3" json:"seq,omitempty"`
+	// Unix milliseconds, 0 if never edited.
+	EditedAt      int64    `protobuf:"varint,8,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`
+	Recalled      bool     `protobuf:"varint,9,opt,name=recalled,proto3" json:"recalled,omitempty"`
+	Content       *Content `protobuf:"bytes,10,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryMessage) Reset() {
+	*x = HistoryMessage{}
+	mi := &file_im_v1_im_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryMessage) ProtoMessage() {}
+
+func (x *HistoryMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryMessage.ProtoReflect.Descriptor instead.
+func (*HistoryMessage) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *HistoryMessage) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *HistoryMessage) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *HistoryMessage) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *HistoryMessage) GetGroupUuid() string {
+	if x != nil {
+		return x.GroupUuid
+	}
+	return ""
+}
+
+func (x *HistoryMessage) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *HistoryMessage) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *HistoryMessage) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *HistoryMessage) GetEditedAt() int64 {
 	if x != nil {
-		return x.Type
+		return x.EditedAt
 	}
-	return LoginType_UNKNOWN
+	return 0
 }
 
-func (x *Login) GetField() string {
+func (x *HistoryMessage) GetRecalled() bool {
 	if x != nil {
-		return x.Field
+		return x.Recalled
 	}
-	return ""
+	return false
 }
 
-func (x *Login) GetPassword() string {
+func (x *HistoryMessage) GetContent() *Content {
 	if x != nil {
-		return x.Password
+		return x.Content
 	}
-	return ""
+	return nil
 }
 
-type Logout struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+// Answer to PullHistory, in the requested direction.
+type HistoryResp struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Messages []*HistoryMessage      `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Cursor of the next page in the same direction; pass it back as
+	// PullHistory.cursor while has_more is set.
+	NextCursor    int64 `protobuf:"varint,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	HasMore       bool  `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Logout) Reset() {
-	*x = Logout{}
-	mi := &file_im_v1_im_proto_msgTypes[6]
+func (x *HistoryResp) Reset() {
+	*x = HistoryResp{}
+	mi := &file_im_v1_im_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Logout) String() string {
+func (x *HistoryResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Logout) ProtoMessage() {}
+func (*HistoryResp) ProtoMessage() {}
 
-func (x *Logout) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[6]
+func (x *HistoryResp) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -898,41 +4445,62 @@ func (x *Logout) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Logout.ProtoReflect.Descriptor instead.
-func (*Logout) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use HistoryResp.ProtoReflect.Descriptor instead.
+func (*HistoryResp) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{48}
 }
 
-func (x *Logout) GetUuid() string {
+func (x *HistoryResp) GetMessages() []*HistoryMessage {
 	if x != nil {
-		return x.Uuid
+		return x.Messages
 	}
-	return ""
+	return nil
 }
 
-type ApplyForFriend struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	Uuid          string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+func (x *HistoryResp) GetNextCursor() int64 {
+	if x != nil {
+		return x.NextCursor
+	}
+	return 0
+}
+
+func (x *HistoryResp) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// Asks for the messages of a conversation by sequence number, typically to
+// fill a gap. Exactly one of peer and group_uuid is set.
+type Sync struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Peer      string                 `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	GroupUuid string                 `protobuf:"bytes,2,opt,name=group_uuid,json=groupUuid,proto3" json:"group_uuid,omitempty"`
+	// Inclusive range; to_seq 0 means up to the latest message.
+	FromSeq int64 `protobuf:"varint,3,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"`
+	ToSeq   int64 `protobuf:"varint,4,opt,name=to_seq,json=toSeq,proto3" json:"to_seq,omitempty"`
+	// Page size; the server caps it.
+	Limit         int32 `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ApplyForFriend) Reset() {
-	*x = ApplyForFriend{}
-	mi := &file_im_v1_im_proto_msgTypes[7]
+func (x *Sync) Reset() {
+	*x = Sync{}
+	mi := &file_im_v1_im_proto_msgTypes[49]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ApplyForFriend) String() string {
+func (x *Sync) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApplyForFriend) ProtoMessage() {}
+func (*Sync) ProtoMessage() {}
 
-func (x *ApplyForFriend) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[7]
+func (x *Sync) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[49]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -943,91 +4511,74 @@ func (x *ApplyForFriend) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApplyForFriend.ProtoReflect.Descriptor instead.
-func (*ApplyForFriend) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use Sync.ProtoReflect.Descriptor instead.
+func (*Sync) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *ApplyForFriend) GetRequestId() string {
+func (x *Sync) GetPeer() string {
 	if x != nil {
-		return x.RequestId
+		return x.Peer
 	}
 	return ""
 }
 
-func (x *ApplyForFriend) GetUuid() string {
+func (x *Sync) GetGroupUuid() string {
 	if x != nil {
-		return x.Uuid
+		return x.GroupUuid
 	}
 	return ""
 }
 
-type AcceptFriend struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *AcceptFriend) Reset() {
-	*x = AcceptFriend{}
-	mi := &file_im_v1_im_proto_msgTypes[8]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *AcceptFriend) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*AcceptFriend) ProtoMessage() {}
-
-func (x *AcceptFriend) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[8]
+func (x *Sync) GetFromSeq() int64 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.FromSeq
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use AcceptFriend.ProtoReflect.Descriptor instead.
-func (*AcceptFriend) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{8}
+func (x *Sync) GetToSeq() int64 {
+	if x != nil {
+		return x.ToSeq
+	}
+	return 0
 }
 
-func (x *AcceptFriend) GetRequestId() string {
+func (x *Sync) GetLimit() int32 {
 	if x != nil {
-		return x.RequestId
+		return x.Limit
 	}
-	return ""
+	return 0
 }
 
-type RejectFriend struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+// Answer to Sync, in sequence order.
+type SyncResp struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Messages []*HistoryMessage      `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Latest sequence number of the conversation.
+	LastSeq int64 `protobuf:"varint,2,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`
+	// Set when the range holds more messages than returned; continue from
+	// the seq after the last message.
+	HasMore       bool `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RejectFriend) Reset() {
-	*x = RejectFriend{}
-	mi := &file_im_v1_im_proto_msgTypes[9]
+func (x *SyncResp) Reset() {
+	*x = SyncResp{}
+	mi := &file_im_v1_im_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RejectFriend) String() string {
+func (x *SyncResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RejectFriend) ProtoMessage() {}
+func (*SyncResp) ProtoMessage() {}
 
-func (x *RejectFriend) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[9]
+func (x *SyncResp) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1038,41 +4589,56 @@ func (x *RejectFriend) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RejectFriend.ProtoReflect.Descriptor instead.
-func (*RejectFriend) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use SyncResp.ProtoReflect.Descriptor instead.
+func (*SyncResp) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *RejectFriend) GetRequestId() string {
+func (x *SyncResp) GetMessages() []*HistoryMessage {
 	if x != nil {
-		return x.RequestId
+		return x.Messages
 	}
-	return ""
+	return nil
 }
 
-type CreateGroup struct {
+func (x *SyncResp) GetLastSeq() int64 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+func (x *SyncResp) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// A 1:1 conversation (peer) or a group (group_uuid); exactly one is set.
+type Conversation struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Peer          string                 `protobuf:"bytes,1,opt,name=peer,proto3" json:"peer,omitempty"`
+	GroupUuid     string                 `protobuf:"bytes,2,opt,name=group_uuid,json=groupUuid,proto3" json:"group_uuid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateGroup) Reset() {
-	*x = CreateGroup{}
-	mi := &file_im_v1_im_proto_msgTypes[10]
+func (x *Conversation) Reset() {
+	*x = Conversation{}
+	mi := &file_im_v1_im_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateGroup) String() string {
+func (x *Conversation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateGroup) ProtoMessage() {}
+func (*Conversation) ProtoMessage() {}
 
-func (x *CreateGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[10]
+func (x *Conversation) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1083,48 +4649,50 @@ func (x *CreateGroup) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateGroup.ProtoReflect.Descriptor instead.
-func (*CreateGroup) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use Conversation.ProtoReflect.Descriptor instead.
+func (*Conversation) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{51}
 }
 
-func (x *CreateGroup) GetUuid() string {
+func (x *Conversation) GetPeer() string {
 	if x != nil {
-		return x.Uuid
+		return x.Peer
 	}
 	return ""
 }
 
-func (x *CreateGroup) GetName() string {
+func (x *Conversation) GetGroupUuid() string {
 	if x != nil {
-		return x.Name
+		return x.GroupUuid
 	}
 	return ""
 }
 
-type ApplyForGroup struct {
+// Marks every message of a conversation up to seq as read; seq 0 marks all
+// of them.
+type MarkRead struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
-	Uuid          string                 `protobuf:"bytes,2,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Seq           int64                  `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ApplyForGroup) Reset() {
-	*x = ApplyForGroup{}
-	mi := &file_im_v1_im_proto_msgTypes[11]
+func (x *MarkRead) Reset() {
+	*x = MarkRead{}
+	mi := &file_im_v1_im_proto_msgTypes[52]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ApplyForGroup) String() string {
+func (x *MarkRead) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ApplyForGroup) ProtoMessage() {}
+func (*MarkRead) ProtoMessage() {}
 
-func (x *ApplyForGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[11]
+func (x *MarkRead) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[52]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1135,47 +4703,53 @@ func (x *ApplyForGroup) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ApplyForGroup.ProtoReflect.Descriptor instead.
-func (*ApplyForGroup) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use MarkRead.ProtoReflect.Descriptor instead.
+func (*MarkRead) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{52}
 }
 
-func (x *ApplyForGroup) GetRequestId() string {
+func (x *MarkRead) GetConversation() *Conversation {
 	if x != nil {
-		return x.RequestId
+		return x.Conversation
 	}
-	return ""
+	return nil
 }
 
-func (x *ApplyForGroup) GetUuid() string {
+func (x *MarkRead) GetSeq() int64 {
 	if x != nil {
-		return x.Uuid
+		return x.Seq
 	}
-	return ""
+	return 0
 }
 
-type AcceptGroup struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+// Read receipt: server -> client. Sent to the other party of a 1:1
+// conversation, or to every group member, and to all devices of the reader.
+type ReadReceipt struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Reader string                 `protobuf:"bytes,1,opt,name=reader,proto3" json:"reader,omitempty"`
+	// The conversation as seen by the receiving user: for a 1:1 conversation
+	// peer is the other party of the receiver.
+	Conversation  *Conversation `protobuf:"bytes,2,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Seq           int64         `protobuf:"varint,3,opt,name=seq,proto3" json:"seq,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AcceptGroup) Reset() {
-	*x = AcceptGroup{}
-	mi := &file_im_v1_im_proto_msgTypes[12]
+func (x *ReadReceipt) Reset() {
+	*x = ReadReceipt{}
+	mi := &file_im_v1_im_proto_msgTypes[53]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AcceptGroup) String() string {
+func (x *ReadReceipt) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AcceptGroup) ProtoMessage() {}
+func (*ReadReceipt) ProtoMessage() {}
 
-func (x *AcceptGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[12]
+func (x *ReadReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[53]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1186,40 +4760,54 @@ func (x *AcceptGroup) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AcceptGroup.ProtoReflect.Descriptor instead.
-func (*AcceptGroup) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use ReadReceipt.ProtoReflect.Descriptor instead.
+func (*ReadReceipt) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *AcceptGroup) GetRequestId() string {
+func (x *ReadReceipt) GetReader() string {
 	if x != nil {
-		return x.RequestId
+		return x.Reader
 	}
 	return ""
 }
 
-type RejectGroup struct {
+func (x *ReadReceipt) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+func (x *ReadReceipt) GetSeq() int64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+type GetUnread struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Conversations []*Conversation        `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RejectGroup) Reset() {
-	*x = RejectGroup{}
-	mi := &file_im_v1_im_proto_msgTypes[13]
+func (x *GetUnread) Reset() {
+	*x = GetUnread{}
+	mi := &file_im_v1_im_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RejectGroup) String() string {
+func (x *GetUnread) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RejectGroup) ProtoMessage() {}
+func (*GetUnread) ProtoMessage() {}
 
-func (x *RejectGroup) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[13]
+func (x *GetUnread) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1230,42 +4818,43 @@ func (x *RejectGroup) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RejectGroup.ProtoReflect.Descriptor instead.
-func (*RejectGroup) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use GetUnread.ProtoReflect.Descriptor instead.
+func (*GetUnread) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{54}
 }
 
-func (x *RejectGroup) GetRequestId() string {
+func (x *GetUnread) GetConversations() []*Conversation {
 	if x != nil {
-		return x.RequestId
+		return x.Conversations
 	}
-	return ""
+	return nil
 }
 
-type ListGroups struct {
+type UnreadCount struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
-	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Unread        int64                  `protobuf:"varint,2,opt,name=unread,proto3" json:"unread,omitempty"`
+	ReadSeq       int64                  `protobuf:"varint,3,opt,name=read_seq,json=readSeq,proto3" json:"read_seq,omitempty"`
+	LastSeq       int64                  `protobuf:"varint,4,opt,name=last_seq,json=lastSeq,proto3" json:"last_seq,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListGroups) Reset() {
-	*x = ListGroups{}
-	mi := &file_im_v1_im_proto_msgTypes[14]
+func (x *UnreadCount) Reset() {
+	*x = UnreadCount{}
+	mi := &file_im_v1_im_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListGroups) String() string {
+func (x *UnreadCount) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListGroups) ProtoMessage() {}
+func (*UnreadCount) ProtoMessage() {}
 
-func (x *ListGroups) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[14]
+func (x *UnreadCount) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1276,56 +4865,62 @@ func (x *ListGroups) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListGroups.ProtoReflect.Descriptor instead.
-func (*ListGroups) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use UnreadCount.ProtoReflect.Descriptor instead.
+func (*UnreadCount) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{55}
 }
 
-func (x *ListGroups) GetUuid() string {
+func (x *UnreadCount) GetConversation() *Conversation {
 	if x != nil {
-		return x.Uuid
+		return x.Conversation
 	}
-	return ""
+	return nil
 }
 
-func (x *ListGroups) GetPage() int32 {
+func (x *UnreadCount) GetUnread() int64 {
 	if x != nil {
-		return x.Page
+		return x.Unread
 	}
 	return 0
 }
 
-func (x *ListGroups) GetPageSize() int32 {
+func (x *UnreadCount) GetReadSeq() int64 {
 	if x != nil {
-		return x.PageSize
+		return x.ReadSeq
 	}
 	return 0
 }
 
-type GroupInfo struct {
+func (x *UnreadCount) GetLastSeq() int64 {
+	if x != nil {
+		return x.LastSeq
+	}
+	return 0
+}
+
+// Answer to GetUnread, in the order of the request.
+type UnreadResp struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Owner         string                 `protobuf:"bytes,3,opt,name=owner,proto3" json:"owner,omitempty"`
+	Counts        []*UnreadCount         `protobuf:"bytes,1,rep,name=counts,proto3" json:"counts,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GroupInfo) Reset() {
-	*x = GroupInfo{}
-	mi := &file_im_v1_im_proto_msgTypes[15]
+func (x *UnreadResp) Reset() {
+	*x = UnreadResp{}
+	mi := &file_im_v1_im_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GroupInfo) String() string {
+func (x *UnreadResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GroupInfo) ProtoMessage() {}
+func (*UnreadResp) ProtoMessage() {}
 
-func (x *GroupInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[15]
+func (x *UnreadResp) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1336,56 +4931,42 @@ func (x *GroupInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GroupInfo.ProtoReflect.Descriptor instead.
-func (*GroupInfo) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{15}
-}
-
-func (x *GroupInfo) GetUuid() string {
-	if x != nil {
-		return x.Uuid
-	}
-	return ""
-}
-
-func (x *GroupInfo) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
+// Deprecated: Use UnreadResp.ProtoReflect.Descriptor instead.
+func (*UnreadResp) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{56}
 }
 
-func (x *GroupInfo) GetOwner() string {
+func (x *UnreadResp) GetCounts() []*UnreadCount {
 	if x != nil {
-		return x.Owner
+		return x.Counts
 	}
-	return ""
+	return nil
 }
 
-type ListGroupsResp struct {
+// Payload of TYPING_START and TYPING_STOP. Clients repeat TYPING_START every
+// few seconds while the user keeps typing; the server coalesces repeats.
+type Typing struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	GroupInfo     []*GroupInfo           `protobuf:"bytes,3,rep,name=group_info,json=groupInfo,proto3" json:"group_info,omitempty"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListGroupsResp) Reset() {
-	*x = ListGroupsResp{}
-	mi := &file_im_v1_im_proto_msgTypes[16]
+func (x *Typing) Reset() {
+	*x = Typing{}
+	mi := &file_im_v1_im_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListGroupsResp) String() string {
+func (x *Typing) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListGroupsResp) ProtoMessage() {}
+func (*Typing) ProtoMessage() {}
 
-func (x *ListGroupsResp) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[16]
+func (x *Typing) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1396,54 +4977,45 @@ func (x *ListGroupsResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListGroupsResp.ProtoReflect.Descriptor instead.
-func (*ListGroupsResp) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{16}
-}
-
-func (x *ListGroupsResp) GetStatus() int32 {
-	if x != nil {
-		return x.Status
-	}
-	return 0
-}
-
-func (x *ListGroupsResp) GetMessage() string {
-	if x != nil {
-		return x.Message
-	}
-	return ""
+// Deprecated: Use Typing.ProtoReflect.Descriptor instead.
+func (*Typing) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{57}
 }
 
-func (x *ListGroupsResp) GetGroupInfo() []*GroupInfo {
+func (x *Typing) GetConversation() *Conversation {
 	if x != nil {
-		return x.GroupInfo
+		return x.Conversation
 	}
 	return nil
 }
 
-type ListGroupMember struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+// Typing indicator: server -> client. Not acknowledged and never stored;
+// receivers should drop a typing state that is not refreshed within ~10s.
+type TypingEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	User  string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	// The conversation as seen by the receiver, like in ReadReceipt.
+	Conversation  *Conversation `protobuf:"bytes,2,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	Typing        bool          `protobuf:"varint,3,opt,name=typing,proto3" json:"typing,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListGroupMember) Reset() {
-	*x = ListGroupMember{}
-	mi := &file_im_v1_im_proto_msgTypes[17]
+func (x *TypingEvent) Reset() {
+	*x = TypingEvent{}
+	mi := &file_im_v1_im_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListGroupMember) String() string {
+func (x *TypingEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListGroupMember) ProtoMessage() {}
+func (*TypingEvent) ProtoMessage() {}
 
-func (x *ListGroupMember) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[17]
+func (x *TypingEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1454,41 +5026,59 @@ func (x *ListGroupMember) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListGroupMember.ProtoReflect.Descriptor instead.
-func (*ListGroupMember) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use TypingEvent.ProtoReflect.Descriptor instead.
+func (*TypingEvent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{58}
 }
 
-func (x *ListGroupMember) GetUuid() string {
+func (x *TypingEvent) GetUser() string {
 	if x != nil {
-		return x.Uuid
+		return x.User
 	}
 	return ""
 }
 
-type GroupMember struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+func (x *TypingEvent) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+func (x *TypingEvent) GetTyping() bool {
+	if x != nil {
+		return x.Typing
+	}
+	return false
+}
+
+// Presence change of a friend: server -> client. Sent when the first device
+// of the user connects and some seconds after the last one disconnected.
+type PresenceEvent struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	User   string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Online bool                   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`
+	// Unix milliseconds.
+	At            int64 `protobuf:"varint,3,opt,name=at,proto3" json:"at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GroupMember) Reset() {
-	*x = GroupMember{}
-	mi := &file_im_v1_im_proto_msgTypes[18]
+func (x *PresenceEvent) Reset() {
+	*x = PresenceEvent{}
+	mi := &file_im_v1_im_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GroupMember) String() string {
+func (x *PresenceEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GroupMember) ProtoMessage() {}
+func (*PresenceEvent) ProtoMessage() {}
 
-func (x *GroupMember) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[18]
+func (x *PresenceEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1499,48 +5089,56 @@ func (x *GroupMember) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GroupMember.ProtoReflect.Descriptor instead.
-func (*GroupMember) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use PresenceEvent.ProtoReflect.Descriptor instead.
+func (*PresenceEvent) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{59}
 }
 
-func (x *GroupMember) GetUuid() string {
+func (x *PresenceEvent) GetUser() string {
 	if x != nil {
-		return x.Uuid
+		return x.User
 	}
 	return ""
 }
 
-func (x *GroupMember) GetName() string {
+func (x *PresenceEvent) GetOnline() bool {
 	if x != nil {
-		return x.Name
+		return x.Online
 	}
-	return ""
+	return false
 }
 
-type ListGroupMemberResp struct {
+func (x *PresenceEvent) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+// Withdraws a message the caller sent. Recipients get it again as a
+// tombstone. Only allowed within the server's recall window.
+type RecallMessage struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        int32                  `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
-	GroupMember   []*GroupMember         `protobuf:"bytes,2,rep,name=group_member,json=groupMember,proto3" json:"group_member,omitempty"`
+	MessageId     int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListGroupMemberResp) Reset() {
-	*x = ListGroupMemberResp{}
-	mi := &file_im_v1_im_proto_msgTypes[19]
+func (x *RecallMessage) Reset() {
+	*x = RecallMessage{}
+	mi := &file_im_v1_im_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListGroupMemberResp) String() string {
+func (x *RecallMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListGroupMemberResp) ProtoMessage() {}
+func (*RecallMessage) ProtoMessage() {}
 
-func (x *ListGroupMemberResp) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[19]
+func (x *RecallMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1551,48 +5149,45 @@ func (x *ListGroupMemberResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListGroupMemberResp.ProtoReflect.Descriptor instead.
-func (*ListGroupMemberResp) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use RecallMessage.ProtoReflect.Descriptor instead.
+func (*RecallMessage) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *ListGroupMemberResp) GetStatus() int32 {
+func (x *RecallMessage) GetMessageId() int64 {
 	if x != nil {
-		return x.Status
+		return x.MessageId
 	}
 	return 0
 }
 
-func (x *ListGroupMemberResp) GetGroupMember() []*GroupMember {
-	if x != nil {
-		return x.GroupMember
-	}
-	return nil
-}
-
-type SingleMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	To            string                 `protobuf:"bytes,1,opt,name=to,proto3" json:"to,omitempty"`
-	Message       []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+// Replaces the content of a message the caller sent. Only allowed within
+// the server's edit window and not on recalled messages.
+type EditMessage struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	MessageId int64                  `protobuf:"varint,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	// The new body, as in SingleMessage.
+	Message       []byte   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Content       *Content `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SingleMessage) Reset() {
-	*x = SingleMessage{}
-	mi := &file_im_v1_im_proto_msgTypes[20]
+func (x *EditMessage) Reset() {
+	*x = EditMessage{}
+	mi := &file_im_v1_im_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SingleMessage) String() string {
+func (x *EditMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SingleMessage) ProtoMessage() {}
+func (*EditMessage) ProtoMessage() {}
 
-func (x *SingleMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[20]
+func (x *EditMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1603,48 +5198,56 @@ func (x *SingleMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SingleMessage.ProtoReflect.Descriptor instead.
-func (*SingleMessage) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use EditMessage.ProtoReflect.Descriptor instead.
+func (*EditMessage) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *SingleMessage) GetTo() string {
+func (x *EditMessage) GetMessageId() int64 {
 	if x != nil {
-		return x.To
+		return x.MessageId
 	}
-	return ""
+	return 0
 }
 
-func (x *SingleMessage) GetMessage() []byte {
+func (x *EditMessage) GetMessage() []byte {
 	if x != nil {
 		return x.Message
 	}
 	return nil
 }
 
-type GroupMessage struct {
+func (x *EditMessage) GetContent() *Content {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+// Asks for a URL to upload one attachment: an HTTP PUT of the raw bytes,
+// answered with JSON {"blob_id", "content_type", "size"}. Messages then
+// reference the blob_id in an Attachment.
+type CreateUploadUrl struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
-	Message       []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GroupMessage) Reset() {
-	*x = GroupMessage{}
-	mi := &file_im_v1_im_proto_msgTypes[21]
+func (x *CreateUploadUrl) Reset() {
+	*x = CreateUploadUrl{}
+	mi := &file_im_v1_im_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GroupMessage) String() string {
+func (x *CreateUploadUrl) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GroupMessage) ProtoMessage() {}
+func (*CreateUploadUrl) ProtoMessage() {}
 
-func (x *GroupMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[21]
+func (x *CreateUploadUrl) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1655,49 +5258,34 @@ func (x *GroupMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GroupMessage.ProtoReflect.Descriptor instead.
-func (*GroupMessage) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{21}
-}
-
-func (x *GroupMessage) GetUuid() string {
-	if x != nil {
-		return x.Uuid
-	}
-	return ""
-}
-
-func (x *GroupMessage) GetMessage() []byte {
-	if x != nil {
-		return x.Message
-	}
-	return nil
+// Deprecated: Use CreateUploadUrl.ProtoReflect.Descriptor instead.
+func (*CreateUploadUrl) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{62}
 }
 
-// Delivery event: server -> client (single chat).
-type DeliverSingleMessage struct {
+// Asks for a URL to download blob_id with an HTTP GET.
+type CreateDownloadUrl struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
-	Message       []byte                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	BlobId        string                 `protobuf:"bytes,1,opt,name=blob_id,json=blobId,proto3" json:"blob_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeliverSingleMessage) Reset() {
-	*x = DeliverSingleMessage{}
-	mi := &file_im_v1_im_proto_msgTypes[22]
+func (x *CreateDownloadUrl) Reset() {
+	*x = CreateDownloadUrl{}
+	mi := &file_im_v1_im_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeliverSingleMessage) String() string {
+func (x *CreateDownloadUrl) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeliverSingleMessage) ProtoMessage() {}
+func (*CreateDownloadUrl) ProtoMessage() {}
 
-func (x *DeliverSingleMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[22]
+func (x *CreateDownloadUrl) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1708,50 +5296,44 @@ func (x *DeliverSingleMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeliverSingleMessage.ProtoReflect.Descriptor instead.
-func (*DeliverSingleMessage) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use CreateDownloadUrl.ProtoReflect.Descriptor instead.
+func (*CreateDownloadUrl) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{63}
 }
 
-func (x *DeliverSingleMessage) GetFrom() string {
+func (x *CreateDownloadUrl) GetBlobId() string {
 	if x != nil {
-		return x.From
+		return x.BlobId
 	}
 	return ""
 }
 
-func (x *DeliverSingleMessage) GetMessage() []byte {
-	if x != nil {
-		return x.Message
-	}
-	return nil
-}
-
-// Delivery event: server -> client (group chat).
-type DeliverGroupMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	GroupUuid     string                 `protobuf:"bytes,1,opt,name=group_uuid,json=groupUuid,proto3" json:"group_uuid,omitempty"`
-	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
-	Message       []byte                 `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+// Answer to CreateUploadUrl and CreateDownloadUrl. The URL is signed for the
+// requesting user and stops working at expires_at.
+type BlobUrl struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Url   string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// Unix milliseconds.
+	ExpiresAt     int64 `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeliverGroupMessage) Reset() {
-	*x = DeliverGroupMessage{}
-	mi := &file_im_v1_im_proto_msgTypes[23]
+func (x *BlobUrl) Reset() {
+	*x = BlobUrl{}
+	mi := &file_im_v1_im_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeliverGroupMessage) String() string {
+func (x *BlobUrl) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeliverGroupMessage) ProtoMessage() {}
+func (*BlobUrl) ProtoMessage() {}
 
-func (x *DeliverGroupMessage) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[23]
+func (x *BlobUrl) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1762,55 +5344,51 @@ func (x *DeliverGroupMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeliverGroupMessage.ProtoReflect.Descriptor instead.
-func (*DeliverGroupMessage) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{23}
-}
-
-func (x *DeliverGroupMessage) GetGroupUuid() string {
-	if x != nil {
-		return x.GroupUuid
-	}
-	return ""
+// Deprecated: Use BlobUrl.ProtoReflect.Descriptor instead.
+func (*BlobUrl) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *DeliverGroupMessage) GetFrom() string {
+func (x *BlobUrl) GetUrl() string {
 	if x != nil {
-		return x.From
+		return x.Url
 	}
 	return ""
 }
 
-func (x *DeliverGroupMessage) GetMessage() []byte {
+func (x *BlobUrl) GetExpiresAt() int64 {
 	if x != nil {
-		return x.Message
+		return x.ExpiresAt
 	}
-	return nil
+	return 0
 }
 
-type Error struct {
+// Sets the caller's notification preference for a group they are in.
+// Messages are still delivered; the preference decides about alerts and
+// push notifications.
+type SetGroupNotify struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Uuid          string                 `protobuf:"bytes,1,opt,name=uuid,proto3" json:"uuid,omitempty"`
+	Level         GroupNotify            `protobuf:"varint,2,opt,name=level,proto3,enum=im.v1.GroupNotify" json:"level,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Error) Reset() {
-	*x = Error{}
-	mi := &file_im_v1_im_proto_msgTypes[24]
+func (x *SetGroupNotify) Reset() {
+	*x = SetGroupNotify{}
+	mi := &file_im_v1_im_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Error) String() string {
+func (x *SetGroupNotify) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Error) ProtoMessage() {}
+func (*SetGroupNotify) ProtoMessage() {}
 
-func (x *Error) ProtoReflect() protoreflect.Message {
-	mi := &file_im_v1_im_proto_msgTypes[24]
+func (x *SetGroupNotify) ProtoReflect() protoreflect.Message {
+	mi := &file_im_v1_im_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1821,30 +5399,30 @@ func (x *Error) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Error.ProtoReflect.Descriptor instead.
-func (*Error) Descriptor() ([]byte, []int) {
-	return file_im_v1_im_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use SetGroupNotify.ProtoReflect.Descriptor instead.
+func (*SetGroupNotify) Descriptor() ([]byte, []int) {
+	return file_im_v1_im_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *Error) GetCode() string {
+func (x *SetGroupNotify) GetUuid() string {
 	if x != nil {
-		return x.Code
+		return x.Uuid
 	}
 	return ""
 }
 
-func (x *Error) GetMessage() string {
+func (x *SetGroupNotify) GetLevel() GroupNotify {
 	if x != nil {
-		return x.Message
+		return x.Level
 	}
-	return ""
+	return GroupNotify_GROUP_NOTIFY_ALL
 }
 
 var File_im_v1_im_proto protoreflect.FileDescriptor
 
 const file_im_v1_im_proto_rawDesc = "" +
 	"\n" +
-	"\x0eim/v1/im.proto\x12\x05im.v1\"\x9e\a\n" +
+	"\x0eim/v1/im.proto\x12\x05im.v1\"\x8f\x0f\n" +
 	"\x0eClientEnvelope\x12\x19\n" +
 	"\btrace_id\x18\x01 \x01(\tR\atraceId\x12&\n" +
 	"\x04type\x18\x02 \x01(\x0e2\x12.im.v1.MessageTypeR\x04type\x12!\n" +
@@ -1864,15 +5442,47 @@ const file_im_v1_im_proto_rawDesc = "" +
 	"listGroups\x12F\n" +
 	"\x12list_group_memeber\x18\x16 \x01(\v2\x16.im.v1.ListGroupMemberH\x00R\x10listGroupMemeber\x12=\n" +
 	"\x0esingle_message\x18\x17 \x01(\v2\x14.im.v1.SingleMessageH\x00R\rsingleMessage\x12:\n" +
-	"\rgroup_message\x18\x18 \x01(\v2\x13.im.v1.GroupMessageH\x00R\fgroupMessageB\t\n" +
-	"\apayload\"\xeb\x03\n" +
+	"\rgroup_message\x18\x18 \x01(\v2\x13.im.v1.GroupMessageH\x00R\fgroupMessage\x127\n" +
+	"\fdelivery_ack\x18\x19 \x01(\v2\x12.im.v1.DeliveryAckH\x00R\vdeliveryAck\x12J\n" +
+	"\x13invite_group_member\x18\x1a \x01(\v2\x18.im.v1.InviteGroupMemberH\x00R\x11inviteGroupMember\x12D\n" +
+	"\x11kick_group_member\x18\x1b \x01(\v2\x16.im.v1.KickGroupMemberH\x00R\x0fkickGroupMember\x127\n" +
+	"\frename_group\x18\x1c \x01(\v2\x12.im.v1.RenameGroupH\x00R\vrenameGroup\x12=\n" +
+	"\x0edissolve_group\x18\x1d \x01(\v2\x14.im.v1.DissolveGroupH\x00R\rdissolveGroup\x12;\n" +
+	"\x0eset_group_role\x18\x1e \x01(\v2\x13.im.v1.SetGroupRoleH\x00R\fsetGroupRole\x12:\n" +
+	"\rrefresh_token\x18\x1f \x01(\v2\x13.im.v1.RefreshTokenH\x00R\frefreshToken\x127\n" +
+	"\fpull_history\x18  \x01(\v2\x12.im.v1.PullHistoryH\x00R\vpullHistory\x12!\n" +
+	"\x04sync\x18! \x01(\v2\v.im.v1.SyncH\x00R\x04sync\x12.\n" +
+	"\tmark_read\x18\" \x01(\v2\x0f.im.v1.MarkReadH\x00R\bmarkRead\x121\n" +
+	"\n" +
+	"get_unread\x18# \x01(\v2\x10.im.v1.GetUnreadH\x00R\tgetUnread\x12'\n" +
+	"\x06typing\x18$ \x01(\v2\r.im.v1.TypingH\x00R\x06typing\x12=\n" +
+	"\x0erecall_message\x18% \x01(\v2\x14.im.v1.RecallMessageH\x00R\rrecallMessage\x127\n" +
+	"\fedit_message\x18& \x01(\v2\x12.im.v1.EditMessageH\x00R\veditMessage\x12D\n" +
+	"\x11create_upload_url\x18' \x01(\v2\x16.im.v1.CreateUploadUrlH\x00R\x0fcreateUploadUrl\x12J\n" +
+	"\x13create_download_url\x18( \x01(\v2\x18.im.v1.CreateDownloadUrlH\x00R\x11createDownloadUrl\x12A\n" +
+	"\x10set_group_notify\x18) \x01(\v2\x15.im.v1.SetGroupNotifyH\x00R\x0esetGroupNotifyB\t\n" +
+	"\apayload\"\xb3\b\n" +
 	"\x0eServerEnvelope\x12\x19\n" +
 	"\btrace_id\x18\x01 \x01(\tR\atraceId\x12!\n" +
 	"\x04echo\x18\n" +
 	" \x01(\v2\v.im.v1.EchoH\x00R\x04echo\x12+\n" +
-	"\back_resp\x18\v \x01(\v2\x0e.im.v1.AckRespH\x00R\aackResp\x12A\n" +
+	"\back_resp\x18\v \x01(\v2\x0e.im.v1.AckRespH\x00R\aackResp\x121\n" +
+	"\n" +
+	"login_resp\x18\f \x01(\v2\x10.im.v1.LoginRespH\x00R\tloginResp\x12'\n" +
+	"\x06kicked\x18\r \x01(\v2\r.im.v1.KickedH\x00R\x06kicked\x127\n" +
+	"\fhistory_resp\x18\x0e \x01(\v2\x12.im.v1.HistoryRespH\x00R\vhistoryResp\x12.\n" +
+	"\tsync_resp\x18\x13 \x01(\v2\x0f.im.v1.SyncRespH\x00R\bsyncResp\x127\n" +
+	"\fread_receipt\x18\x16 \x01(\v2\x12.im.v1.ReadReceiptH\x00R\vreadReceipt\x124\n" +
+	"\vunread_resp\x18\x17 \x01(\v2\x11.im.v1.UnreadRespH\x00R\n" +
+	"unreadResp\x127\n" +
+	"\ftyping_event\x18\x18 \x01(\v2\x12.im.v1.TypingEventH\x00R\vtypingEvent\x12=\n" +
+	"\x0epresence_event\x18\x19 \x01(\v2\x14.im.v1.PresenceEventH\x00R\rpresenceEvent\x12+\n" +
+	"\bblob_url\x18\x1a \x01(\v2\x0e.im.v1.BlobUrlH\x00R\ablobUrl\x12A\n" +
 	"\x10list_groups_resp\x18\x0f \x01(\v2\x15.im.v1.ListGroupsRespH\x00R\x0elistGroupsResp\x12S\n" +
-	"\x17list_group_memeber_resp\x18\x10 \x01(\v2\x1a.im.v1.ListGroupMemberRespH\x00R\x14listGroupMemeberResp\x12S\n" +
+	"\x17list_group_memeber_resp\x18\x10 \x01(\v2\x1a.im.v1.ListGroupMemberRespH\x00R\x14listGroupMemeberResp\x127\n" +
+	"\ffriend_event\x18\x11 \x01(\v2\x12.im.v1.FriendEventH\x00R\vfriendEvent\x124\n" +
+	"\vgroup_event\x18\x12 \x01(\v2\x11.im.v1.GroupEventH\x00R\n" +
+	"groupEvent\x12S\n" +
 	"\x16deliver_single_message\x18\x14 \x01(\v2\x1b.im.v1.DeliverSingleMessageH\x00R\x14deliverSingleMessage\x12P\n" +
 	"\x15deliver_group_message\x18\x15 \x01(\v2\x1a.im.v1.DeliverGroupMessageH\x00R\x13deliverGroupMessage\x12$\n" +
 	"\x05error\x18c \x01(\v2\f.im.v1.ErrorH\x00R\x05errorB\t\n" +
@@ -1884,14 +5494,25 @@ const file_im_v1_im_proto_rawDesc = "" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12\x14\n" +
 	"\x05phone\x18\x03 \x01(\tR\x05phone\x12\x12\n" +
 	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1a\n" +
-	"\bpassword\x18\x05 \x01(\tR\bpassword\";\n" +
+	"\bpassword\x18\x05 \x01(\tR\bpassword\"x\n" +
 	"\aAckResp\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\x05R\x06status\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"_\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x03 \x01(\x03R\tmessageId\x12\x1c\n" +
+	"\tduplicate\x18\x04 \x01(\bR\tduplicate\"\x98\x01\n" +
 	"\x05Login\x12$\n" +
 	"\x04type\x18\x01 \x01(\x0e2\x10.im.v1.LoginTypeR\x04type\x12\x14\n" +
 	"\x05field\x18\x02 \x01(\tR\x05field\x12\x1a\n" +
-	"\bpassword\x18\x03 \x01(\tR\bpassword\"\x1c\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\x12\x1b\n" +
+	"\tdevice_id\x18\x04 \x01(\tR\bdeviceId\x12\x1a\n" +
+	"\bplatform\x18\x05 \x01(\tR\bplatform\"\x0e\n" +
+	"\fRefreshToken\"T\n" +
+	"\tLoginResp\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"\x1c\n" +
 	"\x06Logout\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\"C\n" +
 	"\x0eApplyForFriend\x12\x1d\n" +
@@ -1906,7 +5527,18 @@ const file_im_v1_im_proto_rawDesc = "" +
 	"request_id\x18\x01 \x01(\tR\trequestId\"5\n" +
 	"\vCreateGroup\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\"B\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\xc4\x01\n" +
+	"\vFriendEvent\x12+\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x17.im.v1.FriendEvent.KindR\x04kind\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\tR\x02to\"E\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\v\n" +
+	"\aAPPLIED\x10\x01\x12\f\n" +
+	"\bACCEPTED\x10\x02\x12\f\n" +
+	"\bREJECTED\x10\x03\"B\n" +
 	"\rApplyForGroup\x12\x1d\n" +
 	"\n" +
 	"request_id\x18\x01 \x01(\tR\trequestId\x12\x12\n" +
@@ -1916,7 +5548,46 @@ const file_im_v1_im_proto_rawDesc = "" +
 	"request_id\x18\x01 \x01(\tR\trequestId\",\n" +
 	"\vRejectGroup\x12\x1d\n" +
 	"\n" +
-	"request_id\x18\x01 \x01(\tR\trequestId\"Q\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\"Z\n" +
+	"\x11InviteGroupMember\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tR\trequestId\"9\n" +
+	"\x0fKickGroupMember\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\"5\n" +
+	"\vRenameGroup\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"#\n" +
+	"\rDissolveGroup\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\"\\\n" +
+	"\fSetGroupRole\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12$\n" +
+	"\x04role\x18\x03 \x01(\x0e2\x10.im.v1.GroupRoleR\x04role\"\xfe\x02\n" +
+	"\n" +
+	"GroupEvent\x12*\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\x16.im.v1.GroupEvent.KindR\x04kind\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12\x1d\n" +
+	"\n" +
+	"group_uuid\x18\x03 \x01(\tR\tgroupUuid\x12\x12\n" +
+	"\x04user\x18\x04 \x01(\tR\x04user\x12\x1a\n" +
+	"\boperator\x18\x05 \x01(\tR\boperator\x12\x12\n" +
+	"\x04name\x18\x06 \x01(\tR\x04name\x12$\n" +
+	"\x04role\x18\a \x01(\x0e2\x10.im.v1.GroupRoleR\x04role\"\x9b\x01\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fJOIN_APPLIED\x10\x01\x12\x11\n" +
+	"\rJOIN_ACCEPTED\x10\x02\x12\x11\n" +
+	"\rJOIN_REJECTED\x10\x03\x12\v\n" +
+	"\aINVITED\x10\x04\x12\n" +
+	"\n" +
+	"\x06KICKED\x10\x05\x12\v\n" +
+	"\aRENAMED\x10\x06\x12\r\n" +
+	"\tDISSOLVED\x10\a\x12\x10\n" +
+	"\fROLE_CHANGED\x10\b\"Q\n" +
 	"\n" +
 	"ListGroups\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
@@ -1930,32 +5601,206 @@ const file_im_v1_im_proto_rawDesc = "" +
 	"\x06status\x18\x01 \x01(\x05R\x06status\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12/\n" +
 	"\n" +
-	"group_info\x18\x03 \x03(\v2\x10.im.v1.GroupInfoR\tgroupInfo\"%\n" +
+	"group_info\x18\x03 \x03(\v2\x10.im.v1.GroupInfoR\tgroupInfo\"V\n" +
 	"\x0fListGroupMember\x12\x12\n" +
-	"\x04uuid\x18\x01 \x01(\tR\x04uuid\"5\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"[\n" +
 	"\vGroupMember\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\"d\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12$\n" +
+	"\x04role\x18\x03 \x01(\x0e2\x10.im.v1.GroupRoleR\x04role\"d\n" +
 	"\x13ListGroupMemberResp\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\x05R\x06status\x125\n" +
-	"\fgroup_member\x18\x02 \x03(\v2\x12.im.v1.GroupMemberR\vgroupMember\"9\n" +
+	"\fgroup_member\x18\x02 \x03(\v2\x12.im.v1.GroupMemberR\vgroupMember\"\x87\x01\n" +
 	"\rSingleMessage\x12\x0e\n" +
 	"\x02to\x18\x01 \x01(\tR\x02to\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\fR\amessage\"<\n" +
+	"\amessage\x18\x02 \x01(\fR\amessage\x12(\n" +
+	"\acontent\x18\x03 \x01(\v2\x0e.im.v1.ContentR\acontent\x12\"\n" +
+	"\rclient_msg_id\x18\x04 \x01(\tR\vclientMsgId\"\x8a\x01\n" +
 	"\fGroupMessage\x12\x12\n" +
 	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\fR\amessage\"D\n" +
+	"\amessage\x18\x02 \x01(\fR\amessage\x12(\n" +
+	"\acontent\x18\x03 \x01(\v2\x0e.im.v1.ContentR\acontent\x12\"\n" +
+	"\rclient_msg_id\x18\x04 \x01(\tR\vclientMsgId\"\xd0\x02\n" +
+	"\aContent\x12(\n" +
+	"\x04text\x18\x01 \x01(\v2\x12.im.v1.TextContentH\x00R\x04text\x12+\n" +
+	"\x05image\x18\x02 \x01(\v2\x13.im.v1.ImageContentH\x00R\x05image\x12(\n" +
+	"\x04file\x18\x03 \x01(\v2\x12.im.v1.FileContentH\x00R\x04file\x12+\n" +
+	"\x05audio\x18\x04 \x01(\v2\x13.im.v1.AudioContentH\x00R\x05audio\x124\n" +
+	"\blocation\x18\x05 \x01(\v2\x16.im.v1.LocationContentH\x00R\blocation\x12.\n" +
+	"\x06custom\x18\x06 \x01(\v2\x14.im.v1.CustomContentH\x00R\x06custom\x12)\n" +
+	"\breply_to\x18\n" +
+	" \x01(\v2\x0e.im.v1.ReplyToR\areplyToB\x06\n" +
+	"\x04body\"M\n" +
+	"\vTextContent\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12*\n" +
+	"\bmentions\x18\x02 \x03(\v2\x0e.im.v1.MentionR\bmentions\"_\n" +
+	"\aMention\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x05R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x05R\x06length\x12\x10\n" +
+	"\x03all\x18\x04 \x01(\bR\x03all\"j\n" +
+	"\n" +
+	"Attachment\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\"\xa0\x01\n" +
+	"\fImageContent\x121\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x11.im.v1.AttachmentR\n" +
+	"attachment\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x05R\x06height\x12/\n" +
+	"\tthumbnail\x18\x04 \x01(\v2\x11.im.v1.AttachmentR\tthumbnail\"@\n" +
+	"\vFileContent\x121\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x11.im.v1.AttachmentR\n" +
+	"attachment\"b\n" +
+	"\fAudioContent\x121\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x11.im.v1.AttachmentR\n" +
+	"attachment\x12\x1f\n" +
+	"\vduration_ms\x18\x02 \x01(\x05R\n" +
+	"durationMs\"y\n" +
+	"\x0fLocationContent\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\"7\n" +
+	"\rCustomContent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x12\n" +
+	"\x04json\x18\x02 \x01(\tR\x04json\"B\n" +
+	"\aReplyTo\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\x12\x18\n" +
+	"\aexcerpt\x18\x02 \x01(\tR\aexcerpt\"\xd8\x01\n" +
 	"\x14DeliverSingleMessage\x12\x12\n" +
 	"\x04from\x18\x01 \x01(\tR\x04from\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\fR\amessage\"b\n" +
+	"\amessage\x18\x02 \x01(\fR\amessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x03 \x01(\x03R\tmessageId\x12\x10\n" +
+	"\x03seq\x18\x04 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tedited_at\x18\x05 \x01(\x03R\beditedAt\x12\x1a\n" +
+	"\brecalled\x18\x06 \x01(\bR\brecalled\x12(\n" +
+	"\acontent\x18\a \x01(\v2\x0e.im.v1.ContentR\acontent\"\x94\x02\n" +
 	"\x13DeliverGroupMessage\x12\x1d\n" +
 	"\n" +
 	"group_uuid\x18\x01 \x01(\tR\tgroupUuid\x12\x12\n" +
 	"\x04from\x18\x02 \x01(\tR\x04from\x12\x18\n" +
-	"\amessage\x18\x03 \x01(\fR\amessage\"5\n" +
+	"\amessage\x18\x03 \x01(\fR\amessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x04 \x01(\x03R\tmessageId\x12\x10\n" +
+	"\x03seq\x18\x05 \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tedited_at\x18\x06 \x01(\x03R\beditedAt\x12\x1a\n" +
+	"\brecalled\x18\a \x01(\bR\brecalled\x12(\n" +
+	"\acontent\x18\b \x01(\v2\x0e.im.v1.ContentR\acontent\x12\x1c\n" +
+	"\tmentioned\x18\t \x01(\bR\tmentioned\",\n" +
+	"\vDeliveryAck\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\"5\n" +
 	"\x05Error\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage*\xa3\x02\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xb4\x01\n" +
+	"\x06Kicked\x12,\n" +
+	"\x06reason\x18\x01 \x01(\x0e2\x14.im.v1.Kicked.ReasonR\x06reason\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x1a\n" +
+	"\bplatform\x18\x03 \x01(\tR\bplatform\"C\n" +
+	"\x06Reason\x12\x16\n" +
+	"\x12REASON_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vSAME_DEVICE\x10\x01\x12\x10\n" +
+	"\fOTHER_DEVICE\x10\x02\"\xd2\x01\n" +
+	"\vPullHistory\x12\x12\n" +
+	"\x04peer\x18\x01 \x01(\tR\x04peer\x12\x1d\n" +
+	"\n" +
+	"group_uuid\x18\x02 \x01(\tR\tgroupUuid\x12\x16\n" +
+	"\x06cursor\x18\x03 \x01(\x03R\x06cursor\x12:\n" +
+	"\tdirection\x18\x04 \x01(\x0e2\x1c.im.v1.PullHistory.DirectionR\tdirection\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"&\n" +
+	"\tDirection\x12\f\n" +
+	"\bBACKWARD\x10\x00\x12\v\n" +
+	"\aFORWARD\x10\x01\"\xa0\x02\n" +
+	"\x0eHistoryMessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x1d\n" +
+	"\n" +
+	"group_uuid\x18\x04 \x01(\tR\tgroupUuid\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\fR\amessage\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x10\n" +
+	"\x03seq\x18\a \x01(\x03R\x03seq\x12\x1b\n" +
+	"\tedited_at\x18\b \x01(\x03R\beditedAt\x12\x1a\n" +
+	"\brecalled\x18\t \x01(\bR\brecalled\x12(\n" +
+	"\acontent\x18\n" +
+	" \x01(\v2\x0e.im.v1.ContentR\acontent\"|\n" +
+	"\vHistoryResp\x121\n" +
+	"\bmessages\x18\x01 \x03(\v2\x15.im.v1.HistoryMessageR\bmessages\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\x03R\n" +
+	"nextCursor\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"\x81\x01\n" +
+	"\x04Sync\x12\x12\n" +
+	"\x04peer\x18\x01 \x01(\tR\x04peer\x12\x1d\n" +
+	"\n" +
+	"group_uuid\x18\x02 \x01(\tR\tgroupUuid\x12\x19\n" +
+	"\bfrom_seq\x18\x03 \x01(\x03R\afromSeq\x12\x15\n" +
+	"\x06to_seq\x18\x04 \x01(\x03R\x05toSeq\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"s\n" +
+	"\bSyncResp\x121\n" +
+	"\bmessages\x18\x01 \x03(\v2\x15.im.v1.HistoryMessageR\bmessages\x12\x19\n" +
+	"\blast_seq\x18\x02 \x01(\x03R\alastSeq\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"A\n" +
+	"\fConversation\x12\x12\n" +
+	"\x04peer\x18\x01 \x01(\tR\x04peer\x12\x1d\n" +
+	"\n" +
+	"group_uuid\x18\x02 \x01(\tR\tgroupUuid\"U\n" +
+	"\bMarkRead\x127\n" +
+	"\fconversation\x18\x01 \x01(\v2\x13.im.v1.ConversationR\fconversation\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x03R\x03seq\"p\n" +
+	"\vReadReceipt\x12\x16\n" +
+	"\x06reader\x18\x01 \x01(\tR\x06reader\x127\n" +
+	"\fconversation\x18\x02 \x01(\v2\x13.im.v1.ConversationR\fconversation\x12\x10\n" +
+	"\x03seq\x18\x03 \x01(\x03R\x03seq\"F\n" +
+	"\tGetUnread\x129\n" +
+	"\rconversations\x18\x01 \x03(\v2\x13.im.v1.ConversationR\rconversations\"\x94\x01\n" +
+	"\vUnreadCount\x127\n" +
+	"\fconversation\x18\x01 \x01(\v2\x13.im.v1.ConversationR\fconversation\x12\x16\n" +
+	"\x06unread\x18\x02 \x01(\x03R\x06unread\x12\x19\n" +
+	"\bread_seq\x18\x03 \x01(\x03R\areadSeq\x12\x19\n" +
+	"\blast_seq\x18\x04 \x01(\x03R\alastSeq\"8\n" +
+	"\n" +
+	"UnreadResp\x12*\n" +
+	"\x06counts\x18\x01 \x03(\v2\x12.im.v1.UnreadCountR\x06counts\"A\n" +
+	"\x06Typing\x127\n" +
+	"\fconversation\x18\x01 \x01(\v2\x13.im.v1.ConversationR\fconversation\"r\n" +
+	"\vTypingEvent\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x127\n" +
+	"\fconversation\x18\x02 \x01(\v2\x13.im.v1.ConversationR\fconversation\x12\x16\n" +
+	"\x06typing\x18\x03 \x01(\bR\x06typing\"K\n" +
+	"\rPresenceEvent\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12\x16\n" +
+	"\x06online\x18\x02 \x01(\bR\x06online\x12\x0e\n" +
+	"\x02at\x18\x03 \x01(\x03R\x02at\".\n" +
+	"\rRecallMessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\"p\n" +
+	"\vEditMessage\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\x03R\tmessageId\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\fR\amessage\x12(\n" +
+	"\acontent\x18\x03 \x01(\v2\x0e.im.v1.ContentR\acontent\"\x11\n" +
+	"\x0fCreateUploadUrl\",\n" +
+	"\x11CreateDownloadUrl\x12\x17\n" +
+	"\ablob_id\x18\x01 \x01(\tR\x06blobId\":\n" +
+	"\aBlobUrl\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x02 \x01(\x03R\texpiresAt\"N\n" +
+	"\x0eSetGroupNotify\x12\x12\n" +
+	"\x04uuid\x18\x01 \x01(\tR\x04uuid\x12(\n" +
+	"\x05level\x18\x02 \x01(\x0e2\x12.im.v1.GroupNotifyR\x05level*\xfc\x04\n" +
 	"\vMessageType\x12\x0f\n" +
 	"\vUNSPECIFIED\x10\x00\x12\b\n" +
 	"\x04ECHO\x10\x01\x12\f\n" +
@@ -1974,11 +5819,39 @@ const file_im_v1_im_proto_rawDesc = "" +
 	"\vLIST_GROUPS\x10\r\x12\x15\n" +
 	"\x11LIST_GROUP_MEMBER\x10\x0e\x12\x12\n" +
 	"\x0eSINGLE_MESSAGE\x10\x0f\x12\x11\n" +
-	"\rGROUP_MESSAGE\x10\x10*.\n" +
+	"\rGROUP_MESSAGE\x10\x10\x12\x10\n" +
+	"\fDELIVERY_ACK\x10\x11\x12\x17\n" +
+	"\x13INVITE_GROUP_MEMBER\x10\x12\x12\x15\n" +
+	"\x11KICK_GROUP_MEMBER\x10\x13\x12\x10\n" +
+	"\fRENAME_GROUP\x10\x14\x12\x12\n" +
+	"\x0eDISSOLVE_GROUP\x10\x15\x12\x12\n" +
+	"\x0eSET_GROUP_ROLE\x10\x16\x12\x11\n" +
+	"\rREFRESH_TOKEN\x10\x17\x12\x10\n" +
+	"\fPULL_HISTORY\x10\x18\x12\b\n" +
+	"\x04SYNC\x10\x19\x12\r\n" +
+	"\tMARK_READ\x10\x1a\x12\x0e\n" +
+	"\n" +
+	"GET_UNREAD\x10\x1b\x12\x10\n" +
+	"\fTYPING_START\x10\x1c\x12\x0f\n" +
+	"\vTYPING_STOP\x10\x1d\x12\x12\n" +
+	"\x0eRECALL_MESSAGE\x10\x1e\x12\x10\n" +
+	"\fEDIT_MESSAGE\x10\x1f\x12\x15\n" +
+	"\x11CREATE_UPLOAD_URL\x10 \x12\x17\n" +
+	"\x13CREATE_DOWNLOAD_URL\x10!\x12\x14\n" +
+	"\x10SET_GROUP_NOTIFY\x10\"*.\n" +
 	"\tLoginType\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\t\n" +
 	"\x05PHONE\x10\x01\x12\t\n" +
-	"\x05EMAIL\x10\x02B4Z2github.com/yourusername/im-server/proto/im/v1;imv1b\x06proto3"
+	"\x05EMAIL\x10\x02*j\n" +
+	"\tGroupRole\x12\x1a\n" +
+	"\x16GROUP_ROLE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11GROUP_ROLE_MEMBER\x10\x01\x12\x14\n" +
+	"\x10GROUP_ROLE_ADMIN\x10\x02\x12\x14\n" +
+	"\x10GROUP_ROLE_OWNER\x10\x03*U\n" +
+	"\vGroupNotify\x12\x14\n" +
+	"\x10GROUP_NOTIFY_ALL\x10\x00\x12\x19\n" +
+	"\x15GROUP_NOTIFY_MENTIONS\x10\x01\x12\x15\n" +
+	"\x11GROUP_NOTIFY_NONE\x10\x02B4Z2github.com/yourusername/im-server/proto/im/v1;imv1b\x06proto3"
 
 var (
 	file_im_v1_im_proto_rawDescOnce sync.Once
@@ -1992,69 +5865,179 @@ func file_im_v1_im_proto_rawDescGZIP() []byte {
 	return file_im_v1_im_proto_rawDescData
 }
 
-var file_im_v1_im_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_im_v1_im_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
+var file_im_v1_im_proto_enumTypes = make([]protoimpl.EnumInfo, 8)
+var file_im_v1_im_proto_msgTypes = make([]protoimpl.MessageInfo, 66)
 var file_im_v1_im_proto_goTypes = []any{
 	(MessageType)(0),             // 0: im.v1.MessageType
 	(LoginType)(0),               // 1: im.v1.LoginType
-	(*ClientEnvelope)(nil),       // 2: im.v1.ClientEnvelope
-	(*ServerEnvelope)(nil),       // 3: im.v1.ServerEnvelope
-	(*Echo)(nil),                 // 4: im.v1.Echo
-	(*Register)(nil),             // 5: im.v1.Register
-	(*AckResp)(nil),              // 6: im.v1.AckResp
-	(*Login)(nil),                // 7: im.v1.Login
-	(*Logout)(nil),               // 8: im.v1.Logout
-	(*ApplyForFriend)(nil),       // 9: im.v1.ApplyForFriend
-	(*AcceptFriend)(nil),         // 10: im.v1.AcceptFriend
-	(*RejectFriend)(nil),         // 11: im.v1.RejectFriend
-	(*CreateGroup)(nil),          // 12: im.v1.CreateGroup
-	(*ApplyForGroup)(nil),        // 13: im.v1.ApplyForGroup
-	(*AcceptGroup)(nil),          // 14: im.v1.AcceptGroup
-	(*RejectGroup)(nil),          // 15: im.v1.RejectGroup
-	(*ListGroups)(nil),           // 16: im.v1.ListGroups
-	(*GroupInfo)(nil),            // 17: im.v1.GroupInfo
-	(*ListGroupsResp)(nil),       // 18: im.v1.ListGroupsResp
-	(*ListGroupMember)(nil),      // 19: im.v1.ListGroupMember
-	(*GroupMember)(nil),          // 20: im.v1.GroupMember
-	(*ListGroupMemberResp)(nil),  // 21: im.v1.ListGroupMemberResp
-	(*SingleMessage)(nil),        // 22: im.v1.SingleMessage
-	(*GroupMessage)(nil),         // 23: im.v1.GroupMessage
-	(*DeliverSingleMessage)(nil), // 24: im.v1.DeliverSingleMessage
-	(*DeliverGroupMessage)(nil),  // 25: im.v1.DeliverGroupMessage
-	(*Error)(nil),                // 26: im.v1.Error
+	(GroupRole)(0),               // 2: im.v1.GroupRole
+	(GroupNotify)(0),             // 3: im.v1.GroupNotify
+	(FriendEvent_Kind)(0),        // 4: im.v1.FriendEvent.Kind
+	(GroupEvent_Kind)(0),         // 5: im.v1.GroupEvent.Kind
+	(Kicked_Reason)(0),           // 6: im.v1.Kicked.Reason
+	(PullHistory_Direction)(0),   // 7: im.v1.PullHistory.Direction
+	(*ClientEnvelope)(nil),       // 8: im.v1.ClientEnvelope
+	(*ServerEnvelope)(nil),       // 9: im.v1.ServerEnvelope
+	(*Echo)(nil),                 // 10: im.v1.Echo
+	(*Register)(nil),             // 11: im.v1.Register
+	(*AckResp)(nil),              // 12: im.v1.AckResp
+	(*Login)(nil),                // 13: im.v1.Login
+	(*RefreshToken)(nil),         // 14: im.v1.RefreshToken
+	(*LoginResp)(nil),            // 15: im.v1.LoginResp
+	(*Logout)(nil),               // 16: im.v1.Logout
+	(*ApplyForFriend)(nil),       // 17: im.v1.ApplyForFriend
+	(*AcceptFriend)(nil),         // 18: im.v1.AcceptFriend
+	(*RejectFriend)(nil),         // 19: im.v1.RejectFriend
+	(*CreateGroup)(nil),          // 20: im.v1.CreateGroup
+	(*FriendEvent)(nil),          // 21: im.v1.FriendEvent
+	(*ApplyForGroup)(nil),        // 22: im.v1.ApplyForGroup
+	(*AcceptGroup)(nil),          // 23: im.v1.AcceptGroup
+	(*RejectGroup)(nil),          // 24: im.v1.RejectGroup
+	(*InviteGroupMember)(nil),    // 25: im.v1.InviteGroupMember
+	(*KickGroupMember)(nil),      // 26: im.v1.KickGroupMember
+	(*RenameGroup)(nil),          // 27: im.v1.RenameGroup
+	(*DissolveGroup)(nil),        // 28: im.v1.DissolveGroup
+	(*SetGroupRole)(nil),         // 29: im.v1.SetGroupRole
+	(*GroupEvent)(nil),           // 30: im.v1.GroupEvent
+	(*ListGroups)(nil),           // 31: im.v1.ListGroups
+	(*GroupInfo)(nil),            // 32: im.v1.GroupInfo
+	(*ListGroupsResp)(nil),       // 33: im.v1.ListGroupsResp
+	(*ListGroupMember)(nil),      // 34: im.v1.ListGroupMember
+	(*GroupMember)(nil),          // 35: im.v1.GroupMember
+	(*ListGroupMemberResp)(nil),  // 36: im.v1.ListGroupMemberResp
+	(*SingleMessage)(nil),        // 37: im.v1.SingleMessage
+	(*GroupMessage)(nil),         // 38: im.v1.GroupMessage
+	(*Content)(nil),              // 39: im.v1.Content
+	(*TextContent)(nil),          // 40: im.v1.TextContent
+	(*Mention)(nil),              // 41: im.v1.Mention
+	(*Attachment)(nil),           // 42: im.v1.Attachment
+	(*ImageContent)(nil),         // 43: im.v1.ImageContent
+	(*FileContent)(nil),          // 44: im.v1.FileContent
+	(*AudioContent)(nil),         // 45: im.v1.AudioContent
+	(*LocationContent)(nil),      // 46: im.v1.LocationContent
+	(*CustomContent)(nil),        // 47: im.v1.CustomContent
+	(*ReplyTo)(nil),              // 48: im.v1.ReplyTo
+	(*DeliverSingleMessage)(nil), // 49: im.v1.DeliverSingleMessage
+	(*DeliverGroupMessage)(nil),  // 50: im.v1.DeliverGroupMessage
+	(*DeliveryAck)(nil),          // 51: im.v1.DeliveryAck
+	(*Error)(nil),                // 52: im.v1.Error
+	(*Kicked)(nil),               // 53: im.v1.Kicked
+	(*PullHistory)(nil),          // 54: im.v1.PullHistory
+	(*HistoryMessage)(nil),       // 55: im.v1.HistoryMessage
+	(*HistoryResp)(nil),          // 56: im.v1.HistoryResp
+	(*Sync)(nil),                 // 57: im.v1.Sync
+	(*SyncResp)(nil),             // 58: im.v1.SyncResp
+	(*Conversation)(nil),         // 59: im.v1.Conversation
+	(*MarkRead)(nil),             // 60: im.v1.MarkRead
+	(*ReadReceipt)(nil),          // 61: im.v1.ReadReceipt
+	(*GetUnread)(nil),            // 62: im.v1.GetUnread
+	(*UnreadCount)(nil),          // 63: im.v1.UnreadCount
+	(*UnreadResp)(nil),           // 64: im.v1.UnreadResp
+	(*Typing)(nil),               // 65: im.v1.Typing
+	(*TypingEvent)(nil),          // 66: im.v1.TypingEvent
+	(*PresenceEvent)(nil),        // 67: im.v1.PresenceEvent
+	(*RecallMessage)(nil),        // 68: im.v1.RecallMessage
+	(*EditMessage)(nil),          // 69: im.v1.EditMessage
+	(*CreateUploadUrl)(nil),      // 70: im.v1.CreateUploadUrl
+	(*CreateDownloadUrl)(nil),    // 71: im.v1.CreateDownloadUrl
+	(*BlobUrl)(nil),              // 72: im.v1.BlobUrl
+	(*SetGroupNotify)(nil),       // 73: im.v1.SetGroupNotify
 }
 var file_im_v1_im_proto_depIdxs = []int32{
 	0,  // 0: im.v1.ClientEnvelope.type:type_name -> im.v1.MessageType
-	4,  // 1: im.v1.ClientEnvelope.echo:type_name -> im.v1.Echo
-	5,  // 2: im.v1.ClientEnvelope.register:type_name -> im.v1.Register
-	7,  // 3: im.v1.ClientEnvelope.login:type_name -> im.v1.Login
-	8,  // 4: im.v1.ClientEnvelope.logout:type_name -> im.v1.Logout
-	9,  // 5: im.v1.ClientEnvelope.apply_for_friend:type_name -> im.v1.ApplyForFriend
-	10, // 6: im.v1.ClientEnvelope.accept_friend:type_name -> im.v1.AcceptFriend
-	11, // 7: im.v1.ClientEnvelope.reject_friend:type_name -> im.v1.RejectFriend
-	12, // 8: im.v1.ClientEnvelope.create_group:type_name -> im.v1.CreateGroup
-	13, // 9: im.v1.ClientEnvelope.apply_for_group:type_name -> im.v1.ApplyForGroup
-	14, // 10: im.v1.ClientEnvelope.accept_group:type_name -> im.v1.AcceptGroup
-	15, // 11: im.v1.ClientEnvelope.reject_group:type_name -> im.v1.RejectGroup
-	16, // 12: im.v1.ClientEnvelope.list_groups:type_name -> im.v1.ListGroups
-	19, // 13: im.v1.ClientEnvelope.list_group_memeber:type_name -> im.v1.ListGroupMember
-	22, // 14: im.v1.ClientEnvelope.single_message:type_name -> im.v1.SingleMessage
-	23, // 15: im.v1.ClientEnvelope.group_message:type_name -> im.v1.GroupMessage
-	4,  // 16: im.v1.ServerEnvelope.echo:type_name -> im.v1.Echo
-	6,  // 17: im.v1.ServerEnvelope.ack_resp:type_name -> im.v1.AckResp
-	18, // 18: im.v1.ServerEnvelope.list_groups_resp:type_name -> im.v1.ListGroupsResp
-	21, // 19: im.v1.ServerEnvelope.list_group_memeber_resp:type_name -> im.v1.ListGroupMemberResp
-	24, // 20: im.v1.ServerEnvelope.deliver_single_message:type_name -> im.v1.DeliverSingleMessage
-	25, // 21: im.v1.ServerEnvelope.deliver_group_message:type_name -> im.v1.DeliverGroupMessage
-	26, // 22: im.v1.ServerEnvelope.error:type_name -> im.v1.Error
-	1,  // 23: im.v1.Login.type:type_name -> im.v1.LoginType
-	17, // 24: im.v1.ListGroupsResp.group_info:type_name -> im.v1.GroupInfo
-	20, // 25: im.v1.ListGroupMemberResp.group_member:type_name -> im.v1.GroupMember
-	26, // [26:26] is the sub-list for method output_type
-	26, // [26:26] is the sub-list for method input_type
-	26, // [26:26] is the sub-list for extension type_name
-	26, // [26:26] is the sub-list for extension extendee
-	0,  // [0:26] is the sub-list for field type_name
+	10, // 1: im.v1.ClientEnvelope.echo:type_name -> im.v1.Echo
+	11, // 2: im.v1.ClientEnvelope.register:type_name -> im.v1.Register
+	13, // 3: im.v1.ClientEnvelope.login:type_name -> im.v1.Login
+	16, // 4: im.v1.ClientEnvelope.logout:type_name -> im.v1.Logout
+	17, // 5: im.v1.ClientEnvelope.apply_for_friend:type_name -> im.v1.ApplyForFriend
+	18, // 6: im.v1.ClientEnvelope.accept_friend:type_name -> im.v1.AcceptFriend
+	19, // 7: im.v1.ClientEnvelope.reject_friend:type_name -> im.v1.RejectFriend
+	20, // 8: im.v1.ClientEnvelope.create_group:type_name -> im.v1.CreateGroup
+	22, // 9: im.v1.ClientEnvelope.apply_for_group:type_name -> im.v1.ApplyForGroup
+	23, // 10: im.v1.ClientEnvelope.accept_group:type_name -> im.v1.AcceptGroup
+	24, // 11: im.v1.ClientEnvelope.reject_group:type_name -> im.v1.RejectGroup
+	31, // 12: im.v1.ClientEnvelope.list_groups:type_name -> im.v1.ListGroups
+	34, // 13: im.v1.ClientEnvelope.list_group_memeber:type_name -> im.v1.ListGroupMember
+	37, // 14: im.v1.ClientEnvelope.single_message:type_name -> im.v1.SingleMessage
+	38, // 15: im.v1.ClientEnvelope.group_message:type_name -> im.v1.GroupMessage
+	51, // 16: im.v1.ClientEnvelope.delivery_ack:type_name -> im.v1.DeliveryAck
+	25, // 17: im.v1.ClientEnvelope.invite_group_member:type_name -> im.v1.InviteGroupMember
+	26, // 18: im.v1.ClientEnvelope.kick_group_member:type_name -> im.v1.KickGroupMember
+	27, // 19: im.v1.ClientEnvelope.rename_group:type_name -> im.v1.RenameGroup
+	28, // 20: im.v1.ClientEnvelope.dissolve_group:type_name -> im.v1.DissolveGroup
+	29, // 21: im.v1.ClientEnvelope.set_group_role:type_name -> im.v1.SetGroupRole
+	14, // 22: im.v1.ClientEnvelope.refresh_token:type_name -> im.v1.RefreshToken
+	54, // 23: im.v1.ClientEnvelope.pull_history:type_name -> im.v1.PullHistory
+	57, // 24: im.v1.ClientEnvelope.sync:type_name -> im.v1.Sync
+	60, // 25: im.v1.ClientEnvelope.mark_read:type_name -> im.v1.MarkRead
+	62, // 26: im.v1.ClientEnvelope.get_unread:type_name -> im.v1.GetUnread
+	65, // 27: im.v1.ClientEnvelope.typing:type_name -> im.v1.Typing
+	68, // 28: im.v1.ClientEnvelope.recall_message:type_name -> im.v1.RecallMessage
+	69, // 29: im.v1.ClientEnvelope.edit_message:type_name -> im.v1.EditMessage
+	70, // 30: im.v1.ClientEnvelope.create_upload_url:type_name -> im.v1.CreateUploadUrl
+	71, // 31: im.v1.ClientEnvelope.create_download_url:type_name -> im.v1.CreateDownloadUrl
+	73, // 32: im.v1.ClientEnvelope.set_group_notify:type_name -> im.v1.SetGroupNotify
+	10, // 33: im.v1.ServerEnvelope.echo:type_name -> im.v1.Echo
+	12, // 34: im.v1.ServerEnvelope.ack_resp:type_name -> im.v1.AckResp
+	15, // 35: im.v1.ServerEnvelope.login_resp:type_name -> im.v1.LoginResp
+	53, // 36: im.v1.ServerEnvelope.kicked:type_name -> im.v1.Kicked
+	56, // 37: im.v1.ServerEnvelope.history_resp:type_name -> im.v1.HistoryResp
+	58, // 38: im.v1.ServerEnvelope.sync_resp:type_name -> im.v1.SyncResp
+	61, // 39: im.v1.ServerEnvelope.read_receipt:type_name -> im.v1.ReadReceipt
+	64, // 40: im.v1.ServerEnvelope.unread_resp:type_name -> im.v1.UnreadResp
+	66, // 41: im.v1.ServerEnvelope.typing_event:type_name -> im.v1.TypingEvent
+	67, // 42: im.v1.ServerEnvelope.presence_event:type_name -> im.v1.PresenceEvent
+	72, // 43: im.v1.ServerEnvelope.blob_url:type_name -> im.v1.BlobUrl
+	33, // 44: im.v1.ServerEnvelope.list_groups_resp:type_name -> im.v1.ListGroupsResp
+	36, // 45: im.v1.ServerEnvelope.list_group_memeber_resp:type_name -> im.v1.ListGroupMemberResp
+	21, // 46: im.v1.ServerEnvelope.friend_event:type_name -> im.v1.FriendEvent
+	30, // 47: im.v1.ServerEnvelope.group_event:type_name -> im.v1.GroupEvent
+	49, // 48: im.v1.ServerEnvelope.deliver_single_message:type_name -> im.v1.DeliverSingleMessage
+	50, // 49: im.v1.ServerEnvelope.deliver_group_message:type_name -> im.v1.DeliverGroupMessage
+	52, // 50: im.v1.ServerEnvelope.error:type_name -> im.v1.Error
+	1,  // 51: im.v1.Login.type:type_name -> im.v1.LoginType
+	4,  // 52: im.v1.FriendEvent.kind:type_name -> im.v1.FriendEvent.Kind
+	2,  // 53: im.v1.SetGroupRole.role:type_name -> im.v1.GroupRole
+	5,  // 54: im.v1.GroupEvent.kind:type_name -> im.v1.GroupEvent.Kind
+	2,  // 55: im.v1.GroupEvent.role:type_name -> im.v1.GroupRole
+	32, // 56: im.v1.ListGroupsResp.group_info:type_name -> im.v1.GroupInfo
+	2,  // 57: im.v1.GroupMember.role:type_name -> im.v1.GroupRole
+	35, // 58: im.v1.ListGroupMemberResp.group_member:type_name -> im.v1.GroupMember
+	39, // 59: im.v1.SingleMessage.content:type_name -> im.v1.Content
+	39, // 60: im.v1.GroupMessage.content:type_name -> im.v1.Content
+	40, // 61: im.v1.Content.text:type_name -> im.v1.TextContent
+	43, // 62: im.v1.Content.image:type_name -> im.v1.ImageContent
+	44, // 63: im.v1.Content.file:type_name -> im.v1.FileContent
+	45, // 64: im.v1.Content.audio:type_name -> im.v1.AudioContent
+	46, // 65: im.v1.Content.location:type_name -> im.v1.LocationContent
+	47, // 66: im.v1.Content.custom:type_name -> im.v1.CustomContent
+	48, // 67: im.v1.Content.reply_to:type_name -> im.v1.ReplyTo
+	41, // 68: im.v1.TextContent.mentions:type_name -> im.v1.Mention
+	42, // 69: im.v1.ImageContent.attachment:type_name -> im.v1.Attachment
+	42, // 70: im.v1.ImageContent.thumbnail:type_name -> im.v1.Attachment
+	42, // 71: im.v1.FileContent.attachment:type_name -> im.v1.Attachment
+	42, // 72: im.v1.AudioContent.attachment:type_name -> im.v1.Attachment
+	39, // 73: im.v1.DeliverSingleMessage.content:type_name -> im.v1.Content
+	39, // 74: im.v1.DeliverGroupMessage.content:type_name -> im.v1.Content
+	6,  // 75: im.v1.Kicked.reason:type_name -> im.v1.Kicked.Reason
+	7,  // 76: im.v1.PullHistory.direction:type_name -> im.v1.PullHistory.Direction
+	39, // 77: im.v1.HistoryMessage.content:type_name -> im.v1.Content
+	55, // 78: im.v1.HistoryResp.messages:type_name -> im.v1.HistoryMessage
+	55, // 79: im.v1.SyncResp.messages:type_name -> im.v1.HistoryMessage
+	59, // 80: im.v1.MarkRead.conversation:type_name -> im.v1.Conversation
+	59, // 81: im.v1.ReadReceipt.conversation:type_name -> im.v1.Conversation
+	59, // 82: im.v1.GetUnread.conversations:type_name -> im.v1.Conversation
+	59, // 83: im.v1.UnreadCount.conversation:type_name -> im.v1.Conversation
+	63, // 84: im.v1.UnreadResp.counts:type_name -> im.v1.UnreadCount
+	59, // 85: im.v1.Typing.conversation:type_name -> im.v1.Conversation
+	59, // 86: im.v1.TypingEvent.conversation:type_name -> im.v1.Conversation
+	39, // 87: im.v1.EditMessage.content:type_name -> im.v1.Content
+	3,  // 88: im.v1.SetGroupNotify.level:type_name -> im.v1.GroupNotify
+	89, // [89:89] is the sub-list for method output_type
+	89, // [89:89] is the sub-list for method input_type
+	89, // [89:89] is the sub-list for extension type_name
+	89, // [89:89] is the sub-list for extension extendee
+	0,  // [0:89] is the sub-list for field type_name
 }
 
 func init() { file_im_v1_im_proto_init() }
@@ -2078,23 +6061,59 @@ func file_im_v1_im_proto_init() {
 		(*ClientEnvelope_ListGroupMemeber)(nil),
 		(*ClientEnvelope_SingleMessage)(nil),
 		(*ClientEnvelope_GroupMessage)(nil),
+		(*ClientEnvelope_DeliveryAck)(nil),
+		(*ClientEnvelope_InviteGroupMember)(nil),
+		(*ClientEnvelope_KickGroupMember)(nil),
+		(*ClientEnvelope_RenameGroup)(nil),
+		(*ClientEnvelope_DissolveGroup)(nil),
+		(*ClientEnvelope_SetGroupRole)(nil),
+		(*ClientEnvelope_RefreshToken)(nil),
+		(*ClientEnvelope_PullHistory)(nil),
+		(*ClientEnvelope_Sync)(nil),
+		(*ClientEnvelope_MarkRead)(nil),
+		(*ClientEnvelope_GetUnread)(nil),
+		(*ClientEnvelope_Typing)(nil),
+		(*ClientEnvelope_RecallMessage)(nil),
+		(*ClientEnvelope_EditMessage)(nil),
+		(*ClientEnvelope_CreateUploadUrl)(nil),
+		(*ClientEnvelope_CreateDownloadUrl)(nil),
+		(*ClientEnvelope_SetGroupNotify)(nil),
 	}
 	file_im_v1_im_proto_msgTypes[1].OneofWrappers = []any{
 		(*ServerEnvelope_Echo)(nil),
 		(*ServerEnvelope_AckResp)(nil),
+		(*ServerEnvelope_LoginResp)(nil),
+		(*ServerEnvelope_Kicked)(nil),
+		(*ServerEnvelope_HistoryResp)(nil),
+		(*ServerEnvelope_SyncResp)(nil),
+		(*ServerEnvelope_ReadReceipt)(nil),
+		(*ServerEnvelope_UnreadResp)(nil),
+		(*ServerEnvelope_TypingEvent)(nil),
+		(*ServerEnvelope_PresenceEvent)(nil),
+		(*ServerEnvelope_BlobUrl)(nil),
 		(*ServerEnvelope_ListGroupsResp)(nil),
 		(*ServerEnvelope_ListGroupMemeberResp)(nil),
+		(*ServerEnvelope_FriendEvent)(nil),
+		(*ServerEnvelope_GroupEvent)(nil),
 		(*ServerEnvelope_DeliverSingleMessage)(nil),
 		(*ServerEnvelope_DeliverGroupMessage)(nil),
 		(*ServerEnvelope_Error)(nil),
 	}
+	file_im_v1_im_proto_msgTypes[31].OneofWrappers = []any{
+		(*Content_Text)(nil),
+		(*Content_Image)(nil),
+		(*Content_File)(nil),
+		(*Content_Audio)(nil),
+		(*Content_Location)(nil),
+		(*Content_Custom)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_im_v1_im_proto_rawDesc), len(file_im_v1_im_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   25,
+			NumEnums:      8,
+			NumMessages:   66,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
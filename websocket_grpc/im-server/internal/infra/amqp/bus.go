@@ -0,0 +1,277 @@
+// Package amqp implements the inter-node bus on top of RabbitMQ.
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// exchange routes deliveries by node ID to the per-node queues.
+	exchange    = "im.deliveries"
+	queuePrefix = "im.node."
+
+	minReconnectBackoff = 500 * time.Millisecond
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// ErrDisconnected is returned while the connection to the broker is down.
+// Deliveries published meanwhile are lost; the inbox covers them.
+var ErrDisconnected = errors.New("amqp bus is disconnected")
+
+var errClosed = errors.New("amqp bus is closed")
+
+type subscription struct {
+	ctx     context.Context
+	nodeID  string
+	handler func(context.Context, *cluster.Delivery)
+}
+
+// bus holds one connection to the broker. When it drops, the bus redials
+// with backoff and sets the exchange, the publisher and every subscription
+// up again on the new connection.
+type bus struct {
+	url string
+	log *zap.Logger
+
+	mu   sync.Mutex
+	conn *amqp.Connection // nil while reconnecting
+	pub  *amqp.Channel
+	subs []*subscription
+	// closed is closed by Close and stops reconnecting.
+	closed chan struct{}
+
+	// amqp channels must not be shared between a publisher and a consumer,
+	// nor used by two publishers at once.
+	pubMu sync.Mutex
+}
+
+func NewBus(url string, log *zap.Logger) (cluster.Bus, error) {
+	b := &bus{url: url, log: log, closed: make(chan struct{})}
+	conn, pub, err := connect(url)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.install(conn, pub); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// connect dials the broker and opens the publisher channel.
+func connect(url string) (*amqp.Connection, *amqp.Channel, error) {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial amqp: %w", err)
+	}
+	pub, err := conn.Channel()
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("open amqp channel: %w", err)
+	}
+	if err := pub.ExchangeDeclare(exchange, amqp.ExchangeDirect, true, false, false, false, nil); err != nil {
+		_ = conn.Close()
+		return nil, nil, fmt.Errorf("declare exchange: %w", err)
+	}
+	return conn, pub, nil
+}
+
+// install resumes the live subscriptions on conn and makes it the current
+// connection.
+func (b *bus) install(conn *amqp.Connection, pub *amqp.Channel) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.closed:
+		return errClosed
+	default:
+	}
+
+	live := b.subs[:0]
+	for _, s := range b.subs {
+		if s.ctx.Err() == nil {
+			live = append(live, s)
+		}
+	}
+	b.subs = live
+	for _, s := range b.subs {
+		if err := b.consume(conn, s); err != nil {
+			return err
+		}
+	}
+	b.conn, b.pub = conn, pub
+	go b.watch(conn)
+	return nil
+}
+
+// watch waits for conn to drop and reconnects.
+func (b *bus) watch(conn *amqp.Connection) {
+	// The channel is closed right away if conn is already gone.
+	lost := conn.NotifyClose(make(chan *amqp.Error, 1))
+	var err *amqp.Error
+	select {
+	case <-b.closed:
+		return
+	case err = <-lost:
+	}
+
+	b.mu.Lock()
+	if b.conn == conn {
+		b.conn, b.pub = nil, nil
+	}
+	b.mu.Unlock()
+	b.log.Warn("amqp connection lost, reconnecting", zap.Error(err))
+	b.reconnect()
+}
+
+func (b *bus) reconnect() {
+	backoff := minReconnectBackoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-b.closed:
+			return
+		case <-time.After(backoff):
+		}
+		conn, pub, err := connect(b.url)
+		if err == nil {
+			if err = b.install(conn, pub); err != nil {
+				_ = conn.Close()
+			}
+		}
+		switch {
+		case err == nil:
+			b.log.Info("amqp connection restored", zap.Int("attempt", attempt))
+			return
+		case errors.Is(err, errClosed):
+			return
+		}
+		b.log.Warn("amqp reconnect failed",
+			zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+		backoff = min(backoff*2, maxReconnectBackoff)
+	}
+}
+
+func (b *bus) Publish(ctx context.Context, nodeID string, d *cluster.Delivery) error {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	pub := b.pub
+	b.mu.Unlock()
+	if pub == nil {
+		return ErrDisconnected
+	}
+	b.pubMu.Lock()
+	defer b.pubMu.Unlock()
+	return pub.PublishWithContext(ctx, exchange, nodeID, false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+	})
+}
+
+// Subscribe consumes the deliveries to nodeID until ctx is done, across
+// reconnects. It fails if the broker is unreachable when it is called.
+func (b *bus) Subscribe(ctx context.Context, nodeID string, handler func(context.Context, *cluster.Delivery)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil {
+		return ErrDisconnected
+	}
+	s := &subscription{ctx: ctx, nodeID: nodeID, handler: handler}
+	if err := b.consume(b.conn, s); err != nil {
+		return err
+	}
+	b.subs = append(b.subs, s)
+	return nil
+}
+
+// consume declares the queue of s on conn and hands its deliveries to the
+// handler until ctx is done or the channel closes.
+func (b *bus) consume(conn *amqp.Connection, s *subscription) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("open amqp channel: %w", err)
+	}
+	// The queue outlives the node so forwards published during a restart are
+	// still consumed; the inbox covers anything older.
+	q, err := ch.QueueDeclare(queuePrefix+s.nodeID, true, false, false, false, nil)
+	if err != nil {
+		_ = ch.Close()
+		return fmt.Errorf("declare queue: %w", err)
+	}
+	if err := ch.QueueBind(q.Name, s.nodeID, exchange, false, nil); err != nil {
+		_ = ch.Close()
+		return fmt.Errorf("bind queue: %w", err)
+	}
+	msgs, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		_ = ch.Close()
+		return fmt.Errorf("consume queue: %w", err)
+	}
+
+	go func() {
+		defer ch.Close()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case m, ok := <-msgs:
+				if !ok {
+					// Only the channel failed when the connection is still up;
+					// dropping the connection makes watch set everything up
+					// again.
+					if !conn.IsClosed() {
+						b.log.Warn("amqp delivery channel closed, reconnecting", zap.String("node_id", s.nodeID))
+						_ = conn.Close()
+					}
+					return
+				}
+				var d cluster.Delivery
+				if err := json.Unmarshal(m.Body, &d); err != nil {
+					b.log.Warn("dropping malformed delivery", zap.Error(err))
+					_ = m.Reject(false)
+					continue
+				}
+				s.handler(s.ctx, &d)
+				_ = m.Ack(false)
+			}
+		}
+	}()
+	return nil
+}
+
+// Ready fails while the bus is reconnecting.
+func (b *bus) Ready() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil || b.conn.IsClosed() {
+		return ErrDisconnected
+	}
+	return nil
+}
+
+func (b *bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.closed:
+		return nil
+	default:
+	}
+	close(b.closed)
+	if b.conn == nil {
+		return nil
+	}
+	return b.conn.Close()
+}
@@ -0,0 +1,64 @@
+package amqp
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"go.uber.org/zap"
+)
+
+// TestBus_Reconnect needs a RabbitMQ broker, named by IM_TEST_AMQP_URL.
+func TestBus_Reconnect(t *testing.T) {
+	url := os.Getenv("IM_TEST_AMQP_URL")
+	if url == "" {
+		t.Skip("IM_TEST_AMQP_URL is not set")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cb, err := NewBus(url, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cb.Close()
+	b := cb.(*bus)
+
+	got := make(chan string, 10)
+	if err := b.Subscribe(ctx, "test-reconnect", func(_ context.Context, d *cluster.Delivery) {
+		got <- d.UserID
+	}); err != nil {
+		t.Fatal(err)
+	}
+	roundTrip := func(userID string) {
+		t.Helper()
+		if err := b.Publish(ctx, "test-reconnect", &cluster.Delivery{UserID: userID}); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case id := <-got:
+			if id != userID {
+				t.Fatalf("expected %s, got %s", userID, id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %s", userID)
+		}
+	}
+	roundTrip("u1")
+
+	// drop the connection as a broker restart would
+	b.mu.Lock()
+	conn := b.conn
+	b.mu.Unlock()
+	_ = conn.Close()
+	if err := b.Ready(); err == nil {
+		t.Fatal("expected the bus to report the lost connection")
+	}
+	for deadline := time.Now().Add(10 * time.Second); b.Ready() != nil; time.Sleep(50 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("bus did not reconnect")
+		}
+	}
+	roundTrip("u2")
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrations_Embedded(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Fatalf("expected version %d, got %d_%s", i+1, m.Version, m.Name)
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("%d_%s has no down statements", m.Version, m.Name)
+		}
+	}
+}
+
+func TestMigrations_Load(t *testing.T) {
+	migrations, err := loadMigrations(fstest.MapFS{
+		"002_more.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+		"001_init.down.sql": {Data: []byte("DROP TABLE a;")},
+		"001_init.up.sql":   {Data: []byte("CREATE TABLE a ();")},
+		"README.md":         {Data: []byte("ignored")},
+	})
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "init" || migrations[0].Down != "DROP TABLE a;" ||
+		migrations[1].Version != 2 || migrations[1].Down != "" {
+		t.Fatalf("unexpected migrations: %+v", migrations)
+	}
+}
+
+func TestMigrations_LoadRejects(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"no direction": {"001_init.sql": {Data: []byte("SELECT 1;")}},
+		"no version":   {"init.up.sql": {Data: []byte("SELECT 1;")}},
+		"zero version": {"000_init.up.sql": {Data: []byte("SELECT 1;")}},
+		"no up":        {"001_init.down.sql": {Data: []byte("SELECT 1;")}},
+		"empty up":     {"001_init.up.sql": {Data: []byte("\n")}},
+		"two names": {
+			"001_init.up.sql":  {Data: []byte("SELECT 1;")},
+			"001_other.up.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := loadMigrations(fsys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
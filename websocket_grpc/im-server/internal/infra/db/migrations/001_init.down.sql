@@ -0,0 +1,13 @@
+DROP TABLE presence;
+DROP TABLE client_sends;
+DROP TABLE read_cursors;
+DROP TABLE inboxes;
+DROP TABLE messages;
+DROP TABLE conversation_seqs;
+DROP TABLE group_requests;
+DROP TABLE group_members;
+DROP TABLE groups;
+DROP TABLE friends;
+DROP TABLE friend_requests;
+DROP TABLE tokens;
+DROP TABLE users;
@@ -0,0 +1,144 @@
+-- Users are referenced by their uuid everywhere else; email and phone are
+-- optional but unique, so registration conflicts surface as unique
+-- violations.
+CREATE TABLE users (
+    id            bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    uuid          text NOT NULL UNIQUE,
+    email         text UNIQUE,
+    phone         text UNIQUE,
+    name          text NOT NULL,
+    password_hash text NOT NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    updated_at    timestamptz NOT NULL DEFAULT now()
+);
+
+-- tokens holds opaque access tokens by the hash of their value.
+CREATE TABLE tokens (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    hash       text NOT NULL UNIQUE,
+    user_id    text NOT NULL,
+    device_id  text NOT NULL,
+    expires_at timestamptz NOT NULL,
+    revoked_at timestamptz,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX tokens_user_id_idx ON tokens (user_id) WHERE revoked_at IS NULL;
+
+CREATE TABLE friend_requests (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    request_id text NOT NULL UNIQUE,
+    from_user  text NOT NULL,
+    to_user    text NOT NULL,
+    status     smallint NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX friend_requests_pair_idx ON friend_requests (from_user, to_user, status);
+
+-- friends stores every friendship twice, once from each side.
+CREATE TABLE friends (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id    text NOT NULL,
+    friend_id  text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (user_id, friend_id)
+);
+
+CREATE TABLE groups (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    uuid       text NOT NULL UNIQUE,
+    name       text NOT NULL,
+    owner      text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- role and notify hold domain/group.Role and NotifyLevel.
+CREATE TABLE group_members (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    group_id   bigint NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id    text NOT NULL,
+    role       smallint NOT NULL,
+    notify     smallint NOT NULL DEFAULT 0,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (group_id, user_id)
+);
+CREATE INDEX group_members_user_id_idx ON group_members (user_id, created_at);
+
+CREATE TABLE group_requests (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    request_id text NOT NULL UNIQUE,
+    group_id   bigint NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id    text NOT NULL,
+    status     smallint NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX group_requests_member_idx ON group_requests (group_id, user_id, status);
+
+-- conversation_seqs hands out the gapless per-conversation message seqs.
+CREATE TABLE conversation_seqs (
+    conversation_id text PRIMARY KEY,
+    seq             bigint NOT NULL
+);
+
+-- content_type is empty for untyped messages, whose content is opaque
+-- client bytes; typed content is an encoded im.v1.Content.
+CREATE TABLE messages (
+    id              bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    conversation_id text NOT NULL,
+    seq             bigint NOT NULL,
+    from_user       text NOT NULL,
+    to_user         text NOT NULL DEFAULT '',
+    group_uuid      text NOT NULL DEFAULT '',
+    content_type    text NOT NULL DEFAULT '',
+    content         bytea,
+    created_at      timestamptz NOT NULL DEFAULT now(),
+    edited_at       timestamptz,
+    recalled_at     timestamptz,
+    UNIQUE (conversation_id, seq)
+);
+CREATE INDEX messages_conversation_id_idx ON messages (conversation_id, id);
+
+-- inboxes holds a row per recipient and message until the recipient acks
+-- the delivery.
+CREATE TABLE inboxes (
+    id         bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id    text NOT NULL,
+    message_id bigint NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    delivered  boolean NOT NULL DEFAULT false,
+    created_at timestamptz NOT NULL DEFAULT now(),
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    UNIQUE (user_id, message_id)
+);
+CREATE INDEX inboxes_pending_idx ON inboxes (user_id, id) WHERE NOT delivered;
+CREATE INDEX inboxes_message_id_idx ON inboxes (message_id);
+
+CREATE TABLE read_cursors (
+    user_id         text NOT NULL,
+    conversation_id text NOT NULL,
+    seq             bigint NOT NULL,
+    updated_at      timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, conversation_id)
+);
+
+-- client_sends remembers recent client message IDs to recognize retries.
+CREATE TABLE client_sends (
+    sender        text NOT NULL,
+    client_msg_id text NOT NULL,
+    message_id    bigint NOT NULL,
+    created_at    timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (sender, client_msg_id)
+);
+CREATE INDEX client_sends_created_at_idx ON client_sends (sender, created_at);
+
+-- presence maps users to the nodes holding their sessions.
+CREATE TABLE presence (
+    user_id    text NOT NULL,
+    node_id    text NOT NULL,
+    updated_at timestamptz NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, node_id)
+);
+CREATE INDEX presence_node_id_idx ON presence (node_id);
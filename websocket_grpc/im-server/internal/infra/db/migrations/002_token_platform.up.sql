@@ -0,0 +1,3 @@
+-- platform is the client platform a token was issued for; empty when the
+-- login did not name one.
+ALTER TABLE tokens ADD COLUMN platform text NOT NULL DEFAULT '';
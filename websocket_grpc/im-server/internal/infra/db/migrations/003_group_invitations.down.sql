@@ -0,0 +1 @@
+ALTER TABLE group_requests DROP COLUMN invited_by;
@@ -0,0 +1,2 @@
+-- invited_by is the member who invited user_id; empty for applications.
+ALTER TABLE group_requests ADD COLUMN invited_by text NOT NULL DEFAULT '';
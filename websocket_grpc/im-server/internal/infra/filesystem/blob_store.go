@@ -0,0 +1,192 @@
+// Package filesystem implements storage on a local directory.
+package filesystem
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	domainblob "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/blob"
+)
+
+// blobStore keeps every blob in two files under root:
+//
+//	blobs/<id[:2]>/<id>       content
+//	blobs/<id[:2]>/<id>.json  metadata
+//
+// and records ownership as empty files owners/<sha256(owner)>/<id>. Uploads
+// are written to tmp/ first and renamed into place once hashed.
+type blobStore struct {
+	root string
+	// mu serializes publishing blobs so concurrent uploads of the same
+	// content write its metadata once.
+	mu sync.Mutex
+}
+
+func NewBlobStore(root string) (blob.BlobStore, error) {
+	for _, dir := range []string{"tmp", "blobs", "owners"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &blobStore{root: root}, nil
+}
+
+func (s *blobStore) Put(ctx context.Context, owner, contentType string, r io.Reader) (*domainblob.Blob, error) {
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(h.Sum(nil))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := s.stat(id)
+	if errors.Is(err, respository.ErrNotFound) {
+		b = &domainblob.Blob{ID: id, ContentType: contentType, Size: size, CreatedAt: time.Now()}
+		err = s.publish(tmp.Name(), b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ownerDir := filepath.Join(s.root, "owners", ownerKey(owner))
+	if err := os.MkdirAll(ownerDir, 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(ownerDir, id), nil, 0o644); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// publish moves the uploaded file to its final place and writes the
+// metadata last, so a blob with metadata always has its content.
+func (s *blobStore) publish(tmpName string, b *domainblob.Blob) error {
+	dataPath := s.path(b.ID)
+	if err := os.MkdirAll(filepath.Dir(dataPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, dataPath); err != nil {
+		return err
+	}
+
+	meta, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	metaTmp := filepath.Join(s.root, "tmp", b.ID+".json")
+	if err := os.WriteFile(metaTmp, meta, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(metaTmp, dataPath+".json")
+}
+
+func (s *blobStore) Stat(ctx context.Context, id string) (*domainblob.Blob, error) {
+	return s.stat(id)
+}
+
+func (s *blobStore) stat(id string) (*domainblob.Blob, error) {
+	if !validID(id) {
+		return nil, respository.ErrNotFound
+	}
+	meta, err := os.ReadFile(s.path(id) + ".json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, respository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var b domainblob.Blob
+	if err := json.Unmarshal(meta, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (s *blobStore) Open(ctx context.Context, id string) (io.ReadSeekCloser, *domainblob.Blob, error) {
+	b, err := s.stat(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(s.path(id))
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, b, nil
+}
+
+func (s *blobStore) Usage(ctx context.Context, owner string) (int64, error) {
+	entries, err := os.ReadDir(filepath.Join(s.root, "owners", ownerKey(owner)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		b, err := s.stat(e.Name())
+		if errors.Is(err, respository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += b.Size
+	}
+	return total, nil
+}
+
+func (s *blobStore) Owns(ctx context.Context, owner, id string) (bool, error) {
+	if !validID(id) {
+		return false, nil
+	}
+	_, err := os.Stat(filepath.Join(s.root, "owners", ownerKey(owner), id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *blobStore) path(id string) string {
+	return filepath.Join(s.root, "blobs", id[:2], id)
+}
+
+// validID accepts only hex SHA-256 digests, which also keeps IDs from
+// escaping root.
+func validID(id string) bool {
+	if len(id) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+// ownerKey turns a user ID into a safe directory name.
+func ownerKey(owner string) string {
+	sum := sha256.Sum256([]byte(owner))
+	return hex.EncodeToString(sum[:])
+}
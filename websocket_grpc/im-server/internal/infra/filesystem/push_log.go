@@ -0,0 +1,52 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
+)
+
+// pushLog stands in for a push gateway by appending every notification as a
+// JSON line to a file.
+type pushLog struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewPushLog appends notifications to the file at path, or writes them to
+// standard output when path is "-".
+func NewPushLog(path string) (push.Notifier, error) {
+	if path == "-" {
+		return &pushLog{w: os.Stdout, enc: json.NewEncoder(os.Stdout)}, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &pushLog{w: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (l *pushLog) Notify(ctx context.Context, batch []push.Notification) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range batch {
+		if err := l.enc.Encode(&batch[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the file; standard output stays open.
+func (l *pushLog) Close() error {
+	if f, ok := l.w.(*os.File); ok && f != os.Stdout {
+		return f.Close()
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+)
+
+// bus connects nodes living in the same process. Deliveries are handed to
+// the subscriber synchronously.
+type bus struct {
+	mu       sync.RWMutex
+	handlers map[string]func(context.Context, *cluster.Delivery)
+}
+
+func NewBus() cluster.Bus {
+	return &bus{handlers: make(map[string]func(context.Context, *cluster.Delivery))}
+}
+
+func (b *bus) Publish(ctx context.Context, nodeID string, d *cluster.Delivery) error {
+	b.mu.RLock()
+	handler, ok := b.handlers[nodeID]
+	b.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("node %s is not subscribed", nodeID)
+	}
+	handler(ctx, d)
+	return nil
+}
+
+func (b *bus) Subscribe(ctx context.Context, nodeID string, handler func(context.Context, *cluster.Delivery)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.handlers[nodeID]; ok {
+		return fmt.Errorf("node %s is already subscribed", nodeID)
+	}
+	b.handlers[nodeID] = handler
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.handlers, nodeID)
+		b.mu.Unlock()
+	}()
+	return nil
+}
+
+func (b *bus) Ready() error {
+	return nil
+}
+
+func (b *bus) Close() error {
+	return nil
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+)
+
+type clientSendRepository struct {
+	mu sync.RWMutex
+	// sends is keyed by sender, then by client message ID.
+	sends map[string]map[string]*domainmessage.ClientSend
+}
+
+func NewClientSendRepository() message.ClientSendRepository {
+	return &clientSendRepository{sends: make(map[string]map[string]*domainmessage.ClientSend)}
+}
+
+func (r *clientSendRepository) GetClientSend(ctx context.Context, sender, clientMsgID string) (*domainmessage.ClientSend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.sends[sender][clientMsgID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (r *clientSendRepository) PutClientSend(ctx context.Context, send *domainmessage.ClientSend) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bySender, ok := r.sends[send.Sender]
+	if !ok {
+		bySender = make(map[string]*domainmessage.ClientSend)
+		r.sends[send.Sender] = bySender
+	}
+	cp := *send
+	bySender[send.ClientMsgID] = &cp
+	return nil
+}
+
+func (r *clientSendRepository) TrimClientSends(ctx context.Context, sender string, keep int, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bySender := r.sends[sender]
+	kept := make([]*domainmessage.ClientSend, 0, len(bySender))
+	for id, s := range bySender {
+		if s.CreatedAt.Before(before) {
+			delete(bySender, id)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(kept) > keep {
+		sort.Slice(kept, func(i, j int) bool { return kept[i].CreatedAt.After(kept[j].CreatedAt) })
+		for _, s := range kept[keep:] {
+			delete(bySender, s.ClientMsgID)
+		}
+	}
+	if len(bySender) == 0 {
+		delete(r.sends, sender)
+	}
+	return nil
+}
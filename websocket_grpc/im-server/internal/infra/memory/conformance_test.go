@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/respositorytest"
+)
+
+func TestRepositories_Conformance(t *testing.T) {
+	respositorytest.Run(t, func(t *testing.T) respositorytest.Repositories {
+		// NewTransactor does not roll back, so the suite skips it.
+		return respositorytest.Repositories{
+			Users:    NewUserRepository(),
+			Groups:   NewGroupRepository(),
+			Messages: NewMessageRepository(),
+			Inboxes:  NewInboxRepository(),
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	domainfriend "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/friend"
+)
+
+type friendRepository struct {
+	mu         sync.RWMutex
+	nextID     int64
+	requests   map[string]*domainfriend.FriendRequest
+	friends    map[string]map[string]*domainfriend.Friend // user -> friend -> edge
+	nextEdgeID int64
+}
+
+func NewFriendRepository() friend.FriendRepository {
+	return &friendRepository{
+		requests: make(map[string]*domainfriend.FriendRequest),
+		friends:  make(map[string]map[string]*domainfriend.Friend),
+	}
+}
+
+func (r *friendRepository) CreateRequest(ctx context.Context, request *domainfriend.FriendRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.requests[request.RequestID]; ok {
+		return ErrConflict
+	}
+	r.nextID++
+	request.ID = r.nextID
+	cp := *request
+	r.requests[request.RequestID] = &cp
+	return nil
+}
+
+func (r *friendRepository) GetRequest(ctx context.Context, requestID string) (*domainfriend.FriendRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.requests[requestID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *req
+	return &cp, nil
+}
+
+func (r *friendRepository) ResolveRequest(ctx context.Context, requestID string, status domainfriend.RequestStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	req, ok := r.requests[requestID]
+	if !ok {
+		return false, ErrNotFound
+	}
+	if req.Status != domainfriend.RequestPending {
+		return false, nil
+	}
+	req.Status = status
+	req.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (r *friendRepository) HasPendingRequest(ctx context.Context, fromUser, toUser string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, req := range r.requests {
+		if req.FromUser == fromUser && req.ToUser == toUser && req.Status == domainfriend.RequestPending {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *friendRepository) AddFriend(ctx context.Context, userID, friendID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, pair := range [][2]string{{userID, friendID}, {friendID, userID}} {
+		if _, ok := r.friends[pair[0]]; !ok {
+			r.friends[pair[0]] = make(map[string]*domainfriend.Friend)
+		}
+		if _, ok := r.friends[pair[0]][pair[1]]; ok {
+			continue
+		}
+		r.nextEdgeID++
+		r.friends[pair[0]][pair[1]] = &domainfriend.Friend{
+			ID:        r.nextEdgeID,
+			UserID:    pair[0],
+			FriendID:  pair[1],
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	return nil
+}
+
+func (r *friendRepository) IsFriend(ctx context.Context, userID, friendID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.friends[userID][friendID]
+	return ok, nil
+}
+
+func (r *friendRepository) ListFriends(ctx context.Context, userID string, page int, pageSize int) ([]*domainfriend.Friend, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*domainfriend.Friend, 0, len(r.friends[userID]))
+	for _, f := range r.friends[userID] {
+		cp := *f
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	start, end := paginate(len(out), page, pageSize)
+	return out[start:end], nil
+}
+
+func (r *friendRepository) ListFriendIDs(ctx context.Context, userID string) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.friends[userID]))
+	for _, f := range r.friends[userID] {
+		ids = append(ids, f.FriendID)
+	}
+	return ids, nil
+}
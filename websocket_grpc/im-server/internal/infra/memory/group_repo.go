@@ -0,0 +1,295 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/group"
+)
+
+type groupRepository struct {
+	mu           sync.RWMutex
+	nextID       int64
+	nextMemberID int64
+	groups       map[int64]*domaingroup.Group
+	byUUID       map[string]int64
+	// group -> user -> member
+	members       map[int64]map[string]*domaingroup.GroupMember
+	nextRequestID int64
+	requests      map[string]*domaingroup.GroupRequest
+}
+
+func NewGroupRepository() group.GroupRepository {
+	return &groupRepository{
+		groups:   make(map[int64]*domaingroup.Group),
+		byUUID:   make(map[string]int64),
+		members:  make(map[int64]map[string]*domaingroup.GroupMember),
+		requests: make(map[string]*domaingroup.GroupRequest),
+	}
+}
+
+func (r *groupRepository) CreateGroup(ctx context.Context, group *domaingroup.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byUUID[group.UUID]; ok {
+		return ErrConflict
+	}
+	r.nextID++
+	group.ID = r.nextID
+	cp := *group
+	r.groups[group.ID] = &cp
+	r.byUUID[group.UUID] = group.ID
+	return nil
+}
+
+func (r *groupRepository) GetGroup(ctx context.Context, id int64) (*domaingroup.Group, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	g, ok := r.groups[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *g
+	return &cp, nil
+}
+
+func (r *groupRepository) GetGroupByUUID(ctx context.Context, uuid string) (*domaingroup.Group, error) {
+	r.mu.RLock()
+	id, ok := r.byUUID[uuid]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return r.GetGroup(ctx, id)
+}
+
+func (r *groupRepository) UpdateGroup(ctx context.Context, group *domaingroup.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.groups[group.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if other, taken := r.byUUID[group.UUID]; taken && other != group.ID {
+		return ErrConflict
+	}
+	delete(r.byUUID, old.UUID)
+	cp := *group
+	r.groups[group.ID] = &cp
+	r.byUUID[group.UUID] = group.ID
+	return nil
+}
+
+func (r *groupRepository) DeleteGroup(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if g, ok := r.groups[id]; ok {
+		delete(r.byUUID, g.UUID)
+	}
+	delete(r.groups, id)
+	delete(r.members, id)
+	return nil
+}
+
+func (r *groupRepository) ListGroups(ctx context.Context, page int, pageSize int) ([]*domaingroup.Group, error) {
+	return r.listGroups(page, pageSize, func(*domaingroup.Group) bool { return true }), nil
+}
+
+func (r *groupRepository) ListUserGroups(ctx context.Context, userID string, page int, pageSize int) ([]*domaingroup.Group, error) {
+	return r.listGroups(page, pageSize, func(g *domaingroup.Group) bool {
+		_, ok := r.members[g.ID][userID]
+		return ok
+	}), nil
+}
+
+func (r *groupRepository) AddGroupMember(ctx context.Context, member *domaingroup.GroupMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.groups[member.GroupID]; !ok {
+		return ErrNotFound
+	}
+	if _, ok := r.members[member.GroupID]; !ok {
+		r.members[member.GroupID] = make(map[string]*domaingroup.GroupMember)
+	}
+	if existing, ok := r.members[member.GroupID][member.UserID]; ok {
+		member.ID = existing.ID
+		return nil
+	}
+	r.nextMemberID++
+	member.ID = r.nextMemberID
+	cp := *member
+	r.members[member.GroupID][member.UserID] = &cp
+	return nil
+}
+
+func (r *groupRepository) RemoveGroupMember(ctx context.Context, groupID int64, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.members[groupID], userID)
+	return nil
+}
+
+func (r *groupRepository) IsGroupMember(ctx context.Context, groupID int64, userID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.members[groupID][userID]
+	return ok, nil
+}
+
+func (r *groupRepository) GetGroupMember(ctx context.Context, groupID int64, userID string) (*domaingroup.GroupMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.members[groupID][userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *m
+	return &cp, nil
+}
+
+func (r *groupRepository) UpdateGroupMemberRole(ctx context.Context, groupID int64, userID string, role domaingroup.Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.members[groupID][userID]
+	if !ok {
+		return ErrNotFound
+	}
+	m.Role = role
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *groupRepository) UpdateGroupMemberNotify(ctx context.Context, groupID int64, userID string, level domaingroup.NotifyLevel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.members[groupID][userID]
+	if !ok {
+		return ErrNotFound
+	}
+	m.Notify = level
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *groupRepository) ListGroupMembers(ctx context.Context, groupID int64, page int, pageSize int) ([]*domaingroup.GroupMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*domaingroup.GroupMember, 0, len(r.members[groupID]))
+	for _, m := range r.members[groupID] {
+		cp := *m
+		out = append(out, &cp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	start, end := paginate(len(out), page, pageSize)
+	return out[start:end], nil
+}
+
+func (r *groupRepository) ListGroupMemberIDs(ctx context.Context, groupID int64) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]string, 0, len(r.members[groupID]))
+	for uid := range r.members[groupID] {
+		out = append(out, uid)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func (r *groupRepository) ListMutedGroupMembers(ctx context.Context, groupID int64) (map[string]domaingroup.NotifyLevel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make(map[string]domaingroup.NotifyLevel)
+	for uid, m := range r.members[groupID] {
+		if m.Notify != domaingroup.NotifyAll {
+			out[uid] = m.Notify
+		}
+	}
+	return out, nil
+}
+
+func (r *groupRepository) CreateJoinRequest(ctx context.Context, request *domaingroup.GroupRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.requests[request.RequestID]; ok {
+		return ErrConflict
+	}
+	r.nextRequestID++
+	request.ID = r.nextRequestID
+	cp := *request
+	r.requests[request.RequestID] = &cp
+	return nil
+}
+
+func (r *groupRepository) GetJoinRequest(ctx context.Context, requestID string) (*domaingroup.GroupRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	req, ok := r.requests[requestID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *req
+	return &cp, nil
+}
+
+func (r *groupRepository) ResolveJoinRequest(ctx context.Context, requestID string, status domaingroup.RequestStatus) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	req, ok := r.requests[requestID]
+	if !ok {
+		return false, ErrNotFound
+	}
+	if req.Status != domaingroup.RequestPending {
+		return false, nil
+	}
+	req.Status = status
+	req.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (r *groupRepository) HasPendingJoinRequest(ctx context.Context, groupID int64, userID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, req := range r.requests {
+		if req.GroupID == groupID && req.UserID == userID && req.Status == domaingroup.RequestPending {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listGroups returns the matching groups newest first.
+func (r *groupRepository) listGroups(page, pageSize int, match func(*domaingroup.Group) bool) []*domaingroup.Group {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]*domaingroup.Group, 0)
+	for _, g := range r.groups {
+		if match(g) {
+			cp := *g
+			out = append(out, &cp)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	start, end := paginate(len(out), page, pageSize)
+	return out[start:end]
+}
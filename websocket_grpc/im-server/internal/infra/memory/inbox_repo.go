@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	domaininbox "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+)
+
+type inboxRepository struct {
+	mu      sync.RWMutex
+	nextID  int64
+	inboxes map[int64]*domaininbox.Inbox
+}
+
+func NewInboxRepository() inbox.InboxRepository {
+	return &inboxRepository{inboxes: make(map[int64]*domaininbox.Inbox)}
+}
+
+func (r *inboxRepository) CreateInbox(ctx context.Context, inbox *domaininbox.Inbox) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	inbox.ID = r.nextID
+	cp := *inbox
+	r.inboxes[inbox.ID] = &cp
+	return nil
+}
+
+func (r *inboxRepository) CreateInboxes(ctx context.Context, inboxes []*domaininbox.Inbox) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, inbox := range inboxes {
+		r.nextID++
+		inbox.ID = r.nextID
+		cp := *inbox
+		r.inboxes[inbox.ID] = &cp
+	}
+	return nil
+}
+
+func (r *inboxRepository) GetInbox(ctx context.Context, id int64) (*domaininbox.Inbox, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	in, ok := r.inboxes[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *in
+	return &cp, nil
+}
+
+func (r *inboxRepository) UpdateInbox(ctx context.Context, inbox *domaininbox.Inbox) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.inboxes[inbox.ID]; !ok {
+		return ErrNotFound
+	}
+	cp := *inbox
+	r.inboxes[inbox.ID] = &cp
+	return nil
+}
+
+func (r *inboxRepository) DeleteInbox(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.inboxes, id)
+	return nil
+}
+
+func (r *inboxRepository) ListInboxes(ctx context.Context, userID string, page int, pageSize int) ([]*domaininbox.Inbox, error) {
+	matched := r.filter(func(in *domaininbox.Inbox) bool { return in.UserID == userID })
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+	start, end := paginate(len(matched), page, pageSize)
+	return matched[start:end], nil
+}
+
+func (r *inboxRepository) ListUndelivered(ctx context.Context, userID string, afterID int64, limit int) ([]*domaininbox.Inbox, error) {
+	matched := r.filter(func(in *domaininbox.Inbox) bool {
+		return in.UserID == userID && in.ID > afterID && !in.Delivered
+	})
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+	if limit >= 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+func (r *inboxRepository) MarkDelivered(ctx context.Context, userID string, messageIDs ...int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make(map[int64]struct{}, len(messageIDs))
+	for _, id := range messageIDs {
+		ids[id] = struct{}{}
+	}
+	now := time.Now()
+	for _, in := range r.inboxes {
+		if _, ok := ids[in.MessageID]; ok && in.UserID == userID && !in.Delivered {
+			in.Delivered = true
+			in.UpdatedAt = now
+		}
+	}
+	return nil
+}
+
+func (r *inboxRepository) Redeliver(ctx context.Context, messageID int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userIDs := make([]string, 0)
+	now := time.Now()
+	for _, in := range r.inboxes {
+		if in.MessageID != messageID {
+			continue
+		}
+		in.Delivered = false
+		in.UpdatedAt = now
+		userIDs = append(userIDs, in.UserID)
+	}
+	return userIDs, nil
+}
+
+func (r *inboxRepository) filter(match func(*domaininbox.Inbox) bool) []*domaininbox.Inbox {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*domaininbox.Inbox, 0)
+	for _, in := range r.inboxes {
+		if match(in) {
+			cp := *in
+			matched = append(matched, &cp)
+		}
+	}
+	return matched
+}
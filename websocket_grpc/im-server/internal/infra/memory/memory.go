@@ -0,0 +1,29 @@
+// Package memory provides process-local repository implementations. They are
+// used when no database is configured and by tests.
+package memory
+
+import "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+
+var (
+	ErrNotFound = respository.ErrNotFound
+	ErrConflict = respository.ErrConflict
+)
+
+// paginate returns the bounds of page (1-based) within a slice of length n.
+func paginate(n, page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		return 0, 0
+	}
+	start := (page - 1) * pageSize
+	if start > n {
+		start = n
+	}
+	end := start + pageSize
+	if end > n {
+		end = n
+	}
+	return start, end
+}
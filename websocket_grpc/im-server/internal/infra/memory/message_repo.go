@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+)
+
+type messageRepository struct {
+	mu       sync.RWMutex
+	nextID   int64
+	messages map[int64]*domainmessage.Message
+	// conversation ID -> last allocated seq
+	seqs map[string]int64
+}
+
+func NewMessageRepository() message.MessageRepository {
+	return &messageRepository{
+		messages: make(map[int64]*domainmessage.Message),
+		seqs:     make(map[string]int64),
+	}
+}
+
+func (r *messageRepository) CreateMessage(ctx context.Context, message *domainmessage.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	message.ID = r.nextID
+	conversationID := message.ConversationID()
+	r.seqs[conversationID]++
+	message.Seq = r.seqs[conversationID]
+	cp := *message
+	r.messages[message.ID] = &cp
+	return nil
+}
+
+func (r *messageRepository) GetMessage(ctx context.Context, id int64) (*domainmessage.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	m, ok := r.messages[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *m
+	return &cp, nil
+}
+
+func (r *messageRepository) UpdateMessage(ctx context.Context, m *domainmessage.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.messages[m.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if old.IsRecalled() {
+		return message.ErrRecalled
+	}
+	cp := *m
+	cp.Seq = old.Seq
+	r.messages[m.ID] = &cp
+	return nil
+}
+
+func (r *messageRepository) DeleteMessage(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.messages, id)
+	return nil
+}
+
+func (r *messageRepository) ListConversation(ctx context.Context, userA, userB string, cursor message.Cursor) ([]*domainmessage.Message, error) {
+	return r.page(cursor, func(m *domainmessage.Message) bool {
+		return !m.IsGroup() &&
+			((m.FromUser == userA && m.ToUser == userB) || (m.FromUser == userB && m.ToUser == userA))
+	}), nil
+}
+
+func (r *messageRepository) ListGroupMessages(ctx context.Context, groupUUID string, cursor message.Cursor) ([]*domainmessage.Message, error) {
+	return r.page(cursor, func(m *domainmessage.Message) bool {
+		return m.GroupUUID == groupUUID
+	}), nil
+}
+
+// page returns the matching messages selected by cursor.
+func (r *messageRepository) page(cursor message.Cursor, match func(*domainmessage.Message) bool) []*domainmessage.Message {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	forward := cursor.Direction == message.Forward
+	matched := make([]*domainmessage.Message, 0)
+	for _, m := range r.messages {
+		if !match(m) {
+			continue
+		}
+		if forward && m.ID <= cursor.After || !forward && cursor.After != 0 && m.ID >= cursor.After {
+			continue
+		}
+		cp := *m
+		matched = append(matched, &cp)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if forward {
+			return matched[i].ID < matched[j].ID
+		}
+		return matched[i].ID > matched[j].ID
+	})
+	if len(matched) > cursor.Limit {
+		matched = matched[:cursor.Limit]
+	}
+	return matched
+}
+
+func (r *messageRepository) ListSeqRange(ctx context.Context, conversationID string, fromSeq, toSeq int64, limit int) ([]*domainmessage.Message, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*domainmessage.Message, 0)
+	for _, m := range r.messages {
+		if m.Seq >= fromSeq && m.Seq <= toSeq && m.ConversationID() == conversationID {
+			cp := *m
+			matched = append(matched, &cp)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Seq < matched[j].Seq })
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+func (r *messageRepository) CountAfter(ctx context.Context, conversationID string, afterSeq int64, excludeFrom string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var n int64
+	for _, m := range r.messages {
+		if m.Seq > afterSeq && m.FromUser != excludeFrom && m.ConversationID() == conversationID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *messageRepository) LastSeq(ctx context.Context, conversationID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.seqs[conversationID], nil
+}
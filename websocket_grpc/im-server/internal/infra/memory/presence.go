@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/presence"
+)
+
+// presenceDirectory is only shared by nodes living in the same process.
+type presenceDirectory struct {
+	mu sync.RWMutex
+	// user -> node set
+	nodes map[string]map[string]struct{}
+}
+
+func NewPresenceDirectory() presence.Directory {
+	return &presenceDirectory{nodes: make(map[string]map[string]struct{})}
+}
+
+func (d *presenceDirectory) Register(ctx context.Context, userID, nodeID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.nodes[userID]; !ok {
+		d.nodes[userID] = make(map[string]struct{})
+	}
+	d.nodes[userID][nodeID] = struct{}{}
+	return nil
+}
+
+func (d *presenceDirectory) Unregister(ctx context.Context, userID, nodeID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	nodes, ok := d.nodes[userID]
+	if !ok {
+		return nil
+	}
+	delete(nodes, nodeID)
+	if len(nodes) == 0 {
+		delete(d.nodes, userID)
+	}
+	return nil
+}
+
+func (d *presenceDirectory) Nodes(ctx context.Context, userID string) ([]string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	out := make([]string, 0, len(d.nodes[userID]))
+	for node := range d.nodes[userID] {
+		out = append(out, node)
+	}
+	return out, nil
+}
+
+func (d *presenceDirectory) ClearNode(ctx context.Context, nodeID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for userID, nodes := range d.nodes {
+		delete(nodes, nodeID)
+		if len(nodes) == 0 {
+			delete(d.nodes, userID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/receipt"
+)
+
+type readCursorRepository struct {
+	mu sync.RWMutex
+	// user -> conversation -> seq
+	cursors map[string]map[string]int64
+}
+
+func NewReadCursorRepository() receipt.ReadCursorRepository {
+	return &readCursorRepository{cursors: make(map[string]map[string]int64)}
+}
+
+func (r *readCursorRepository) AdvanceReadCursor(ctx context.Context, userID, conversationID string, seq int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conversations, ok := r.cursors[userID]
+	if !ok {
+		conversations = make(map[string]int64)
+		r.cursors[userID] = conversations
+	}
+	if conversations[conversationID] >= seq {
+		return false, nil
+	}
+	conversations[conversationID] = seq
+	return true, nil
+}
+
+func (r *readCursorRepository) GetReadCursor(ctx context.Context, userID, conversationID string) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cursors[userID][conversationID], nil
+}
@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	domaintoken "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/token"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/token"
+)
+
+type tokenRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	tokens map[string]*domaintoken.Token // hash -> token
+}
+
+func NewTokenRepository() token.TokenRepository {
+	return &tokenRepository{tokens: make(map[string]*domaintoken.Token)}
+}
+
+func (r *tokenRepository) CreateToken(ctx context.Context, token *domaintoken.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tokens[token.Hash]; ok {
+		return ErrConflict
+	}
+	r.nextID++
+	token.ID = r.nextID
+	cp := *token
+	r.tokens[token.Hash] = &cp
+	return nil
+}
+
+func (r *tokenRepository) GetToken(ctx context.Context, hash string) (*domaintoken.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tokens[hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *t
+	return &cp, nil
+}
+
+func (r *tokenRepository) RevokeToken(ctx context.Context, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.tokens[hash]; ok && t.RevokedAt == nil {
+		now := time.Now()
+		t.RevokedAt = &now
+	}
+	return nil
+}
+
+func (r *tokenRepository) RevokeUserTokens(ctx context.Context, userID, deviceID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for _, t := range r.tokens {
+		if t.UserID != userID || (deviceID != "" && t.DeviceID != deviceID) || t.RevokedAt != nil {
+			continue
+		}
+		revokedAt := now
+		t.RevokedAt = &revokedAt
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,19 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+)
+
+type transactor struct{}
+
+// NewTransactor returns a Transactor that simply calls fn: every repository
+// here locks on its own, so a failing fn leaves its earlier writes in place.
+func NewTransactor() respository.Transactor {
+	return transactor{}
+}
+
+func (transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	domainuser "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/user"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+)
+
+type userRepository struct {
+	mu     sync.RWMutex
+	nextID int64
+	users  map[int64]*domainuser.User
+}
+
+func NewUserRepository() user.UserRepository {
+	return &userRepository{users: make(map[int64]*domainuser.User)}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user *domainuser.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.taken(user) {
+		return ErrConflict
+	}
+	r.nextID++
+	user.ID = r.nextID
+	cp := *user
+	r.users[user.ID] = &cp
+	return nil
+}
+
+func (r *userRepository) GetUser(ctx context.Context, id int64) (*domainuser.User, error) {
+	return r.find(func(u *domainuser.User) bool { return u.ID == id })
+}
+
+func (r *userRepository) GetUserByUUID(ctx context.Context, uuid string) (*domainuser.User, error) {
+	return r.find(func(u *domainuser.User) bool { return u.UUID == uuid })
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*domainuser.User, error) {
+	return r.find(func(u *domainuser.User) bool { return email != "" && u.Email == email })
+}
+
+func (r *userRepository) GetUserByPhone(ctx context.Context, phone string) (*domainuser.User, error) {
+	return r.find(func(u *domainuser.User) bool { return phone != "" && u.Phone == phone })
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user *domainuser.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.taken(user) {
+		return ErrConflict
+	}
+	cp := *user
+	r.users[user.ID] = &cp
+	return nil
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+	return nil
+}
+
+func (r *userRepository) find(match func(*domainuser.User) bool) (*domainuser.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if match(u) {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// taken reports whether another user already holds the UUID, email or phone
+// of user. Empty email and phone values never collide.
+func (r *userRepository) taken(user *domainuser.User) bool {
+	for _, u := range r.users {
+		if u.ID == user.ID {
+			continue
+		}
+		if u.UUID == user.UUID ||
+			(user.Email != "" && u.Email == user.Email) ||
+			(user.Phone != "" && u.Phone == user.Phone) {
+			return true
+		}
+	}
+	return false
+}
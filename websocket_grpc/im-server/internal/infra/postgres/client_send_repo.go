@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type clientSendRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewClientSendRepository(pool *pgxpool.Pool) message.ClientSendRepository {
+	return &clientSendRepository{pool: pool}
+}
+
+func (r *clientSendRepository) GetClientSend(ctx context.Context, sender, clientMsgID string) (*domainmessage.ClientSend, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT sender, client_msg_id, message_id, created_at FROM client_sends WHERE sender = $1 AND client_msg_id = $2", sender, clientMsgID)
+	var s domainmessage.ClientSend
+	if err := row.Scan(&s.Sender, &s.ClientMsgID, &s.MessageID, &s.CreatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &s, nil
+}
+
+func (r *clientSendRepository) PutClientSend(ctx context.Context, send *domainmessage.ClientSend) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `INSERT INTO client_sends (sender, client_msg_id, message_id, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (sender, client_msg_id) DO UPDATE SET message_id = EXCLUDED.message_id, created_at = EXCLUDED.created_at`,
+		send.Sender, send.ClientMsgID, send.MessageID, send.CreatedAt)
+	return err
+}
+
+func (r *clientSendRepository) TrimClientSends(ctx context.Context, sender string, keep int, before time.Time) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM client_sends WHERE sender = $1 AND (created_at < $2 OR client_msg_id NOT IN (
+		SELECT client_msg_id FROM client_sends WHERE sender = $1 ORDER BY created_at DESC LIMIT $3))`,
+		sender, before, keep)
+	return err
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/db"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/respositorytest"
+)
+
+// TestRepositories_Conformance needs a database it may wipe, named by
+// IM_TEST_POSTGRES_DSN.
+func TestRepositories_Conformance(t *testing.T) {
+	dsn := os.Getenv("IM_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("IM_TEST_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	migrate(t, pool)
+
+	respositorytest.Run(t, func(t *testing.T) respositorytest.Repositories {
+		if _, err := pool.Exec(ctx, `TRUNCATE users, groups, group_members, group_requests,
+			conversation_seqs, messages, inboxes RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatal(err)
+		}
+		return respositorytest.Repositories{
+			Users:      NewUserRepository(pool),
+			Groups:     NewGroupRepository(pool),
+			Messages:   NewMessageRepository(pool),
+			Inboxes:    NewInboxRepository(pool),
+			Transactor: NewTransactor(pool),
+		}
+	})
+}
+
+func migrate(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+	ctx := context.Background()
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Release()
+	m, err := db.NewMigrator(conn.Conn())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
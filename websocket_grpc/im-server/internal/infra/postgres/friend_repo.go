@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+
+	domainfriend "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/friend"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type friendRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFriendRepository(pool *pgxpool.Pool) friend.FriendRepository {
+	return &friendRepository{pool: pool}
+}
+
+func (r *friendRepository) CreateRequest(ctx context.Context, request *domainfriend.FriendRequest) error {
+	row := conn(ctx, r.pool).QueryRow(ctx, "INSERT INTO friend_requests (request_id, from_user, to_user, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id", request.RequestID, request.FromUser, request.ToUser, request.Status, request.CreatedAt, request.UpdatedAt)
+	return mapErr(row.Scan(&request.ID))
+}
+
+func (r *friendRepository) GetRequest(ctx context.Context, requestID string) (*domainfriend.FriendRequest, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT id, request_id, from_user, to_user, status, created_at, updated_at FROM friend_requests WHERE request_id = $1", requestID)
+	var request domainfriend.FriendRequest
+	if err := row.Scan(&request.ID, &request.RequestID, &request.FromUser, &request.ToUser, &request.Status, &request.CreatedAt, &request.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &request, nil
+}
+
+func (r *friendRepository) ResolveRequest(ctx context.Context, requestID string, status domainfriend.RequestStatus) (bool, error) {
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE friend_requests SET status = $1, updated_at = now() WHERE request_id = $2 AND status = $3", status, requestID, domainfriend.RequestPending)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return true, nil
+	}
+	// Tell a missing request apart from one that was already resolved.
+	if _, err := r.GetRequest(ctx, requestID); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func (r *friendRepository) HasPendingRequest(ctx context.Context, fromUser, toUser string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM friend_requests WHERE from_user = $1 AND to_user = $2 AND status = $3)", fromUser, toUser, domainfriend.RequestPending).Scan(&exists)
+	return exists, err
+}
+
+func (r *friendRepository) AddFriend(ctx context.Context, userID, friendID string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, "INSERT INTO friends (user_id, friend_id, created_at, updated_at) VALUES ($1, $2, now(), now()), ($2, $1, now(), now()) ON CONFLICT (user_id, friend_id) DO NOTHING", userID, friendID)
+	return err
+}
+
+func (r *friendRepository) IsFriend(ctx context.Context, userID, friendID string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM friends WHERE user_id = $1 AND friend_id = $2)", userID, friendID).Scan(&exists)
+	return exists, err
+}
+
+func (r *friendRepository) ListFriends(ctx context.Context, userID string, page int, pageSize int) ([]*domainfriend.Friend, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT id, user_id, friend_id, created_at, updated_at FROM friends WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	friends := make([]*domainfriend.Friend, 0)
+	for rows.Next() {
+		var f domainfriend.Friend
+		if err := rows.Scan(&f.ID, &f.UserID, &f.FriendID, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		friends = append(friends, &f)
+	}
+	return friends, rows.Err()
+}
+
+func (r *friendRepository) ListFriendIDs(ctx context.Context, userID string) ([]string, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT friend_id FROM friends WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 
 	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/group"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,48 +19,114 @@ func NewGroupRepository(pool *pgxpool.Pool) group.GroupRepository {
 }
 
 func (r *groupRepository) CreateGroup(ctx context.Context, group *domaingroup.Group) error {
-	_, err := r.pool.Exec(ctx, "INSERT INTO groups (id, uuid, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)", group.ID, group.UUID, group.Name, group.CreatedAt, group.UpdatedAt)
-	return err
+	row := conn(ctx, r.pool).QueryRow(ctx, "INSERT INTO groups (uuid, name, owner, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id", group.UUID, group.Name, group.Owner, group.CreatedAt, group.UpdatedAt)
+	return mapErr(row.Scan(&group.ID))
 }
 
 func (r *groupRepository) GetGroup(ctx context.Context, id int64) (*domaingroup.Group, error) {
-	row := r.pool.QueryRow(ctx, "SELECT id, uuid, name, created_at, updated_at FROM groups WHERE id = $1", id)
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT id, uuid, name, owner, created_at, updated_at FROM groups WHERE id = $1", id)
+	var group domaingroup.Group
+	if err := row.Scan(&group.ID, &group.UUID, &group.Name, &group.Owner, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &group, nil
+}
+
+func (r *groupRepository) GetGroupByUUID(ctx context.Context, uuid string) (*domaingroup.Group, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT id, uuid, name, owner, created_at, updated_at FROM groups WHERE uuid = $1", uuid)
 	var group domaingroup.Group
-	err := row.Scan(&group.ID, &group.UUID, &group.Name, &group.CreatedAt, &group.UpdatedAt)
-	return &group, err
+	if err := row.Scan(&group.ID, &group.UUID, &group.Name, &group.Owner, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &group, nil
 }
 
 func (r *groupRepository) UpdateGroup(ctx context.Context, group *domaingroup.Group) error {
-	_, err := r.pool.Exec(ctx, "UPDATE groups SET uuid = $1, name = $2, created_at = $3, updated_at = $4 WHERE id = $5", group.UUID, group.Name, group.CreatedAt, group.UpdatedAt, group.ID)
-	return err
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE groups SET uuid = $1, name = $2, owner = $3, created_at = $4, updated_at = $5 WHERE id = $6", group.UUID, group.Name, group.Owner, group.CreatedAt, group.UpdatedAt, group.ID)
+	if err != nil {
+		return mapErr(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return respository.ErrNotFound
+	}
+	return nil
 }
 
 func (r *groupRepository) DeleteGroup(ctx context.Context, id int64) error {
-	_, err := r.pool.Exec(ctx, "DELETE FROM groups WHERE id = $1", id)
+	_, err := conn(ctx, r.pool).Exec(ctx, "DELETE FROM groups WHERE id = $1", id)
 	return err
 }
 
 func (r *groupRepository) ListGroups(ctx context.Context, page int, pageSize int) ([]*domaingroup.Group, error) {
-	rows, err := r.pool.Query(ctx, "SELECT id, uuid, name, created_at, updated_at FROM groups ORDER BY created_at DESC LIMIT $1 OFFSET $2", pageSize, (page-1)*pageSize)
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT id, uuid, name, owner, created_at, updated_at FROM groups ORDER BY created_at DESC LIMIT $1 OFFSET $2", pageSize, (page-1)*pageSize)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return scanGroups(rows)
+}
 
-	groups := make([]*domaingroup.Group, 0)
-	for rows.Next() {
-		var group domaingroup.Group
-		err := rows.Scan(&group.ID, &group.UUID, &group.Name, &group.CreatedAt, &group.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		groups = append(groups, &group)
+func (r *groupRepository) ListUserGroups(ctx context.Context, userID string, page int, pageSize int) ([]*domaingroup.Group, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT g.id, g.uuid, g.name, g.owner, g.created_at, g.updated_at FROM groups g JOIN group_members m ON m.group_id = g.id WHERE m.user_id = $1 ORDER BY m.created_at DESC LIMIT $2 OFFSET $3", userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
 	}
-	return groups, nil
+	return scanGroups(rows)
+}
+
+func (r *groupRepository) AddGroupMember(ctx context.Context, member *domaingroup.GroupMember) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, "INSERT INTO group_members (group_id, user_id, role, notify, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (group_id, user_id) DO NOTHING", member.GroupID, member.UserID, member.Role, member.Notify, member.CreatedAt, member.UpdatedAt)
+	return mapErr(err)
+}
+
+func (r *groupRepository) RemoveGroupMember(ctx context.Context, groupID int64, userID string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, "DELETE FROM group_members WHERE group_id = $1 AND user_id = $2", groupID, userID)
+	return err
+}
+
+func (r *groupRepository) IsGroupMember(ctx context.Context, groupID int64, userID string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM group_members WHERE group_id = $1 AND user_id = $2)", groupID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *groupRepository) GetGroupMember(ctx context.Context, groupID int64, userID string) (*domaingroup.GroupMember, error) {
+	query := "SELECT id, user_id, group_id, role, notify, created_at, updated_at FROM group_members WHERE group_id = $1 AND user_id = $2"
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		// Keep the role from changing until the caller's transaction ends.
+		query += " FOR UPDATE"
+	}
+	row := conn(ctx, r.pool).QueryRow(ctx, query, groupID, userID)
+	var groupMember domaingroup.GroupMember
+	if err := row.Scan(&groupMember.ID, &groupMember.UserID, &groupMember.GroupID, &groupMember.Role, &groupMember.Notify, &groupMember.CreatedAt, &groupMember.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &groupMember, nil
+}
+
+func (r *groupRepository) UpdateGroupMemberRole(ctx context.Context, groupID int64, userID string, role domaingroup.Role) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE group_members SET role = $1, updated_at = now() WHERE group_id = $2 AND user_id = $3", role, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return respository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *groupRepository) UpdateGroupMemberNotify(ctx context.Context, groupID int64, userID string, level domaingroup.NotifyLevel) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE group_members SET notify = $1, updated_at = now() WHERE group_id = $2 AND user_id = $3", level, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return respository.ErrNotFound
+	}
+	return nil
 }
 
 func (r *groupRepository) ListGroupMembers(ctx context.Context, groupID int64, page int, pageSize int) ([]*domaingroup.GroupMember, error) {
-	rows, err := r.pool.Query(ctx, "SELECT id, user_id, group_id, created_at, updated_at FROM group_members WHERE group_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", groupID, pageSize, (page-1)*pageSize)
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT id, user_id, group_id, role, notify, created_at, updated_at FROM group_members WHERE group_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", groupID, pageSize, (page-1)*pageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +135,7 @@ func (r *groupRepository) ListGroupMembers(ctx context.Context, groupID int64, p
 	groupMembers := make([]*domaingroup.GroupMember, 0)
 	for rows.Next() {
 		var groupMember domaingroup.GroupMember
-		err := rows.Scan(&groupMember.ID, &groupMember.UserID, &groupMember.GroupID, &groupMember.CreatedAt, &groupMember.UpdatedAt)
+		err := rows.Scan(&groupMember.ID, &groupMember.UserID, &groupMember.GroupID, &groupMember.Role, &groupMember.Notify, &groupMember.CreatedAt, &groupMember.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -75,3 +143,92 @@ func (r *groupRepository) ListGroupMembers(ctx context.Context, groupID int64, p
 	}
 	return groupMembers, nil
 }
+
+func (r *groupRepository) ListGroupMemberIDs(ctx context.Context, groupID int64) ([]string, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT user_id FROM group_members WHERE group_id = $1", groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *groupRepository) ListMutedGroupMembers(ctx context.Context, groupID int64) (map[string]domaingroup.NotifyLevel, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT user_id, notify FROM group_members WHERE group_id = $1 AND notify <> $2", groupID, domaingroup.NotifyAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make(map[string]domaingroup.NotifyLevel)
+	for rows.Next() {
+		var (
+			id    string
+			level domaingroup.NotifyLevel
+		)
+		if err := rows.Scan(&id, &level); err != nil {
+			return nil, err
+		}
+		levels[id] = level
+	}
+	return levels, rows.Err()
+}
+
+func (r *groupRepository) CreateJoinRequest(ctx context.Context, request *domaingroup.GroupRequest) error {
+	row := conn(ctx, r.pool).QueryRow(ctx, "INSERT INTO group_requests (request_id, group_id, user_id, invited_by, status, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id", request.RequestID, request.GroupID, request.UserID, request.InvitedBy, request.Status, request.CreatedAt, request.UpdatedAt)
+	return mapErr(row.Scan(&request.ID))
+}
+
+func (r *groupRepository) GetJoinRequest(ctx context.Context, requestID string) (*domaingroup.GroupRequest, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT id, request_id, group_id, user_id, invited_by, status, created_at, updated_at FROM group_requests WHERE request_id = $1", requestID)
+	var request domaingroup.GroupRequest
+	if err := row.Scan(&request.ID, &request.RequestID, &request.GroupID, &request.UserID, &request.InvitedBy, &request.Status, &request.CreatedAt, &request.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &request, nil
+}
+
+func (r *groupRepository) ResolveJoinRequest(ctx context.Context, requestID string, status domaingroup.RequestStatus) (bool, error) {
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE group_requests SET status = $1, updated_at = now() WHERE request_id = $2 AND status = $3", status, requestID, domaingroup.RequestPending)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return true, nil
+	}
+	// Tell a missing request apart from one that was already resolved.
+	if _, err := r.GetJoinRequest(ctx, requestID); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func (r *groupRepository) HasPendingJoinRequest(ctx context.Context, groupID int64, userID string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM group_requests WHERE group_id = $1 AND user_id = $2 AND status = $3)", groupID, userID, domaingroup.RequestPending).Scan(&exists)
+	return exists, err
+}
+
+func scanGroups(rows pgx.Rows) ([]*domaingroup.Group, error) {
+	defer rows.Close()
+
+	groups := make([]*domaingroup.Group, 0)
+	for rows.Next() {
+		var group domaingroup.Group
+		err := rows.Scan(&group.ID, &group.UUID, &group.Name, &group.Owner, &group.CreatedAt, &group.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, &group)
+	}
+	return groups, rows.Err()
+}
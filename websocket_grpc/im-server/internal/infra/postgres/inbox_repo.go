@@ -2,12 +2,18 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	domaininbox "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// copyThreshold is the batch size from which CreateInboxes uses COPY.
+const copyThreshold = 64
+
 type inboxRepository struct {
 	pool *pgxpool.Pool
 }
@@ -17,29 +23,119 @@ func NewInboxRepository(pool *pgxpool.Pool) inbox.InboxRepository {
 }
 
 func (r *inboxRepository) CreateInbox(ctx context.Context, inbox *domaininbox.Inbox) error {
-	_, err := r.pool.Exec(ctx, "INSERT INTO inboxes (id, user_id, message_id, created_at) VALUES ($1, $2, $3, $4)", inbox.ID, inbox.UserID, inbox.MessageID, inbox.CreatedAt)
-	return err
+	row := conn(ctx, r.pool).QueryRow(ctx, "INSERT INTO inboxes (user_id, message_id, delivered, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id", inbox.UserID, inbox.MessageID, inbox.Delivered, inbox.CreatedAt, inbox.UpdatedAt)
+	return row.Scan(&inbox.ID)
+}
+
+// CreateInboxes inserts small batches in one statement and streams large ones
+// with COPY, which cannot return the IDs, so those are read back afterwards.
+func (r *inboxRepository) CreateInboxes(ctx context.Context, inboxes []*domaininbox.Inbox) error {
+	if len(inboxes) == 0 {
+		return nil
+	}
+	q := conn(ctx, r.pool)
+	if len(inboxes) < copyThreshold {
+		return r.insertInboxes(ctx, q, inboxes)
+	}
+
+	_, err := q.CopyFrom(ctx, pgx.Identifier{"inboxes"}, []string{"user_id", "message_id", "delivered", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(inboxes), func(i int) ([]any, error) {
+			in := inboxes[i]
+			return []any{in.UserID, in.MessageID, in.Delivered, in.CreatedAt, in.UpdatedAt}, nil
+		}))
+	if err != nil {
+		return mapErr(err)
+	}
+
+	messageIDs := make([]int64, 0, 1)
+	seen := make(map[int64]bool)
+	for _, in := range inboxes {
+		if !seen[in.MessageID] {
+			seen[in.MessageID] = true
+			messageIDs = append(messageIDs, in.MessageID)
+		}
+	}
+	rows, err := q.Query(ctx, "SELECT id, user_id, message_id FROM inboxes WHERE message_id = ANY($1)", messageIDs)
+	if err != nil {
+		return err
+	}
+	return assignInboxIDs(rows, inboxes)
+}
+
+func (r *inboxRepository) insertInboxes(ctx context.Context, q querier, inboxes []*domaininbox.Inbox) error {
+	userIDs := make([]string, len(inboxes))
+	messageIDs := make([]int64, len(inboxes))
+	delivered := make([]bool, len(inboxes))
+	createdAt := make([]time.Time, len(inboxes))
+	updatedAt := make([]time.Time, len(inboxes))
+	for i, in := range inboxes {
+		userIDs[i], messageIDs[i], delivered[i], createdAt[i], updatedAt[i] = in.UserID, in.MessageID, in.Delivered, in.CreatedAt, in.UpdatedAt
+	}
+	rows, err := q.Query(ctx, `INSERT INTO inboxes (user_id, message_id, delivered, created_at, updated_at)
+	SELECT * FROM unnest($1::text[], $2::bigint[], $3::boolean[], $4::timestamptz[], $5::timestamptz[])
+	RETURNING id, user_id, message_id`, userIDs, messageIDs, delivered, createdAt, updatedAt)
+	if err != nil {
+		return err
+	}
+	return assignInboxIDs(rows, inboxes)
+}
+
+// assignInboxIDs reads id, user_id, message_id rows and sets the IDs of the
+// matching entries; a user holds at most one entry per message.
+func assignInboxIDs(rows pgx.Rows, inboxes []*domaininbox.Inbox) error {
+	defer rows.Close()
+
+	type key struct {
+		userID    string
+		messageID int64
+	}
+	ids := make(map[key]int64, len(inboxes))
+	for rows.Next() {
+		var (
+			id int64
+			k  key
+		)
+		if err := rows.Scan(&id, &k.userID, &k.messageID); err != nil {
+			return err
+		}
+		ids[k] = id
+	}
+	if err := rows.Err(); err != nil {
+		return mapErr(err)
+	}
+	for _, in := range inboxes {
+		in.ID = ids[key{in.UserID, in.MessageID}]
+	}
+	return nil
 }
 
 func (r *inboxRepository) GetInbox(ctx context.Context, id int64) (*domaininbox.Inbox, error) {
-	row := r.pool.QueryRow(ctx, "SELECT id, user_id, message_id, created_at FROM inboxes WHERE id = $1", id)
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT id, user_id, message_id, delivered, created_at, updated_at FROM inboxes WHERE id = $1", id)
 	var inbox domaininbox.Inbox
-	err := row.Scan(&inbox.ID, &inbox.UserID, &inbox.MessageID, &inbox.CreatedAt)
-	return &inbox, err
+	if err := row.Scan(&inbox.ID, &inbox.UserID, &inbox.MessageID, &inbox.Delivered, &inbox.CreatedAt, &inbox.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &inbox, nil
 }
 
 func (r *inboxRepository) UpdateInbox(ctx context.Context, inbox *domaininbox.Inbox) error {
-	_, err := r.pool.Exec(ctx, "UPDATE inboxes SET user_id = $1, message_id = $2, created_at = $3 WHERE id = $4", inbox.UserID, inbox.MessageID, inbox.CreatedAt, inbox.ID)
-	return err
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE inboxes SET user_id = $1, message_id = $2, delivered = $3, created_at = $4, updated_at = $5 WHERE id = $6", inbox.UserID, inbox.MessageID, inbox.Delivered, inbox.CreatedAt, inbox.UpdatedAt, inbox.ID)
+	if err != nil {
+		return mapErr(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return respository.ErrNotFound
+	}
+	return nil
 }
 
 func (r *inboxRepository) DeleteInbox(ctx context.Context, id int64) error {
-	_, err := r.pool.Exec(ctx, "DELETE FROM inboxes WHERE id = $1", id)
+	_, err := conn(ctx, r.pool).Exec(ctx, "DELETE FROM inboxes WHERE id = $1", id)
 	return err
 }
 
-func (r *inboxRepository) ListInboxes(ctx context.Context, userID int64, page int, pageSize int) ([]*domaininbox.Inbox, error) {
-	rows, err := r.pool.Query(ctx, "SELECT id, user_id, message_id, created_at FROM inboxes WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", userID, pageSize, (page-1)*pageSize)
+func (r *inboxRepository) ListInboxes(ctx context.Context, userID string, page int, pageSize int) ([]*domaininbox.Inbox, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT id, user_id, message_id, delivered, created_at, updated_at FROM inboxes WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", userID, pageSize, (page-1)*pageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -48,7 +144,7 @@ func (r *inboxRepository) ListInboxes(ctx context.Context, userID int64, page in
 	inboxes := make([]*domaininbox.Inbox, 0)
 	for rows.Next() {
 		var inbox domaininbox.Inbox
-		err := rows.Scan(&inbox.ID, &inbox.UserID, &inbox.MessageID, &inbox.CreatedAt)
+		err := rows.Scan(&inbox.ID, &inbox.UserID, &inbox.MessageID, &inbox.Delivered, &inbox.CreatedAt, &inbox.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -56,3 +152,48 @@ func (r *inboxRepository) ListInboxes(ctx context.Context, userID int64, page in
 	}
 	return inboxes, nil
 }
+
+func (r *inboxRepository) ListUndelivered(ctx context.Context, userID string, afterID int64, limit int) ([]*domaininbox.Inbox, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT id, user_id, message_id, delivered, created_at, updated_at FROM inboxes WHERE user_id = $1 AND id > $2 AND NOT delivered ORDER BY id ASC LIMIT $3", userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inboxes := make([]*domaininbox.Inbox, 0)
+	for rows.Next() {
+		var inbox domaininbox.Inbox
+		err := rows.Scan(&inbox.ID, &inbox.UserID, &inbox.MessageID, &inbox.Delivered, &inbox.CreatedAt, &inbox.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		inboxes = append(inboxes, &inbox)
+	}
+	return inboxes, rows.Err()
+}
+
+func (r *inboxRepository) MarkDelivered(ctx context.Context, userID string, messageIDs ...int64) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+	_, err := conn(ctx, r.pool).Exec(ctx, "UPDATE inboxes SET delivered = TRUE, updated_at = now() WHERE user_id = $1 AND message_id = ANY($2) AND NOT delivered", userID, messageIDs)
+	return err
+}
+
+func (r *inboxRepository) Redeliver(ctx context.Context, messageID int64) ([]string, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "UPDATE inboxes SET delivered = FALSE, updated_at = now() WHERE message_id = $1 RETURNING user_id", messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := make([]string, 0)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
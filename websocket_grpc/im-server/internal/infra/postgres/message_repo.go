@@ -2,12 +2,18 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const messageColumns = "id, from_user, to_user, group_uuid, seq, content_type, content, created_at, edited_at, recalled_at"
+
 type messageRepository struct {
 	pool *pgxpool.Pool
 }
@@ -16,62 +22,127 @@ func NewMessageRepository(pool *pgxpool.Pool) message.MessageRepository {
 	return &messageRepository{pool: pool}
 }
 
+// CreateMessage bumps the conversation's counter in conversation_seqs and
+// inserts the message in one statement; the counter row lock serializes
+// concurrent senders of the same conversation.
 func (r *messageRepository) CreateMessage(ctx context.Context, message *domainmessage.Message) error {
-	_, err := r.pool.Exec(ctx, "INSERT INTO messages (id, from_user, to_user, group_id, content, created_at) VALUES ($1, $2, $3, $4, $5, $6)", message.ID, message.FromUser, message.ToUser, message.GroupID, message.Content, message.CreatedAt)
-	return err
+	row := conn(ctx, r.pool).QueryRow(ctx, `WITH s AS (
+		INSERT INTO conversation_seqs (conversation_id, seq) VALUES ($1, 1)
+		ON CONFLICT (conversation_id) DO UPDATE SET seq = conversation_seqs.seq + 1
+		RETURNING seq
+	)
+	INSERT INTO messages (conversation_id, seq, from_user, to_user, group_uuid, content_type, content, created_at)
+	SELECT $1, s.seq, $2, $3, $4, $5, $6, $7 FROM s RETURNING id, seq`,
+		message.ConversationID(), message.FromUser, message.ToUser, message.GroupUUID, message.ContentType, message.Content, message.CreatedAt)
+	return row.Scan(&message.ID, &message.Seq)
 }
 
 func (r *messageRepository) GetMessage(ctx context.Context, id int64) (*domainmessage.Message, error) {
-	row := r.pool.QueryRow(ctx, "SELECT id, from_user, to_user, group_id, content, created_at FROM messages WHERE id = $1", id)
-	var message domainmessage.Message
-	err := row.Scan(&message.ID, &message.FromUser, &message.ToUser, &message.GroupID, &message.Content, &message.CreatedAt)
-	return &message, err
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT "+messageColumns+" FROM messages WHERE id = $1", id)
+	message, err := scanMessage(row)
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return message, nil
 }
 
-func (r *messageRepository) UpdateMessage(ctx context.Context, message *domainmessage.Message) error {
-	_, err := r.pool.Exec(ctx, "UPDATE messages SET from_user = $1, to_user = $2, group_id = $3, content = $4, created_at = $5 WHERE id = $6", message.FromUser, message.ToUser, message.GroupID, message.Content, message.CreatedAt, message.ID)
-	return err
+// UpdateMessage rewrites the content and the edit and recall markers of a
+// message; its conversation, sequence and creation time never change.
+func (r *messageRepository) UpdateMessage(ctx context.Context, m *domainmessage.Message) error {
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE messages SET content_type = $1, content = $2, edited_at = $3, recalled_at = $4 WHERE id = $5 AND recalled_at IS NULL",
+		m.ContentType, m.Content, m.EditedAt, m.RecalledAt, m.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.notUpdated(ctx, m.ID)
+	}
+	return nil
+}
+
+// notUpdated tells why UpdateMessage changed no row.
+func (r *messageRepository) notUpdated(ctx context.Context, id int64) error {
+	var exists bool
+	if err := conn(ctx, r.pool).QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM messages WHERE id = $1)", id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return message.ErrRecalled
+	}
+	return respository.ErrNotFound
 }
 
 func (r *messageRepository) DeleteMessage(ctx context.Context, id int64) error {
-	_, err := r.pool.Exec(ctx, "DELETE FROM messages WHERE id = $1", id)
+	_, err := conn(ctx, r.pool).Exec(ctx, "DELETE FROM messages WHERE id = $1", id)
 	return err
 }
 
-func (r *messageRepository) ListMessages(ctx context.Context, userID int64, page int, pageSize int) ([]*domainmessage.Message, error) {
-	rows, err := r.pool.Query(ctx, "SELECT id, from_user, to_user, group_id, content, created_at FROM messages WHERE from_user = $1 OR to_user = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", userID, pageSize, (page-1)*pageSize)
+func (r *messageRepository) ListConversation(ctx context.Context, userA, userB string, cursor message.Cursor) ([]*domainmessage.Message, error) {
+	return r.page(ctx, "conversation_id = $1", cursor, domainmessage.PairConversation(userA, userB))
+}
+
+func (r *messageRepository) ListGroupMessages(ctx context.Context, groupUUID string, cursor message.Cursor) ([]*domainmessage.Message, error) {
+	return r.page(ctx, "conversation_id = $1", cursor, domainmessage.GroupConversation(groupUUID))
+}
+
+// page runs a keyset query over the messages matching where, whose
+// placeholders are bound to args.
+func (r *messageRepository) page(ctx context.Context, where string, cursor message.Cursor, args ...any) ([]*domainmessage.Message, error) {
+	n := len(args)
+	query := "SELECT " + messageColumns + " FROM messages WHERE " + where
+	if cursor.Direction == message.Forward {
+		query += fmt.Sprintf(" AND id > $%d ORDER BY id ASC LIMIT $%d", n+1, n+2)
+	} else {
+		query += fmt.Sprintf(" AND ($%d = 0 OR id < $%d) ORDER BY id DESC LIMIT $%d", n+1, n+1, n+2)
+	}
+	rows, err := conn(ctx, r.pool).Query(ctx, query, append(args, cursor.After, cursor.Limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return collectMessages(rows)
+}
+
+func (r *messageRepository) ListSeqRange(ctx context.Context, conversationID string, fromSeq, toSeq int64, limit int) ([]*domainmessage.Message, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, "SELECT "+messageColumns+" FROM messages WHERE conversation_id = $1 AND seq BETWEEN $2 AND $3 ORDER BY seq ASC LIMIT $4", conversationID, fromSeq, toSeq, limit)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return collectMessages(rows)
+}
 
-	messages := make([]*domainmessage.Message, 0)
-	for rows.Next() {
-		var message domainmessage.Message
-		err := rows.Scan(&message.ID, &message.FromUser, &message.ToUser, &message.GroupID, &message.Content, &message.CreatedAt)
-		if err != nil {
-			return nil, err
-		}
-		messages = append(messages, &message)
+func (r *messageRepository) CountAfter(ctx context.Context, conversationID string, afterSeq int64, excludeFrom string) (int64, error) {
+	var n int64
+	err := conn(ctx, r.pool).QueryRow(ctx, "SELECT count(*) FROM messages WHERE conversation_id = $1 AND seq > $2 AND from_user <> $3", conversationID, afterSeq, excludeFrom).Scan(&n)
+	return n, err
+}
+
+func (r *messageRepository) LastSeq(ctx context.Context, conversationID string) (int64, error) {
+	var seq int64
+	err := conn(ctx, r.pool).QueryRow(ctx, "SELECT seq FROM conversation_seqs WHERE conversation_id = $1", conversationID).Scan(&seq)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
 	}
-	return messages, nil
+	return seq, err
 }
 
-func (r *messageRepository) ListMessagesByGroupID(ctx context.Context, groupID int64, page int, pageSize int) ([]*domainmessage.Message, error) {
-	rows, err := r.pool.Query(ctx, "SELECT id, from_user, to_user, group_id, content, created_at FROM messages WHERE group_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3", groupID, pageSize, (page-1)*pageSize)
-	if err != nil {
+func scanMessage(row pgx.Row) (*domainmessage.Message, error) {
+	var message domainmessage.Message
+	if err := row.Scan(&message.ID, &message.FromUser, &message.ToUser, &message.GroupUUID, &message.Seq, &message.ContentType, &message.Content, &message.CreatedAt, &message.EditedAt, &message.RecalledAt); err != nil {
 		return nil, err
 	}
+	return &message, nil
+}
+
+func collectMessages(rows pgx.Rows) ([]*domainmessage.Message, error) {
 	defer rows.Close()
 
 	messages := make([]*domainmessage.Message, 0)
 	for rows.Next() {
-		var message domainmessage.Message
-		err := rows.Scan(&message.ID, &message.FromUser, &message.ToUser, &message.GroupID, &message.Content, &message.CreatedAt)
+		message, err := scanMessage(rows)
 		if err != nil {
 			return nil, err
 		}
-		messages = append(messages, &message)
+		messages = append(messages, message)
 	}
-	return messages, nil
+	return messages, rows.Err()
 }
@@ -0,0 +1,33 @@
+// Package postgres implements the repositories on top of pgx.
+package postgres
+
+import (
+	"errors"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+// mapErr translates pgx errors into the shared repository errors.
+func mapErr(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return respository.ErrNotFound
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case uniqueViolation:
+			return respository.ErrConflict
+		case foreignKeyViolation:
+			// The row refers to a group or message that does not exist.
+			return respository.ErrNotFound
+		}
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/presence"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type presenceDirectory struct {
+	pool *pgxpool.Pool
+}
+
+func NewPresenceDirectory(pool *pgxpool.Pool) presence.Directory {
+	return &presenceDirectory{pool: pool}
+}
+
+func (d *presenceDirectory) Register(ctx context.Context, userID, nodeID string) error {
+	_, err := conn(ctx, d.pool).Exec(ctx, "INSERT INTO presence (user_id, node_id, updated_at) VALUES ($1, $2, now()) ON CONFLICT (user_id, node_id) DO UPDATE SET updated_at = now()", userID, nodeID)
+	return err
+}
+
+func (d *presenceDirectory) Unregister(ctx context.Context, userID, nodeID string) error {
+	_, err := conn(ctx, d.pool).Exec(ctx, "DELETE FROM presence WHERE user_id = $1 AND node_id = $2", userID, nodeID)
+	return err
+}
+
+func (d *presenceDirectory) Nodes(ctx context.Context, userID string) ([]string, error) {
+	rows, err := conn(ctx, d.pool).Query(ctx, "SELECT node_id FROM presence WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := make([]string, 0)
+	for rows.Next() {
+		var node string
+		if err := rows.Scan(&node); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+func (d *presenceDirectory) ClearNode(ctx context.Context, nodeID string) error {
+	_, err := conn(ctx, d.pool).Exec(ctx, "DELETE FROM presence WHERE node_id = $1", nodeID)
+	return err
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/receipt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type readCursorRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReadCursorRepository(pool *pgxpool.Pool) receipt.ReadCursorRepository {
+	return &readCursorRepository{pool: pool}
+}
+
+func (r *readCursorRepository) AdvanceReadCursor(ctx context.Context, userID, conversationID string, seq int64) (bool, error) {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `INSERT INTO read_cursors (user_id, conversation_id, seq, updated_at) VALUES ($1, $2, $3, now())
+		ON CONFLICT (user_id, conversation_id) DO UPDATE SET seq = EXCLUDED.seq, updated_at = EXCLUDED.updated_at
+		WHERE read_cursors.seq < EXCLUDED.seq`, userID, conversationID, seq)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *readCursorRepository) GetReadCursor(ctx context.Context, userID, conversationID string) (int64, error) {
+	var seq int64
+	err := conn(ctx, r.pool).QueryRow(ctx, "SELECT seq FROM read_cursors WHERE user_id = $1 AND conversation_id = $2", userID, conversationID).Scan(&seq)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return seq, err
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	domaintoken "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/token"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/token"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTokenRepository(pool *pgxpool.Pool) token.TokenRepository {
+	return &tokenRepository{pool: pool}
+}
+
+func (r *tokenRepository) CreateToken(ctx context.Context, token *domaintoken.Token) error {
+	row := conn(ctx, r.pool).QueryRow(ctx, "INSERT INTO tokens (hash, user_id, device_id, platform, expires_at, revoked_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id", token.Hash, token.UserID, token.DeviceID, token.Platform, token.ExpiresAt, token.RevokedAt, token.CreatedAt)
+	return mapErr(row.Scan(&token.ID))
+}
+
+func (r *tokenRepository) GetToken(ctx context.Context, hash string) (*domaintoken.Token, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, "SELECT id, hash, user_id, device_id, platform, expires_at, revoked_at, created_at FROM tokens WHERE hash = $1", hash)
+	var token domaintoken.Token
+	if err := row.Scan(&token.ID, &token.Hash, &token.UserID, &token.DeviceID, &token.Platform, &token.ExpiresAt, &token.RevokedAt, &token.CreatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &token, nil
+}
+
+func (r *tokenRepository) RevokeToken(ctx context.Context, hash string) error {
+	_, err := conn(ctx, r.pool).Exec(ctx, "UPDATE tokens SET revoked_at = now() WHERE hash = $1 AND revoked_at IS NULL", hash)
+	return err
+}
+
+func (r *tokenRepository) RevokeUserTokens(ctx context.Context, userID, deviceID string) (int64, error) {
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE tokens SET revoked_at = now() WHERE user_id = $1 AND ($2 = '' OR device_id = $2) AND revoked_at IS NULL", userID, deviceID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -4,10 +4,15 @@ import (
 	"context"
 
 	domainuser "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/user"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Email and phone are optional; empty values are stored as NULL so the
+// unique indexes only apply to real addresses.
+const selectUser = "SELECT id, uuid, COALESCE(email, ''), COALESCE(phone, ''), name, password_hash, created_at, updated_at FROM users"
+
 type userRepository struct {
 	pool *pgxpool.Pool
 }
@@ -17,23 +22,47 @@ func NewUserRepository(pool *pgxpool.Pool) user.UserRepository {
 }
 
 func (r *userRepository) CreateUser(ctx context.Context, user *domainuser.User) error {
-	_, err := r.pool.Exec(ctx, "INSERT INTO users (id, uuid, email, phone, name, password, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)", user.ID, user.UUID, user.Email, user.Phone, user.Name, user.Password, user.CreatedAt, user.UpdatedAt)
-	return err
+	row := conn(ctx, r.pool).QueryRow(ctx, "INSERT INTO users (uuid, email, phone, name, password_hash, created_at, updated_at) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5, $6, $7) RETURNING id", user.UUID, user.Email, user.Phone, user.Name, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	return mapErr(row.Scan(&user.ID))
 }
 
 func (r *userRepository) GetUser(ctx context.Context, id int64) (*domainuser.User, error) {
-	row := r.pool.QueryRow(ctx, "SELECT id, uuid, email, phone, name, password, created_at, updated_at FROM users WHERE id = $1", id)
+	return r.getUser(ctx, selectUser+" WHERE id = $1", id)
+}
+
+func (r *userRepository) GetUserByUUID(ctx context.Context, uuid string) (*domainuser.User, error) {
+	return r.getUser(ctx, selectUser+" WHERE uuid = $1", uuid)
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*domainuser.User, error) {
+	return r.getUser(ctx, selectUser+" WHERE email = $1", email)
+}
+
+func (r *userRepository) GetUserByPhone(ctx context.Context, phone string) (*domainuser.User, error) {
+	return r.getUser(ctx, selectUser+" WHERE phone = $1", phone)
+}
+
+func (r *userRepository) getUser(ctx context.Context, query string, arg any) (*domainuser.User, error) {
+	row := conn(ctx, r.pool).QueryRow(ctx, query, arg)
 	var user domainuser.User
-	err := row.Scan(&user.ID, &user.UUID, &user.Email, &user.Phone, &user.Name, &user.Password, &user.CreatedAt, &user.UpdatedAt)
-	return &user, err
+	if err := row.Scan(&user.ID, &user.UUID, &user.Email, &user.Phone, &user.Name, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &user, nil
 }
 
 func (r *userRepository) UpdateUser(ctx context.Context, user *domainuser.User) error {
-	_, err := r.pool.Exec(ctx, "UPDATE users SET uuid = $1, email = $2, phone = $3, name = $4, password = $5, created_at = $6, updated_at = $7 WHERE id = $8", user.UUID, user.Email, user.Phone, user.Name, user.Password, user.CreatedAt, user.UpdatedAt, user.ID)
-	return err
+	tag, err := conn(ctx, r.pool).Exec(ctx, "UPDATE users SET uuid = $1, email = NULLIF($2, ''), phone = NULLIF($3, ''), name = $4, password_hash = $5, created_at = $6, updated_at = $7 WHERE id = $8", user.UUID, user.Email, user.Phone, user.Name, user.PasswordHash, user.CreatedAt, user.UpdatedAt, user.ID)
+	if err != nil {
+		return mapErr(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return respository.ErrNotFound
+	}
+	return nil
 }
 
 func (r *userRepository) DeleteUser(ctx context.Context, id int64) error {
-	_, err := r.pool.Exec(ctx, "DELETE FROM users WHERE id = $1", id)
+	_, err := conn(ctx, r.pool).Exec(ctx, "DELETE FROM users WHERE id = $1", id)
 	return err
 }
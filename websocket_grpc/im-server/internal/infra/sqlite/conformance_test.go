@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/respositorytest"
+)
+
+func TestRepositories_Conformance(t *testing.T) {
+	respositorytest.Run(t, func(t *testing.T) respositorytest.Repositories {
+		db, err := Open(filepath.Join(t.TempDir(), "im.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		return respositorytest.Repositories{
+			Users:      NewUserRepository(db),
+			Groups:     NewGroupRepository(db),
+			Messages:   NewMessageRepository(db),
+			Inboxes:    NewInboxRepository(db),
+			Transactor: NewTransactor(db),
+		}
+	})
+}
+
+func TestOpen_InMemory(t *testing.T) {
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var on int
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&on); err != nil || on != 1 {
+		t.Fatalf("foreign keys are off: %d, %v", on, err)
+	}
+}
@@ -0,0 +1,220 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/group"
+)
+
+const (
+	groupColumns   = "id, uuid, name, owner, created_at, updated_at"
+	memberColumns  = "id, user_id, group_id, role, notify, created_at, updated_at"
+	requestColumns = "id, request_id, group_id, user_id, invited_by, status, created_at, updated_at"
+)
+
+type groupRepository struct {
+	db *sql.DB
+}
+
+func NewGroupRepository(db *sql.DB) group.GroupRepository {
+	return &groupRepository{db: db}
+}
+
+func (r *groupRepository) CreateGroup(ctx context.Context, group *domaingroup.Group) error {
+	row := conn(ctx, r.db).QueryRowContext(ctx, "INSERT INTO groups (uuid, name, owner, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id", group.UUID, group.Name, group.Owner, group.CreatedAt, group.UpdatedAt)
+	return mapErr(row.Scan(&group.ID))
+}
+
+func (r *groupRepository) GetGroup(ctx context.Context, id int64) (*domaingroup.Group, error) {
+	return scanGroup(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+groupColumns+" FROM groups WHERE id = ?", id))
+}
+
+func (r *groupRepository) GetGroupByUUID(ctx context.Context, uuid string) (*domaingroup.Group, error) {
+	return scanGroup(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+groupColumns+" FROM groups WHERE uuid = ?", uuid))
+}
+
+func (r *groupRepository) UpdateGroup(ctx context.Context, group *domaingroup.Group) error {
+	return checkAffected(conn(ctx, r.db).ExecContext(ctx, "UPDATE groups SET uuid = ?, name = ?, owner = ?, created_at = ?, updated_at = ? WHERE id = ?", group.UUID, group.Name, group.Owner, group.CreatedAt, group.UpdatedAt, group.ID))
+}
+
+// DeleteGroup also drops the members and join requests of the group.
+func (r *groupRepository) DeleteGroup(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM groups WHERE id = ?", id)
+	return err
+}
+
+func (r *groupRepository) ListGroups(ctx context.Context, page int, pageSize int) ([]*domaingroup.Group, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+groupColumns+" FROM groups ORDER BY id DESC LIMIT ? OFFSET ?", pageSize, offset(page, pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return scanGroups(rows)
+}
+
+// ListUserGroups returns the groups userID joined last first.
+func (r *groupRepository) ListUserGroups(ctx context.Context, userID string, page int, pageSize int) ([]*domaingroup.Group, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT g.id, g.uuid, g.name, g.owner, g.created_at, g.updated_at FROM groups g JOIN group_members m ON m.group_id = g.id WHERE m.user_id = ? ORDER BY m.id DESC LIMIT ? OFFSET ?", userID, pageSize, offset(page, pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return scanGroups(rows)
+}
+
+func (r *groupRepository) AddGroupMember(ctx context.Context, member *domaingroup.GroupMember) error {
+	q := conn(ctx, r.db)
+	_, err := q.ExecContext(ctx, "INSERT INTO group_members (group_id, user_id, role, notify, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (group_id, user_id) DO NOTHING", member.GroupID, member.UserID, member.Role, member.Notify, member.CreatedAt, member.UpdatedAt)
+	if err != nil {
+		return mapErr(err)
+	}
+	return mapErr(q.QueryRowContext(ctx, "SELECT id FROM group_members WHERE group_id = ? AND user_id = ?", member.GroupID, member.UserID).Scan(&member.ID))
+}
+
+func (r *groupRepository) RemoveGroupMember(ctx context.Context, groupID int64, userID string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM group_members WHERE group_id = ? AND user_id = ?", groupID, userID)
+	return err
+}
+
+func (r *groupRepository) IsGroupMember(ctx context.Context, groupID int64, userID string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM group_members WHERE group_id = ? AND user_id = ?)", groupID, userID).Scan(&exists)
+	return exists, err
+}
+
+func (r *groupRepository) GetGroupMember(ctx context.Context, groupID int64, userID string) (*domaingroup.GroupMember, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+memberColumns+" FROM group_members WHERE group_id = ? AND user_id = ?", groupID, userID)
+	var member domaingroup.GroupMember
+	if err := row.Scan(&member.ID, &member.UserID, &member.GroupID, &member.Role, &member.Notify, &member.CreatedAt, &member.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &member, nil
+}
+
+func (r *groupRepository) UpdateGroupMemberRole(ctx context.Context, groupID int64, userID string, role domaingroup.Role) error {
+	return checkAffected(conn(ctx, r.db).ExecContext(ctx, "UPDATE group_members SET role = ?, updated_at = ? WHERE group_id = ? AND user_id = ?", role, time.Now(), groupID, userID))
+}
+
+func (r *groupRepository) UpdateGroupMemberNotify(ctx context.Context, groupID int64, userID string, level domaingroup.NotifyLevel) error {
+	return checkAffected(conn(ctx, r.db).ExecContext(ctx, "UPDATE group_members SET notify = ?, updated_at = ? WHERE group_id = ? AND user_id = ?", level, time.Now(), groupID, userID))
+}
+
+func (r *groupRepository) ListGroupMembers(ctx context.Context, groupID int64, page int, pageSize int) ([]*domaingroup.GroupMember, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+memberColumns+" FROM group_members WHERE group_id = ? ORDER BY id DESC LIMIT ? OFFSET ?", groupID, pageSize, offset(page, pageSize))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]*domaingroup.GroupMember, 0)
+	for rows.Next() {
+		var member domaingroup.GroupMember
+		if err := rows.Scan(&member.ID, &member.UserID, &member.GroupID, &member.Role, &member.Notify, &member.CreatedAt, &member.UpdatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+	return members, rows.Err()
+}
+
+func (r *groupRepository) ListGroupMemberIDs(ctx context.Context, groupID int64) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT user_id FROM group_members WHERE group_id = ? ORDER BY user_id", groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *groupRepository) ListMutedGroupMembers(ctx context.Context, groupID int64) (map[string]domaingroup.NotifyLevel, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT user_id, notify FROM group_members WHERE group_id = ? AND notify <> ?", groupID, domaingroup.NotifyAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make(map[string]domaingroup.NotifyLevel)
+	for rows.Next() {
+		var (
+			id    string
+			level domaingroup.NotifyLevel
+		)
+		if err := rows.Scan(&id, &level); err != nil {
+			return nil, err
+		}
+		levels[id] = level
+	}
+	return levels, rows.Err()
+}
+
+func (r *groupRepository) CreateJoinRequest(ctx context.Context, request *domaingroup.GroupRequest) error {
+	row := conn(ctx, r.db).QueryRowContext(ctx, "INSERT INTO group_requests (request_id, group_id, user_id, invited_by, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id", request.RequestID, request.GroupID, request.UserID, request.InvitedBy, request.Status, request.CreatedAt, request.UpdatedAt)
+	return mapErr(row.Scan(&request.ID))
+}
+
+func (r *groupRepository) GetJoinRequest(ctx context.Context, requestID string) (*domaingroup.GroupRequest, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+requestColumns+" FROM group_requests WHERE request_id = ?", requestID)
+	var request domaingroup.GroupRequest
+	if err := row.Scan(&request.ID, &request.RequestID, &request.GroupID, &request.UserID, &request.InvitedBy, &request.Status, &request.CreatedAt, &request.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &request, nil
+}
+
+func (r *groupRepository) ResolveJoinRequest(ctx context.Context, requestID string, status domaingroup.RequestStatus) (bool, error) {
+	err := checkAffected(conn(ctx, r.db).ExecContext(ctx, "UPDATE group_requests SET status = ?, updated_at = ? WHERE request_id = ? AND status = ?", status, time.Now(), requestID, domaingroup.RequestPending))
+	if err == nil {
+		return true, nil
+	}
+	// Tell a missing request apart from one that was already resolved.
+	if _, err := r.GetJoinRequest(ctx, requestID); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+func (r *groupRepository) HasPendingJoinRequest(ctx context.Context, groupID int64, userID string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM group_requests WHERE group_id = ? AND user_id = ? AND status = ?)", groupID, userID, domaingroup.RequestPending).Scan(&exists)
+	return exists, err
+}
+
+func scanGroup(row *sql.Row) (*domaingroup.Group, error) {
+	var group domaingroup.Group
+	if err := row.Scan(&group.ID, &group.UUID, &group.Name, &group.Owner, &group.CreatedAt, &group.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &group, nil
+}
+
+func scanGroups(rows *sql.Rows) ([]*domaingroup.Group, error) {
+	defer rows.Close()
+
+	groups := make([]*domaingroup.Group, 0)
+	for rows.Next() {
+		var group domaingroup.Group
+		if err := rows.Scan(&group.ID, &group.UUID, &group.Name, &group.Owner, &group.CreatedAt, &group.UpdatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, &group)
+	}
+	return groups, rows.Err()
+}
+
+// offset is the number of rows before page (1-based).
+func offset(page, pageSize int) int {
+	if page < 1 {
+		return 0
+	}
+	return (page - 1) * pageSize
+}
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	domaininbox "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+)
+
+const inboxColumns = "id, user_id, message_id, delivered, created_at, updated_at"
+
+type inboxRepository struct {
+	db *sql.DB
+	tx *transactor
+}
+
+func NewInboxRepository(db *sql.DB) inbox.InboxRepository {
+	return &inboxRepository{db: db, tx: &transactor{db: db}}
+}
+
+func (r *inboxRepository) CreateInbox(ctx context.Context, inbox *domaininbox.Inbox) error {
+	row := conn(ctx, r.db).QueryRowContext(ctx, "INSERT INTO inboxes (user_id, message_id, delivered, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id", inbox.UserID, inbox.MessageID, inbox.Delivered, inbox.CreatedAt, inbox.UpdatedAt)
+	return mapErr(row.Scan(&inbox.ID))
+}
+
+// CreateInboxes reuses one prepared insert within a transaction, which is
+// about as fast as SQLite gets without a bulk loading API.
+func (r *inboxRepository) CreateInboxes(ctx context.Context, inboxes []*domaininbox.Inbox) error {
+	if len(inboxes) == 0 {
+		return nil
+	}
+	return r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tx := ctx.Value(txKey{}).(*sql.Tx)
+		stmt, err := tx.PrepareContext(ctx, "INSERT INTO inboxes (user_id, message_id, delivered, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, in := range inboxes {
+			if err := stmt.QueryRowContext(ctx, in.UserID, in.MessageID, in.Delivered, in.CreatedAt, in.UpdatedAt).Scan(&in.ID); err != nil {
+				return mapErr(err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *inboxRepository) GetInbox(ctx context.Context, id int64) (*domaininbox.Inbox, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+inboxColumns+" FROM inboxes WHERE id = ?", id)
+	var inbox domaininbox.Inbox
+	if err := row.Scan(&inbox.ID, &inbox.UserID, &inbox.MessageID, &inbox.Delivered, &inbox.CreatedAt, &inbox.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &inbox, nil
+}
+
+func (r *inboxRepository) UpdateInbox(ctx context.Context, inbox *domaininbox.Inbox) error {
+	return checkAffected(conn(ctx, r.db).ExecContext(ctx, "UPDATE inboxes SET user_id = ?, message_id = ?, delivered = ?, created_at = ?, updated_at = ? WHERE id = ?", inbox.UserID, inbox.MessageID, inbox.Delivered, inbox.CreatedAt, inbox.UpdatedAt, inbox.ID))
+}
+
+func (r *inboxRepository) DeleteInbox(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM inboxes WHERE id = ?", id)
+	return err
+}
+
+func (r *inboxRepository) ListInboxes(ctx context.Context, userID string, page int, pageSize int) ([]*domaininbox.Inbox, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+inboxColumns+" FROM inboxes WHERE user_id = ? ORDER BY id DESC LIMIT ? OFFSET ?", userID, pageSize, offset(page, pageSize))
+	if err != nil {
+		return nil, err
+	}
+	return scanInboxes(rows)
+}
+
+func (r *inboxRepository) ListUndelivered(ctx context.Context, userID string, afterID int64, limit int) ([]*domaininbox.Inbox, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+inboxColumns+" FROM inboxes WHERE user_id = ? AND id > ? AND NOT delivered ORDER BY id ASC LIMIT ?", userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanInboxes(rows)
+}
+
+func (r *inboxRepository) MarkDelivered(ctx context.Context, userID string, messageIDs ...int64) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+	args := make([]any, 0, len(messageIDs)+2)
+	args = append(args, time.Now(), userID)
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE inboxes SET delivered = TRUE, updated_at = ? WHERE user_id = ? AND NOT delivered AND message_id IN ("+placeholders(len(messageIDs))+")", args...)
+	return err
+}
+
+func (r *inboxRepository) Redeliver(ctx context.Context, messageID int64) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "UPDATE inboxes SET delivered = FALSE, updated_at = ? WHERE message_id = ? RETURNING user_id", time.Now(), messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	userIDs := make([]string, 0)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, rows.Err()
+}
+
+func scanInboxes(rows *sql.Rows) ([]*domaininbox.Inbox, error) {
+	defer rows.Close()
+
+	inboxes := make([]*domaininbox.Inbox, 0)
+	for rows.Next() {
+		var inbox domaininbox.Inbox
+		if err := rows.Scan(&inbox.ID, &inbox.UserID, &inbox.MessageID, &inbox.Delivered, &inbox.CreatedAt, &inbox.UpdatedAt); err != nil {
+			return nil, err
+		}
+		inboxes = append(inboxes, &inbox)
+	}
+	return inboxes, rows.Err()
+}
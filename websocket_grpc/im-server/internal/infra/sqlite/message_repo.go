@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+)
+
+const messageColumns = "id, from_user, to_user, group_uuid, seq, content_type, content, created_at, edited_at, recalled_at"
+
+type messageRepository struct {
+	db *sql.DB
+	tx *transactor
+}
+
+func NewMessageRepository(db *sql.DB) message.MessageRepository {
+	return &messageRepository{db: db, tx: &transactor{db: db}}
+}
+
+// CreateMessage bumps the conversation's counter in conversation_seqs and
+// inserts the message in one transaction, joining the caller's if any.
+func (r *messageRepository) CreateMessage(ctx context.Context, message *domainmessage.Message) error {
+	return r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		q := conn(ctx, r.db)
+		conversationID := message.ConversationID()
+		err := q.QueryRowContext(ctx, `INSERT INTO conversation_seqs (conversation_id, seq) VALUES (?, 1)
+			ON CONFLICT (conversation_id) DO UPDATE SET seq = seq + 1 RETURNING seq`, conversationID).Scan(&message.Seq)
+		if err != nil {
+			return err
+		}
+		row := q.QueryRowContext(ctx, `INSERT INTO messages (conversation_id, seq, from_user, to_user, group_uuid, content_type, content, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`,
+			conversationID, message.Seq, message.FromUser, message.ToUser, message.GroupUUID, message.ContentType, message.Content, message.CreatedAt)
+		return mapErr(row.Scan(&message.ID))
+	})
+}
+
+func (r *messageRepository) GetMessage(ctx context.Context, id int64) (*domainmessage.Message, error) {
+	message, err := scanMessage(conn(ctx, r.db).QueryRowContext(ctx, "SELECT "+messageColumns+" FROM messages WHERE id = ?", id))
+	if err != nil {
+		return nil, mapErr(err)
+	}
+	return message, nil
+}
+
+// UpdateMessage rewrites the content and the edit and recall markers of a
+// message; its conversation, sequence and creation time never change.
+func (r *messageRepository) UpdateMessage(ctx context.Context, m *domainmessage.Message) error {
+	err := checkAffected(conn(ctx, r.db).ExecContext(ctx, "UPDATE messages SET content_type = ?, content = ?, edited_at = ?, recalled_at = ? WHERE id = ? AND recalled_at IS NULL",
+		m.ContentType, m.Content, m.EditedAt, m.RecalledAt, m.ID))
+	if !errors.Is(err, respository.ErrNotFound) {
+		return err
+	}
+	var exists bool
+	if err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM messages WHERE id = ?)", m.ID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return message.ErrRecalled
+	}
+	return respository.ErrNotFound
+}
+
+func (r *messageRepository) DeleteMessage(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM messages WHERE id = ?", id)
+	return err
+}
+
+func (r *messageRepository) ListConversation(ctx context.Context, userA, userB string, cursor message.Cursor) ([]*domainmessage.Message, error) {
+	return r.page(ctx, domainmessage.PairConversation(userA, userB), cursor)
+}
+
+func (r *messageRepository) ListGroupMessages(ctx context.Context, groupUUID string, cursor message.Cursor) ([]*domainmessage.Message, error) {
+	return r.page(ctx, domainmessage.GroupConversation(groupUUID), cursor)
+}
+
+// page runs a keyset query over the messages of conversationID.
+func (r *messageRepository) page(ctx context.Context, conversationID string, cursor message.Cursor) ([]*domainmessage.Message, error) {
+	query := "SELECT " + messageColumns + " FROM messages WHERE conversation_id = ?1"
+	if cursor.Direction == message.Forward {
+		query += " AND id > ?2 ORDER BY id ASC LIMIT ?3"
+	} else {
+		query += " AND (?2 = 0 OR id < ?2) ORDER BY id DESC LIMIT ?3"
+	}
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, conversationID, cursor.After, cursor.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectMessages(rows)
+}
+
+func (r *messageRepository) ListSeqRange(ctx context.Context, conversationID string, fromSeq, toSeq int64, limit int) ([]*domainmessage.Message, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, "SELECT "+messageColumns+" FROM messages WHERE conversation_id = ? AND seq BETWEEN ? AND ? ORDER BY seq ASC LIMIT ?", conversationID, fromSeq, toSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collectMessages(rows)
+}
+
+func (r *messageRepository) CountAfter(ctx context.Context, conversationID string, afterSeq int64, excludeFrom string) (int64, error) {
+	var n int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT count(*) FROM messages WHERE conversation_id = ? AND seq > ? AND from_user <> ?", conversationID, afterSeq, excludeFrom).Scan(&n)
+	return n, err
+}
+
+func (r *messageRepository) LastSeq(ctx context.Context, conversationID string) (int64, error) {
+	var seq int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, "SELECT seq FROM conversation_seqs WHERE conversation_id = ?", conversationID).Scan(&seq)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return seq, err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMessage(row scanner) (*domainmessage.Message, error) {
+	var message domainmessage.Message
+	if err := row.Scan(&message.ID, &message.FromUser, &message.ToUser, &message.GroupUUID, &message.Seq, &message.ContentType, &message.Content, &message.CreatedAt, &message.EditedAt, &message.RecalledAt); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func collectMessages(rows *sql.Rows) ([]*domainmessage.Message, error) {
+	defer rows.Close()
+
+	messages := make([]*domainmessage.Message, 0)
+	for rows.Next() {
+		message, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
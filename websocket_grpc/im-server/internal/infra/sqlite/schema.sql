@@ -0,0 +1,79 @@
+-- The SQLite counterpart of the PostgreSQL tables behind the user, group,
+-- message and inbox repositories. Times are TIMESTAMP so the driver reads
+-- them back as time.Time.
+CREATE TABLE IF NOT EXISTS users (
+    id            INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid          TEXT NOT NULL UNIQUE,
+    email         TEXT UNIQUE,
+    phone         TEXT UNIQUE,
+    name          TEXT NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at    TIMESTAMP NOT NULL,
+    updated_at    TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS groups (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid       TEXT NOT NULL UNIQUE,
+    name       TEXT NOT NULL,
+    owner      TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS group_members (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id   INTEGER NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL,
+    role       INTEGER NOT NULL,
+    notify     INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS group_members_user_id_idx ON group_members (user_id);
+
+CREATE TABLE IF NOT EXISTS group_requests (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    request_id TEXT NOT NULL UNIQUE,
+    group_id   INTEGER NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL,
+    invited_by TEXT NOT NULL DEFAULT '',
+    status     INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS group_requests_member_idx ON group_requests (group_id, user_id, status);
+
+CREATE TABLE IF NOT EXISTS conversation_seqs (
+    conversation_id TEXT PRIMARY KEY,
+    seq             INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    conversation_id TEXT NOT NULL,
+    seq             INTEGER NOT NULL,
+    from_user       TEXT NOT NULL,
+    to_user         TEXT NOT NULL DEFAULT '',
+    group_uuid      TEXT NOT NULL DEFAULT '',
+    content_type    TEXT NOT NULL DEFAULT '',
+    content         BLOB,
+    created_at      TIMESTAMP NOT NULL,
+    edited_at       TIMESTAMP,
+    recalled_at     TIMESTAMP,
+    UNIQUE (conversation_id, seq)
+);
+CREATE INDEX IF NOT EXISTS messages_conversation_id_idx ON messages (conversation_id, id);
+
+CREATE TABLE IF NOT EXISTS inboxes (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id    TEXT NOT NULL,
+    message_id INTEGER NOT NULL REFERENCES messages (id) ON DELETE CASCADE,
+    delivered  BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, message_id)
+);
+CREATE INDEX IF NOT EXISTS inboxes_pending_idx ON inboxes (user_id, id) WHERE NOT delivered;
+CREATE INDEX IF NOT EXISTS inboxes_message_id_idx ON inboxes (message_id);
@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	domainuser "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/user"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+)
+
+// Empty email and phone values are stored as NULL so the unique indexes only
+// apply to real addresses.
+const selectUser = "SELECT id, uuid, COALESCE(email, ''), COALESCE(phone, ''), name, password_hash, created_at, updated_at FROM users"
+
+type userRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) user.UserRepository {
+	return &userRepository{db: db}
+}
+
+func (r *userRepository) CreateUser(ctx context.Context, user *domainuser.User) error {
+	row := conn(ctx, r.db).QueryRowContext(ctx, "INSERT INTO users (uuid, email, phone, name, password_hash, created_at, updated_at) VALUES (?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?, ?) RETURNING id", user.UUID, user.Email, user.Phone, user.Name, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	return mapErr(row.Scan(&user.ID))
+}
+
+func (r *userRepository) GetUser(ctx context.Context, id int64) (*domainuser.User, error) {
+	return r.getUser(ctx, selectUser+" WHERE id = ?", id)
+}
+
+func (r *userRepository) GetUserByUUID(ctx context.Context, uuid string) (*domainuser.User, error) {
+	return r.getUser(ctx, selectUser+" WHERE uuid = ?", uuid)
+}
+
+func (r *userRepository) GetUserByEmail(ctx context.Context, email string) (*domainuser.User, error) {
+	return r.getUser(ctx, selectUser+" WHERE email = ?", email)
+}
+
+func (r *userRepository) GetUserByPhone(ctx context.Context, phone string) (*domainuser.User, error) {
+	return r.getUser(ctx, selectUser+" WHERE phone = ?", phone)
+}
+
+func (r *userRepository) getUser(ctx context.Context, query string, arg any) (*domainuser.User, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, arg)
+	var user domainuser.User
+	if err := row.Scan(&user.ID, &user.UUID, &user.Email, &user.Phone, &user.Name, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		return nil, mapErr(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdateUser(ctx context.Context, user *domainuser.User) error {
+	return checkAffected(conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET uuid = ?, email = NULLIF(?, ''), phone = NULLIF(?, ''), name = ?, password_hash = ?, created_at = ?, updated_at = ? WHERE id = ?", user.UUID, user.Email, user.Phone, user.Name, user.PasswordHash, user.CreatedAt, user.UpdatedAt, user.ID))
+}
+
+func (r *userRepository) DeleteUser(ctx context.Context, id int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM users WHERE id = ?", id)
+	return err
+}
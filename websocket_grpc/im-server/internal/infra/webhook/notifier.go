@@ -0,0 +1,93 @@
+// Package webhook forwards push notifications to an HTTP relay in front of
+// the APNs and FCM gateways.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body when a
+// secret is configured, so the relay can tell the requests are ours.
+const SignatureHeader = "X-IM-Signature"
+
+const defaultTimeout = 10 * time.Second
+
+type Options struct {
+	// URL receives a POST per batch with the body {"notifications": [...]}.
+	URL string
+	// Secret signs the request bodies; nothing is signed when empty.
+	Secret []byte
+	// Timeout bounds a request; 10 seconds by default.
+	Timeout time.Duration
+	Client  *http.Client
+}
+
+type notifier struct {
+	options Options
+}
+
+func NewNotifier(options Options) push.Notifier {
+	if options.Timeout <= 0 {
+		options.Timeout = defaultTimeout
+	}
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+	return &notifier{options: options}
+}
+
+// Notify posts batch to the relay. Server errors, 408 and 429 may be
+// retried; any other non-2xx answer fails with push.ErrRejected.
+func (n *notifier) Notify(ctx context.Context, batch []push.Notification) error {
+	body, err := json.Marshal(struct {
+		Notifications []push.Notification `json:"notifications"`
+	}{batch})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.options.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.options.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.options.Secret) > 0 {
+		mac := hmac.New(sha256.New, n.options.Secret)
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.options.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return fmt.Errorf("push relay answered %s", resp.Status)
+	default:
+		return fmt.Errorf("%w: push relay answered %s", push.ErrRejected, resp.Status)
+	}
+}
+
+func (n *notifier) Close() error {
+	return nil
+}
@@ -27,6 +27,11 @@ var (
 		Name: "ws_bad_proto",
 		Help: "Number of WebSocket connections with bad protocol",
 	})
+
+	WSRetransmits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "ws_retransmits",
+		Help: "Number of deliveries resent because the client did not acknowledge them",
+	})
 )
 
 func init() {
@@ -34,6 +39,7 @@ func init() {
 	prometheus.MustRegister(WSReadFrames)
 	prometheus.MustRegister(WSSentFrames)
 	prometheus.MustRegister(WSBadProto)
+	prometheus.MustRegister(WSRetransmits)
 }
 
 func MetricsHandler() http.Handler {
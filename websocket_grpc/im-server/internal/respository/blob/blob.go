@@ -0,0 +1,23 @@
+package blob
+
+import (
+	"context"
+	"io"
+
+	domainblob "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/blob"
+)
+
+type BlobStore interface {
+	// Put stores the content read from r on behalf of owner. Content that is
+	// already stored is not written again: the existing blob, with its
+	// original content type, is returned and owner is added to it. If r
+	// fails, nothing is stored and its error is returned.
+	Put(ctx context.Context, owner, contentType string, r io.Reader) (*domainblob.Blob, error)
+	Stat(ctx context.Context, id string) (*domainblob.Blob, error)
+	// Open returns the content of blob id; the caller closes it.
+	Open(ctx context.Context, id string) (io.ReadSeekCloser, *domainblob.Blob, error)
+	// Usage returns the total size of the distinct blobs owner uploaded.
+	Usage(ctx context.Context, owner string) (int64, error)
+	// Owns reports whether owner uploaded blob id.
+	Owns(ctx context.Context, owner, id string) (bool, error)
+}
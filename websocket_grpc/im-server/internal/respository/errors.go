@@ -0,0 +1,11 @@
+// Package respository holds the errors shared by every repository implementation.
+// The repository interfaces themselves live in the sub-packages.
+package respository
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a record with the same unique key exists.
+	ErrConflict = errors.New("already exists")
+)
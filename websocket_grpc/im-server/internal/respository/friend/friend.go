@@ -0,0 +1,23 @@
+package friend
+
+import (
+	"context"
+
+	domainfriend "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/friend"
+)
+
+type FriendRepository interface {
+	// CreateRequest fails with respository.ErrConflict if RequestID is taken.
+	CreateRequest(ctx context.Context, request *domainfriend.FriendRequest) error
+	GetRequest(ctx context.Context, requestID string) (*domainfriend.FriendRequest, error)
+	// ResolveRequest moves a pending request to status and reports whether
+	// it was still pending.
+	ResolveRequest(ctx context.Context, requestID string, status domainfriend.RequestStatus) (bool, error)
+	HasPendingRequest(ctx context.Context, fromUser, toUser string) (bool, error)
+	// AddFriend stores the friendship in both directions; it is idempotent.
+	AddFriend(ctx context.Context, userID, friendID string) error
+	IsFriend(ctx context.Context, userID, friendID string) (bool, error)
+	ListFriends(ctx context.Context, userID string, page int, pageSize int) ([]*domainfriend.Friend, error)
+	// ListFriendIDs returns every friend of userID, for fan-out.
+	ListFriendIDs(ctx context.Context, userID string) ([]string, error)
+}
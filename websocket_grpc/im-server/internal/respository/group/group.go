@@ -7,10 +7,38 @@ import (
 )
 
 type GroupRepository interface {
+	// CreateGroup assigns the group ID and fails with respository.ErrConflict
+	// if the UUID is taken.
 	CreateGroup(ctx context.Context, group *domaingroup.Group) error
 	GetGroup(ctx context.Context, id int64) (*domaingroup.Group, error)
+	GetGroupByUUID(ctx context.Context, uuid string) (*domaingroup.Group, error)
 	UpdateGroup(ctx context.Context, group *domaingroup.Group) error
 	DeleteGroup(ctx context.Context, id int64) error
 	ListGroups(ctx context.Context, page int, pageSize int) ([]*domaingroup.Group, error)
+	// ListUserGroups returns the groups userID is a member of.
+	ListUserGroups(ctx context.Context, userID string, page int, pageSize int) ([]*domaingroup.Group, error)
+	// AddGroupMember is idempotent.
+	AddGroupMember(ctx context.Context, member *domaingroup.GroupMember) error
+	RemoveGroupMember(ctx context.Context, groupID int64, userID string) error
+	IsGroupMember(ctx context.Context, groupID int64, userID string) (bool, error)
+	// GetGroupMember fails with respository.ErrNotFound if userID is not a
+	// member. Within a transaction, stores that can keep the member from
+	// changing until it ends, so a role check holds for the whole unit.
+	GetGroupMember(ctx context.Context, groupID int64, userID string) (*domaingroup.GroupMember, error)
+	UpdateGroupMemberRole(ctx context.Context, groupID int64, userID string, role domaingroup.Role) error
+	UpdateGroupMemberNotify(ctx context.Context, groupID int64, userID string, level domaingroup.NotifyLevel) error
 	ListGroupMembers(ctx context.Context, groupID int64, page int, pageSize int) ([]*domaingroup.GroupMember, error)
+	// ListGroupMemberIDs returns every member, for fan-out.
+	ListGroupMemberIDs(ctx context.Context, groupID int64) ([]string, error)
+	// ListMutedGroupMembers returns the notification level of every member
+	// that does not use domaingroup.NotifyAll.
+	ListMutedGroupMembers(ctx context.Context, groupID int64) (map[string]domaingroup.NotifyLevel, error)
+
+	// CreateJoinRequest fails with respository.ErrConflict if RequestID is taken.
+	CreateJoinRequest(ctx context.Context, request *domaingroup.GroupRequest) error
+	GetJoinRequest(ctx context.Context, requestID string) (*domaingroup.GroupRequest, error)
+	// ResolveJoinRequest moves a pending request to status and reports
+	// whether it was still pending.
+	ResolveJoinRequest(ctx context.Context, requestID string, status domaingroup.RequestStatus) (bool, error)
+	HasPendingJoinRequest(ctx context.Context, groupID int64, userID string) (bool, error)
 }
@@ -8,8 +8,18 @@ import (
 
 type InboxRepository interface {
 	CreateInbox(ctx context.Context, inbox *domaininbox.Inbox) error
+	// CreateInboxes stores several entries at once and sets their IDs.
+	CreateInboxes(ctx context.Context, inboxes []*domaininbox.Inbox) error
 	GetInbox(ctx context.Context, id int64) (*domaininbox.Inbox, error)
 	UpdateInbox(ctx context.Context, inbox *domaininbox.Inbox) error
 	DeleteInbox(ctx context.Context, id int64) error
-	ListInboxes(ctx context.Context, userID int64, page int, pageSize int) ([]*domaininbox.Inbox, error)
+	ListInboxes(ctx context.Context, userID string, page int, pageSize int) ([]*domaininbox.Inbox, error)
+	// ListUndelivered returns undelivered entries of userID with an ID greater
+	// than afterID, oldest first.
+	ListUndelivered(ctx context.Context, userID string, afterID int64, limit int) ([]*domaininbox.Inbox, error)
+	// MarkDelivered marks the entries of userID that point at messageIDs as delivered.
+	MarkDelivered(ctx context.Context, userID string, messageIDs ...int64) error
+	// Redeliver marks every entry that points at messageID as undelivered
+	// again and returns the users owning them.
+	Redeliver(ctx context.Context, messageID int64) ([]string, error)
 }
@@ -0,0 +1,19 @@
+package message
+
+import (
+	"context"
+	"time"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+)
+
+type ClientSendRepository interface {
+	// GetClientSend fails with respository.ErrNotFound if sender has no
+	// recorded send tagged clientMsgID.
+	GetClientSend(ctx context.Context, sender, clientMsgID string) (*domainmessage.ClientSend, error)
+	// PutClientSend records send, replacing an older send with the same tag.
+	PutClientSend(ctx context.Context, send *domainmessage.ClientSend) error
+	// TrimClientSends drops the sends of sender created before before, and
+	// all but the newest keep of the others.
+	TrimClientSends(ctx context.Context, sender string, keep int, before time.Time) error
+}
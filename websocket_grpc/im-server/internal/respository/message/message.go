@@ -2,15 +2,53 @@ package message
 
 import (
 	"context"
+	"errors"
 
 	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
 )
 
+// ErrRecalled is returned by UpdateMessage for a message that was recalled.
+// A recall is final, so a write from an earlier read cannot undo it.
+var ErrRecalled = errors.New("message was recalled")
+
+// Direction orders a history page relative to its cursor.
+type Direction int
+
+const (
+	// Backward pages towards older messages, newest first.
+	Backward Direction = iota
+	// Forward pages towards newer messages, oldest first.
+	Forward
+)
+
+// Cursor selects a page of at most Limit messages strictly before (Backward)
+// or after (Forward) the message with ID After. A zero After starts at the
+// newest or the oldest end of the conversation.
+type Cursor struct {
+	After     int64
+	Direction Direction
+	Limit     int
+}
+
 type MessageRepository interface {
+	// CreateMessage stores message and assigns its ID and the next Seq of
+	// its conversation.
 	CreateMessage(ctx context.Context, message *domainmessage.Message) error
 	GetMessage(ctx context.Context, id int64) (*domainmessage.Message, error)
+	// UpdateMessage rewrites the content and the edit and recall markers. It
+	// fails with ErrRecalled once the stored message is recalled.
 	UpdateMessage(ctx context.Context, message *domainmessage.Message) error
 	DeleteMessage(ctx context.Context, id int64) error
-	ListMessages(ctx context.Context, userID int64, page int, pageSize int) ([]*domainmessage.Message, error)
-	ListMessagesByGroupID(ctx context.Context, groupID int64, page int, pageSize int) ([]*domainmessage.Message, error)
+	// ListConversation pages through the 1:1 messages between userA and userB.
+	ListConversation(ctx context.Context, userA, userB string, cursor Cursor) ([]*domainmessage.Message, error)
+	// ListGroupMessages pages through the messages of a group.
+	ListGroupMessages(ctx context.Context, groupUUID string, cursor Cursor) ([]*domainmessage.Message, error)
+	// ListSeqRange returns at most limit messages of conversationID with
+	// fromSeq <= Seq <= toSeq, in Seq order.
+	ListSeqRange(ctx context.Context, conversationID string, fromSeq, toSeq int64, limit int) ([]*domainmessage.Message, error)
+	// CountAfter counts the messages of conversationID with a Seq above
+	// afterSeq that were not sent by excludeFrom.
+	CountAfter(ctx context.Context, conversationID string, afterSeq int64, excludeFrom string) (int64, error)
+	// LastSeq returns the highest Seq of conversationID, zero if it is empty.
+	LastSeq(ctx context.Context, conversationID string) (int64, error)
 }
@@ -0,0 +1,12 @@
+package presence
+
+import "context"
+
+// Directory records which nodes currently hold at least one session of a user.
+type Directory interface {
+	Register(ctx context.Context, userID, nodeID string) error
+	Unregister(ctx context.Context, userID, nodeID string) error
+	Nodes(ctx context.Context, userID string) ([]string, error)
+	// ClearNode drops every entry of nodeID, e.g. left behind by a crash.
+	ClearNode(ctx context.Context, nodeID string) error
+}
@@ -0,0 +1,14 @@
+package receipt
+
+import (
+	"context"
+)
+
+type ReadCursorRepository interface {
+	// AdvanceReadCursor moves the cursor of userID in conversationID to seq.
+	// It never moves a cursor backwards and reports whether it moved.
+	AdvanceReadCursor(ctx context.Context, userID, conversationID string, seq int64) (bool, error)
+	// GetReadCursor returns the read seq of userID in conversationID, zero if
+	// the user never read it.
+	GetReadCursor(ctx context.Context, userID, conversationID string) (int64, error)
+}
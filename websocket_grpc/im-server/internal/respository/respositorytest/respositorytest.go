@@ -0,0 +1,619 @@
+// Package respositorytest is a conformance suite for the repository
+// implementations: each of them runs it against an empty store so they all
+// behave the way the interfaces document.
+package respositorytest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	domaininbox "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/inbox"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	domainuser "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/user"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+)
+
+// Repositories are the implementations under test, sharing one store.
+type Repositories struct {
+	Users    user.UserRepository
+	Groups   group.GroupRepository
+	Messages message.MessageRepository
+	Inboxes  inbox.InboxRepository
+	// Transactor is checked to roll back failed units; leave it nil for
+	// stores that cannot.
+	Transactor respository.Transactor
+}
+
+// Run runs the suite. open is called once per test and must return
+// repositories over an empty store.
+func Run(t *testing.T, open func(t *testing.T) Repositories) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, r Repositories)
+	}{
+		{"Users", testUsers},
+		{"Groups", testGroups},
+		{"GroupMembers", testGroupMembers},
+		{"JoinRequests", testJoinRequests},
+		{"Messages", testMessages},
+		{"MessagePages", testMessagePages},
+		{"Inboxes", testInboxes},
+		{"Transactor", testTransactor},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, open(t))
+		})
+	}
+}
+
+// base is a time every store keeps exactly; PostgreSQL only stores
+// microseconds.
+var base = time.Date(2026, 1, 2, 3, 4, 5, 123456000, time.UTC)
+
+// at returns the time i minutes after base, so rows created in order also
+// sort by time.
+func at(i int) time.Time {
+	return base.Add(time.Duration(i) * time.Minute)
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func expectErr(t *testing.T, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("expected %v, got %v", want, err)
+	}
+}
+
+func testUsers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	u1 := &domainuser.User{UUID: "u1", Email: "a@example.com", Name: "A", PasswordHash: "h1", CreatedAt: at(0), UpdatedAt: at(0)}
+	must(t, r.Users.CreateUser(ctx, u1))
+	if u1.ID == 0 {
+		t.Fatal("CreateUser assigned no ID")
+	}
+	// users without email or phone do not collide
+	u2 := &domainuser.User{UUID: "u2", Phone: "+100", Name: "B", PasswordHash: "h2", CreatedAt: at(1), UpdatedAt: at(1)}
+	must(t, r.Users.CreateUser(ctx, u2))
+	must(t, r.Users.CreateUser(ctx, &domainuser.User{UUID: "u3", Name: "C", CreatedAt: at(2), UpdatedAt: at(2)}))
+
+	for name, dup := range map[string]*domainuser.User{
+		"uuid":  {UUID: "u1", Name: "X", CreatedAt: at(3), UpdatedAt: at(3)},
+		"email": {UUID: "x1", Email: "a@example.com", Name: "X", CreatedAt: at(3), UpdatedAt: at(3)},
+		"phone": {UUID: "x2", Phone: "+100", Name: "X", CreatedAt: at(3), UpdatedAt: at(3)},
+	} {
+		if err := r.Users.CreateUser(ctx, dup); !errors.Is(err, respository.ErrConflict) {
+			t.Fatalf("duplicate %s: expected %v, got %v", name, respository.ErrConflict, err)
+		}
+	}
+
+	lookups := map[string]func() (*domainuser.User, error){
+		"id":    func() (*domainuser.User, error) { return r.Users.GetUser(ctx, u1.ID) },
+		"uuid":  func() (*domainuser.User, error) { return r.Users.GetUserByUUID(ctx, "u1") },
+		"email": func() (*domainuser.User, error) { return r.Users.GetUserByEmail(ctx, "a@example.com") },
+	}
+	for name, get := range lookups {
+		got, err := get()
+		must(t, err)
+		if got.ID != u1.ID || got.UUID != "u1" || got.Email != u1.Email || got.Phone != "" || got.Name != "A" ||
+			got.PasswordHash != "h1" || !got.CreatedAt.Equal(at(0)) || !got.UpdatedAt.Equal(at(0)) {
+			t.Fatalf("by %s: unexpected user %+v", name, got)
+		}
+	}
+	if got, err := r.Users.GetUserByPhone(ctx, "+100"); err != nil || got.ID != u2.ID || got.Email != "" {
+		t.Fatalf("by phone: unexpected user %+v, %v", got, err)
+	}
+	for _, get := range []func() (*domainuser.User, error){
+		func() (*domainuser.User, error) { return r.Users.GetUser(ctx, -1) },
+		func() (*domainuser.User, error) { return r.Users.GetUserByUUID(ctx, "nobody") },
+		func() (*domainuser.User, error) { return r.Users.GetUserByEmail(ctx, "") },
+		func() (*domainuser.User, error) { return r.Users.GetUserByPhone(ctx, "") },
+	} {
+		_, err := get()
+		expectErr(t, err, respository.ErrNotFound)
+	}
+
+	u1.Name, u1.Email, u1.Phone, u1.UpdatedAt = "A2", "", "+200", at(5)
+	must(t, r.Users.UpdateUser(ctx, u1))
+	if got, err := r.Users.GetUserByPhone(ctx, "+200"); err != nil || got.Name != "A2" || got.Email != "" || !got.UpdatedAt.Equal(at(5)) {
+		t.Fatalf("after update: unexpected user %+v, %v", got, err)
+	}
+	_, err := r.Users.GetUserByEmail(ctx, "a@example.com")
+	expectErr(t, err, respository.ErrNotFound)
+	u2.Phone = "+200"
+	expectErr(t, r.Users.UpdateUser(ctx, u2), respository.ErrConflict)
+	expectErr(t, r.Users.UpdateUser(ctx, &domainuser.User{ID: -1, UUID: "ghost"}), respository.ErrNotFound)
+
+	must(t, r.Users.DeleteUser(ctx, u1.ID))
+	_, err = r.Users.GetUser(ctx, u1.ID)
+	expectErr(t, err, respository.ErrNotFound)
+	must(t, r.Users.DeleteUser(ctx, u1.ID))
+}
+
+// createGroups stores groups with the UUIDs, created in order.
+func createGroups(t *testing.T, r Repositories, uuids ...string) []*domaingroup.Group {
+	t.Helper()
+	groups := make([]*domaingroup.Group, 0, len(uuids))
+	for i, uuid := range uuids {
+		g := &domaingroup.Group{UUID: uuid, Name: "group " + uuid, Owner: "owner", CreatedAt: at(i), UpdatedAt: at(i)}
+		must(t, r.Groups.CreateGroup(context.Background(), g))
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+func groupUUIDs(groups []*domaingroup.Group) []string {
+	out := make([]string, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, g.UUID)
+	}
+	return out
+}
+
+func testGroups(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	groups := createGroups(t, r, "g1", "g2", "g3")
+	if groups[0].ID == 0 || groups[0].ID == groups[1].ID {
+		t.Fatalf("unexpected IDs %d and %d", groups[0].ID, groups[1].ID)
+	}
+	expectErr(t, r.Groups.CreateGroup(ctx, &domaingroup.Group{UUID: "g1", CreatedAt: at(9), UpdatedAt: at(9)}), respository.ErrConflict)
+
+	got, err := r.Groups.GetGroupByUUID(ctx, "g2")
+	must(t, err)
+	if got.ID != groups[1].ID || got.Name != "group g2" || got.Owner != "owner" || !got.CreatedAt.Equal(at(1)) {
+		t.Fatalf("unexpected group %+v", got)
+	}
+	_, err = r.Groups.GetGroup(ctx, -1)
+	expectErr(t, err, respository.ErrNotFound)
+	_, err = r.Groups.GetGroupByUUID(ctx, "nope")
+	expectErr(t, err, respository.ErrNotFound)
+
+	// newest first
+	page, err := r.Groups.ListGroups(ctx, 1, 2)
+	must(t, err)
+	if uuids := groupUUIDs(page); !slices.Equal(uuids, []string{"g3", "g2"}) {
+		t.Fatalf("first page: %v", uuids)
+	}
+	page, err = r.Groups.ListGroups(ctx, 2, 2)
+	must(t, err)
+	if uuids := groupUUIDs(page); !slices.Equal(uuids, []string{"g1"}) {
+		t.Fatalf("second page: %v", uuids)
+	}
+
+	groups[1].Name, groups[1].UpdatedAt = "renamed", at(7)
+	must(t, r.Groups.UpdateGroup(ctx, groups[1]))
+	got, err = r.Groups.GetGroup(ctx, groups[1].ID)
+	must(t, err)
+	if got.Name != "renamed" || !got.UpdatedAt.Equal(at(7)) {
+		t.Fatalf("after update: %+v", got)
+	}
+	expectErr(t, r.Groups.UpdateGroup(ctx, &domaingroup.Group{ID: -1, UUID: "ghost"}), respository.ErrNotFound)
+
+	// deleting a group drops its members
+	member := &domaingroup.GroupMember{GroupID: groups[0].ID, UserID: "u1", Role: domaingroup.RoleMember, CreatedAt: at(0), UpdatedAt: at(0)}
+	must(t, r.Groups.AddGroupMember(ctx, member))
+	must(t, r.Groups.DeleteGroup(ctx, groups[0].ID))
+	_, err = r.Groups.GetGroupByUUID(ctx, "g1")
+	expectErr(t, err, respository.ErrNotFound)
+	if ok, err := r.Groups.IsGroupMember(ctx, groups[0].ID, "u1"); err != nil || ok {
+		t.Fatalf("member of a deleted group: %v, %v", ok, err)
+	}
+	userGroups, err := r.Groups.ListUserGroups(ctx, "u1", 1, 10)
+	must(t, err)
+	if len(userGroups) != 0 {
+		t.Fatalf("groups of u1 after delete: %v", groupUUIDs(userGroups))
+	}
+	// the UUID is free again
+	createGroups(t, r, "g1")
+}
+
+func testGroupMembers(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	groups := createGroups(t, r, "g1", "g2")
+	g := groups[0]
+	add := func(groupID int64, userID string, i int) *domaingroup.GroupMember {
+		t.Helper()
+		m := &domaingroup.GroupMember{GroupID: groupID, UserID: userID, Role: domaingroup.RoleMember, CreatedAt: at(i), UpdatedAt: at(i)}
+		must(t, r.Groups.AddGroupMember(ctx, m))
+		return m
+	}
+	owner := &domaingroup.GroupMember{GroupID: g.ID, UserID: "u1", Role: domaingroup.RoleOwner, CreatedAt: at(0), UpdatedAt: at(0)}
+	must(t, r.Groups.AddGroupMember(ctx, owner))
+	add(g.ID, "u2", 1)
+	add(g.ID, "u3", 2)
+	add(groups[1].ID, "u1", 3)
+
+	// adding again keeps the member as it is
+	again := &domaingroup.GroupMember{GroupID: g.ID, UserID: "u1", Role: domaingroup.RoleMember, CreatedAt: at(9), UpdatedAt: at(9)}
+	must(t, r.Groups.AddGroupMember(ctx, again))
+	got, err := r.Groups.GetGroupMember(ctx, g.ID, "u1")
+	must(t, err)
+	if got.Role != domaingroup.RoleOwner || got.Notify != domaingroup.NotifyAll || got.GroupID != g.ID || !got.CreatedAt.Equal(at(0)) {
+		t.Fatalf("unexpected member %+v", got)
+	}
+	expectErr(t, r.Groups.AddGroupMember(ctx, &domaingroup.GroupMember{GroupID: -1, UserID: "u1", Role: domaingroup.RoleMember, CreatedAt: at(0), UpdatedAt: at(0)}), respository.ErrNotFound)
+
+	if ok, err := r.Groups.IsGroupMember(ctx, g.ID, "u2"); err != nil || !ok {
+		t.Fatalf("u2 should be a member: %v", err)
+	}
+	if ok, err := r.Groups.IsGroupMember(ctx, g.ID, "u9"); err != nil || ok {
+		t.Fatalf("u9 should not be a member: %v", err)
+	}
+	_, err = r.Groups.GetGroupMember(ctx, g.ID, "u9")
+	expectErr(t, err, respository.ErrNotFound)
+
+	members, err := r.Groups.ListGroupMembers(ctx, g.ID, 1, 2)
+	must(t, err)
+	if len(members) != 2 || members[0].UserID != "u3" || members[1].UserID != "u2" {
+		t.Fatalf("unexpected first page %+v", members)
+	}
+	ids, err := r.Groups.ListGroupMemberIDs(ctx, g.ID)
+	must(t, err)
+	slices.Sort(ids)
+	if !slices.Equal(ids, []string{"u1", "u2", "u3"}) {
+		t.Fatalf("unexpected member IDs %v", ids)
+	}
+	// the group joined last comes first
+	userGroups, err := r.Groups.ListUserGroups(ctx, "u1", 1, 10)
+	must(t, err)
+	if uuids := groupUUIDs(userGroups); !slices.Equal(uuids, []string{"g2", "g1"}) {
+		t.Fatalf("unexpected groups of u1 %v", uuids)
+	}
+
+	must(t, r.Groups.UpdateGroupMemberRole(ctx, g.ID, "u2", domaingroup.RoleAdmin))
+	must(t, r.Groups.UpdateGroupMemberNotify(ctx, g.ID, "u2", domaingroup.NotifyMentions))
+	must(t, r.Groups.UpdateGroupMemberNotify(ctx, g.ID, "u3", domaingroup.NotifyNone))
+	got, err = r.Groups.GetGroupMember(ctx, g.ID, "u2")
+	must(t, err)
+	if got.Role != domaingroup.RoleAdmin || got.Notify != domaingroup.NotifyMentions {
+		t.Fatalf("after updates: %+v", got)
+	}
+	expectErr(t, r.Groups.UpdateGroupMemberRole(ctx, g.ID, "u9", domaingroup.RoleAdmin), respository.ErrNotFound)
+	expectErr(t, r.Groups.UpdateGroupMemberNotify(ctx, g.ID, "u9", domaingroup.NotifyNone), respository.ErrNotFound)
+	muted, err := r.Groups.ListMutedGroupMembers(ctx, g.ID)
+	must(t, err)
+	if len(muted) != 2 || muted["u2"] != domaingroup.NotifyMentions || muted["u3"] != domaingroup.NotifyNone {
+		t.Fatalf("unexpected muted members %v", muted)
+	}
+
+	must(t, r.Groups.RemoveGroupMember(ctx, g.ID, "u2"))
+	must(t, r.Groups.RemoveGroupMember(ctx, g.ID, "u2"))
+	if ok, err := r.Groups.IsGroupMember(ctx, g.ID, "u2"); err != nil || ok {
+		t.Fatalf("u2 should have left: %v", err)
+	}
+	// the member of the other group is untouched
+	if ok, err := r.Groups.IsGroupMember(ctx, groups[1].ID, "u1"); err != nil || !ok {
+		t.Fatalf("u1 should still be in g2: %v", err)
+	}
+}
+
+func testJoinRequests(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	g := createGroups(t, r, "g1")[0]
+	req := &domaingroup.GroupRequest{RequestID: "r1", GroupID: g.ID, UserID: "u2", InvitedBy: "u1", Status: domaingroup.RequestPending, CreatedAt: at(0), UpdatedAt: at(0)}
+	must(t, r.Groups.CreateJoinRequest(ctx, req))
+	if req.ID == 0 {
+		t.Fatal("CreateJoinRequest assigned no ID")
+	}
+	dup := &domaingroup.GroupRequest{RequestID: "r1", GroupID: g.ID, UserID: "u3", Status: domaingroup.RequestPending, CreatedAt: at(1), UpdatedAt: at(1)}
+	expectErr(t, r.Groups.CreateJoinRequest(ctx, dup), respository.ErrConflict)
+
+	got, err := r.Groups.GetJoinRequest(ctx, "r1")
+	must(t, err)
+	if got.ID != req.ID || got.GroupID != g.ID || got.UserID != "u2" || got.InvitedBy != "u1" || got.Status != domaingroup.RequestPending || !got.CreatedAt.Equal(at(0)) {
+		t.Fatalf("unexpected request %+v", got)
+	}
+	_, err = r.Groups.GetJoinRequest(ctx, "r9")
+	expectErr(t, err, respository.ErrNotFound)
+
+	if ok, err := r.Groups.HasPendingJoinRequest(ctx, g.ID, "u2"); err != nil || !ok {
+		t.Fatalf("u2 should have a pending request: %v", err)
+	}
+	if ok, err := r.Groups.HasPendingJoinRequest(ctx, g.ID, "u3"); err != nil || ok {
+		t.Fatalf("u3 should have no pending request: %v", err)
+	}
+
+	ok, err := r.Groups.ResolveJoinRequest(ctx, "r1", domaingroup.RequestAccepted)
+	if err != nil || !ok {
+		t.Fatalf("first answer: %v, %v", ok, err)
+	}
+	ok, err = r.Groups.ResolveJoinRequest(ctx, "r1", domaingroup.RequestRejected)
+	if err != nil || ok {
+		t.Fatalf("second answer: %v, %v", ok, err)
+	}
+	_, err = r.Groups.ResolveJoinRequest(ctx, "r9", domaingroup.RequestAccepted)
+	expectErr(t, err, respository.ErrNotFound)
+	got, err = r.Groups.GetJoinRequest(ctx, "r1")
+	must(t, err)
+	if got.Status != domaingroup.RequestAccepted {
+		t.Fatalf("unexpected status %v", got.Status)
+	}
+	if ok, err := r.Groups.HasPendingJoinRequest(ctx, g.ID, "u2"); err != nil || ok {
+		t.Fatalf("the answered request is still pending: %v", err)
+	}
+}
+
+func createMessage(t *testing.T, r Repositories, m *domainmessage.Message) *domainmessage.Message {
+	t.Helper()
+	must(t, r.Messages.CreateMessage(context.Background(), m))
+	return m
+}
+
+func testMessages(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	m1 := createMessage(t, r, &domainmessage.Message{FromUser: "u1", ToUser: "u2", Content: []byte("one"), CreatedAt: at(0)})
+	m2 := createMessage(t, r, &domainmessage.Message{FromUser: "u2", ToUser: "u1", Content: []byte("two"), CreatedAt: at(1)})
+	g1 := createMessage(t, r, &domainmessage.Message{FromUser: "u1", GroupUUID: "g1", ContentType: domainmessage.ContentText, Content: []byte("hi"), CreatedAt: at(2)})
+	m3 := createMessage(t, r, &domainmessage.Message{FromUser: "u1", ToUser: "u2", Content: []byte("three"), CreatedAt: at(3)})
+
+	// seqs count per conversation, whoever sends
+	if m1.Seq != 1 || m2.Seq != 2 || m3.Seq != 3 || g1.Seq != 1 {
+		t.Fatalf("unexpected seqs %d %d %d, group %d", m1.Seq, m2.Seq, m3.Seq, g1.Seq)
+	}
+	if m1.ID == 0 || m2.ID <= m1.ID || m3.ID <= g1.ID {
+		t.Fatalf("IDs do not increase: %d %d %d %d", m1.ID, m2.ID, g1.ID, m3.ID)
+	}
+
+	got, err := r.Messages.GetMessage(ctx, g1.ID)
+	must(t, err)
+	if got.FromUser != "u1" || got.ToUser != "" || got.GroupUUID != "g1" || got.Seq != 1 || got.ContentType != domainmessage.ContentText ||
+		!bytes.Equal(got.Content, []byte("hi")) || !got.CreatedAt.Equal(at(2)) || got.EditedAt != nil || got.RecalledAt != nil {
+		t.Fatalf("unexpected message %+v", got)
+	}
+	_, err = r.Messages.GetMessage(ctx, -1)
+	expectErr(t, err, respository.ErrNotFound)
+
+	pair := domainmessage.PairConversation("u1", "u2")
+	if seq, err := r.Messages.LastSeq(ctx, pair); err != nil || seq != 3 {
+		t.Fatalf("last seq: %d, %v", seq, err)
+	}
+	if seq, err := r.Messages.LastSeq(ctx, domainmessage.PairConversation("u1", "u9")); err != nil || seq != 0 {
+		t.Fatalf("last seq of an empty conversation: %d, %v", seq, err)
+	}
+	if n, err := r.Messages.CountAfter(ctx, pair, 1, "u1"); err != nil || n != 1 {
+		t.Fatalf("count after: %d, %v", n, err)
+	}
+	if n, err := r.Messages.CountAfter(ctx, pair, 0, "u9"); err != nil || n != 3 {
+		t.Fatalf("count after: %d, %v", n, err)
+	}
+	got2, err := r.Messages.ListSeqRange(ctx, pair, 2, 5, 10)
+	must(t, err)
+	if len(got2) != 2 || got2[0].ID != m2.ID || got2[1].ID != m3.ID {
+		t.Fatalf("unexpected seq range %+v", got2)
+	}
+	if got2, err = r.Messages.ListSeqRange(ctx, pair, 1, 3, 1); err != nil || len(got2) != 1 || got2[0].Seq != 1 {
+		t.Fatalf("limited seq range: %+v, %v", got2, err)
+	}
+
+	// an update keeps the seq and creation time
+	edited := at(4)
+	got.ContentType, got.Content, got.EditedAt, got.Seq = domainmessage.ContentCustom, []byte("edited"), &edited, 99
+	must(t, r.Messages.UpdateMessage(ctx, got))
+	got, err = r.Messages.GetMessage(ctx, g1.ID)
+	must(t, err)
+	if got.Seq != 1 || got.ContentType != domainmessage.ContentCustom || !bytes.Equal(got.Content, []byte("edited")) ||
+		got.EditedAt == nil || !got.EditedAt.Equal(edited) || !got.CreatedAt.Equal(at(2)) {
+		t.Fatalf("after update: %+v", got)
+	}
+	recalled := at(5)
+	got.ContentType, got.Content, got.RecalledAt = domainmessage.ContentUntyped, nil, &recalled
+	must(t, r.Messages.UpdateMessage(ctx, got))
+	if got, err = r.Messages.GetMessage(ctx, g1.ID); err != nil || !got.IsRecalled() || len(got.Content) != 0 {
+		t.Fatalf("after recall: %+v, %v", got, err)
+	}
+	// a write based on a read from before the recall cannot undo it
+	stale := *got
+	stale.ContentType, stale.Content, stale.RecalledAt = domainmessage.ContentCustom, []byte("stale"), nil
+	expectErr(t, r.Messages.UpdateMessage(ctx, &stale), message.ErrRecalled)
+	if got, err = r.Messages.GetMessage(ctx, g1.ID); err != nil || !got.IsRecalled() || len(got.Content) != 0 {
+		t.Fatalf("after stale update: %+v, %v", got, err)
+	}
+	expectErr(t, r.Messages.UpdateMessage(ctx, &domainmessage.Message{ID: -1}), respository.ErrNotFound)
+
+	// deleting keeps the counter, so seqs are never reused
+	must(t, r.Messages.DeleteMessage(ctx, m3.ID))
+	_, err = r.Messages.GetMessage(ctx, m3.ID)
+	expectErr(t, err, respository.ErrNotFound)
+	m4 := createMessage(t, r, &domainmessage.Message{FromUser: "u2", ToUser: "u1", Content: []byte("four"), CreatedAt: at(6)})
+	if m4.Seq != 4 {
+		t.Fatalf("expected seq 4 after a delete, got %d", m4.Seq)
+	}
+}
+
+func messageIDs(messages []*domainmessage.Message) []int64 {
+	out := make([]int64, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, m.ID)
+	}
+	return out
+}
+
+func testMessagePages(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	var pair, grp []int64
+	for i := range 5 {
+		from, to := "u1", "u2"
+		if i%2 == 1 {
+			from, to = to, from
+		}
+		pair = append(pair, createMessage(t, r, &domainmessage.Message{FromUser: from, ToUser: to, CreatedAt: at(2 * i)}).ID)
+		grp = append(grp, createMessage(t, r, &domainmessage.Message{FromUser: "u1", GroupUUID: "g1", CreatedAt: at(2*i + 1)}).ID)
+	}
+	createMessage(t, r, &domainmessage.Message{FromUser: "u1", ToUser: "u3", CreatedAt: at(20)})
+
+	cases := []struct {
+		name   string
+		peer   string
+		group  string
+		cursor message.Cursor
+		want   []int64
+	}{
+		{"newest", "u2", "", message.Cursor{Limit: 2}, []int64{pair[4], pair[3]}},
+		{"older", "u2", "", message.Cursor{After: pair[3], Limit: 10}, []int64{pair[2], pair[1], pair[0]}},
+		{"oldest", "u2", "", message.Cursor{Direction: message.Forward, Limit: 2}, []int64{pair[0], pair[1]}},
+		{"newer", "u2", "", message.Cursor{After: pair[3], Direction: message.Forward, Limit: 10}, []int64{pair[4]}},
+		{"group", "", "g1", message.Cursor{After: grp[2], Limit: 10}, []int64{grp[1], grp[0]}},
+		{"group forward", "", "g1", message.Cursor{After: grp[2], Direction: message.Forward, Limit: 10}, []int64{grp[3], grp[4]}},
+		{"empty", "", "g2", message.Cursor{Limit: 10}, []int64{}},
+	}
+	for _, tc := range cases {
+		var (
+			got []*domainmessage.Message
+			err error
+		)
+		if tc.group != "" {
+			got, err = r.Messages.ListGroupMessages(ctx, tc.group, tc.cursor)
+		} else {
+			// either side may ask
+			got, err = r.Messages.ListConversation(ctx, tc.peer, "u1", tc.cursor)
+		}
+		must(t, err)
+		if ids := messageIDs(got); !slices.Equal(ids, tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, ids)
+		}
+	}
+}
+
+func testInboxes(t *testing.T, r Repositories) {
+	ctx := context.Background()
+	var msgs []*domainmessage.Message
+	for i := range 3 {
+		msgs = append(msgs, createMessage(t, r, &domainmessage.Message{FromUser: "u1", GroupUUID: "g1", CreatedAt: at(i)}))
+	}
+	entry := func(userID string, m *domainmessage.Message) *domaininbox.Inbox {
+		return &domaininbox.Inbox{UserID: userID, MessageID: m.ID, CreatedAt: m.CreatedAt, UpdatedAt: m.CreatedAt}
+	}
+
+	first := entry("u2", msgs[0])
+	must(t, r.Inboxes.CreateInbox(ctx, first))
+	batch := []*domaininbox.Inbox{entry("u2", msgs[1]), entry("u3", msgs[1]), entry("u2", msgs[2]), entry("u3", msgs[2])}
+	must(t, r.Inboxes.CreateInboxes(ctx, batch))
+	must(t, r.Inboxes.CreateInboxes(ctx, nil))
+	seen := map[int64]bool{first.ID: true}
+	for _, in := range batch {
+		if in.ID == 0 || seen[in.ID] {
+			t.Fatalf("unexpected batch IDs %+v", batch)
+		}
+		seen[in.ID] = true
+	}
+
+	got, err := r.Inboxes.GetInbox(ctx, batch[1].ID)
+	must(t, err)
+	if got.UserID != "u3" || got.MessageID != msgs[1].ID || got.Delivered || !got.CreatedAt.Equal(at(1)) {
+		t.Fatalf("unexpected entry %+v", got)
+	}
+	_, err = r.Inboxes.GetInbox(ctx, -1)
+	expectErr(t, err, respository.ErrNotFound)
+
+	list, err := r.Inboxes.ListInboxes(ctx, "u2", 1, 2)
+	must(t, err)
+	if len(list) != 2 || list[0].ID != batch[2].ID || list[1].ID != batch[0].ID {
+		t.Fatalf("unexpected newest entries of u2 %+v", list)
+	}
+
+	pending, err := r.Inboxes.ListUndelivered(ctx, "u2", 0, 10)
+	must(t, err)
+	if len(pending) != 3 || pending[0].ID != first.ID || pending[2].ID != batch[2].ID {
+		t.Fatalf("unexpected pending entries %+v", pending)
+	}
+	if pending, err = r.Inboxes.ListUndelivered(ctx, "u2", first.ID, 1); err != nil || len(pending) != 1 || pending[0].ID != batch[0].ID {
+		t.Fatalf("pending after %d: %+v, %v", first.ID, pending, err)
+	}
+
+	must(t, r.Inboxes.MarkDelivered(ctx, "u2", msgs[0].ID, msgs[1].ID))
+	must(t, r.Inboxes.MarkDelivered(ctx, "u2"))
+	if pending, err = r.Inboxes.ListUndelivered(ctx, "u2", 0, 10); err != nil || len(pending) != 1 || pending[0].MessageID != msgs[2].ID {
+		t.Fatalf("pending after delivery: %+v, %v", pending, err)
+	}
+	// the other recipient is untouched
+	if pending, err = r.Inboxes.ListUndelivered(ctx, "u3", 0, 10); err != nil || len(pending) != 2 {
+		t.Fatalf("pending of u3: %+v, %v", pending, err)
+	}
+
+	users, err := r.Inboxes.Redeliver(ctx, msgs[1].ID)
+	must(t, err)
+	slices.Sort(users)
+	if !slices.Equal(users, []string{"u2", "u3"}) {
+		t.Fatalf("unexpected redelivered users %v", users)
+	}
+	if pending, err = r.Inboxes.ListUndelivered(ctx, "u2", 0, 10); err != nil || len(pending) != 2 {
+		t.Fatalf("pending after redelivery: %+v, %v", pending, err)
+	}
+	if users, err = r.Inboxes.Redeliver(ctx, -1); err != nil || len(users) != 0 {
+		t.Fatalf("redelivering nothing: %v, %v", users, err)
+	}
+
+	got.Delivered, got.UpdatedAt = true, at(8)
+	must(t, r.Inboxes.UpdateInbox(ctx, got))
+	if got, err = r.Inboxes.GetInbox(ctx, got.ID); err != nil || !got.Delivered || !got.UpdatedAt.Equal(at(8)) {
+		t.Fatalf("after update: %+v, %v", got, err)
+	}
+	expectErr(t, r.Inboxes.UpdateInbox(ctx, &domaininbox.Inbox{ID: -1, UserID: "u2", MessageID: msgs[0].ID}), respository.ErrNotFound)
+	must(t, r.Inboxes.DeleteInbox(ctx, got.ID))
+	_, err = r.Inboxes.GetInbox(ctx, got.ID)
+	expectErr(t, err, respository.ErrNotFound)
+}
+
+func testTransactor(t *testing.T, r Repositories) {
+	if r.Transactor == nil {
+		t.Skip("the store cannot roll back")
+	}
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	// a failing unit leaves nothing behind, including its nested units
+	var msg *domainmessage.Message
+	err := r.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		msg = &domainmessage.Message{FromUser: "u1", ToUser: "u2", CreatedAt: at(0)}
+		if err := r.Messages.CreateMessage(ctx, msg); err != nil {
+			return err
+		}
+		err := r.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			return r.Inboxes.CreateInboxes(ctx, []*domaininbox.Inbox{{UserID: "u2", MessageID: msg.ID, CreatedAt: at(0), UpdatedAt: at(0)}})
+		})
+		if err != nil {
+			return err
+		}
+		return boom
+	})
+	expectErr(t, err, boom)
+	_, err = r.Messages.GetMessage(ctx, msg.ID)
+	expectErr(t, err, respository.ErrNotFound)
+	if pending, err := r.Inboxes.ListUndelivered(ctx, "u2", 0, 10); err != nil || len(pending) != 0 {
+		t.Fatalf("entries survived the rollback: %+v, %v", pending, err)
+	}
+	pair := domainmessage.PairConversation("u1", "u2")
+	if seq, err := r.Messages.LastSeq(ctx, pair); err != nil || seq != 0 {
+		t.Fatalf("the seq survived the rollback: %d, %v", seq, err)
+	}
+
+	err = r.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+		msg = &domainmessage.Message{FromUser: "u1", ToUser: "u2", CreatedAt: at(1)}
+		if err := r.Messages.CreateMessage(ctx, msg); err != nil {
+			return err
+		}
+		return r.Inboxes.CreateInbox(ctx, &domaininbox.Inbox{UserID: "u2", MessageID: msg.ID, CreatedAt: at(1), UpdatedAt: at(1)})
+	})
+	must(t, err)
+	if msg.Seq != 1 {
+		t.Fatalf("expected seq 1, got %d", msg.Seq)
+	}
+	if pending, err := r.Inboxes.ListUndelivered(ctx, "u2", 0, 10); err != nil || len(pending) != 1 || pending[0].MessageID != msg.ID {
+		t.Fatalf("committed entries: %+v, %v", pending, err)
+	}
+}
@@ -0,0 +1,20 @@
+package token
+
+import (
+	"context"
+
+	domaintoken "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/token"
+)
+
+type TokenRepository interface {
+	// CreateToken assigns token.ID. A token created with RevokedAt set only
+	// records a revocation.
+	CreateToken(ctx context.Context, token *domaintoken.Token) error
+	// GetToken fails with respository.ErrNotFound for unknown hashes.
+	GetToken(ctx context.Context, hash string) (*domaintoken.Token, error)
+	// RevokeToken marks the token revoked; revoking twice is a no-op.
+	RevokeToken(ctx context.Context, hash string) error
+	// RevokeUserTokens revokes every live token of userID, or only those of
+	// deviceID if it is set, and returns how many were revoked.
+	RevokeUserTokens(ctx context.Context, userID, deviceID string) (int64, error)
+}
@@ -0,0 +1,12 @@
+package respository
+
+import "context"
+
+// Transactor runs several repository calls as one unit. The repositories
+// join the transaction through the context fn receives, so fn must pass it
+// on to every call that belongs to the unit.
+type Transactor interface {
+	// WithinTx commits when fn returns nil and rolls back otherwise. Called
+	// again within fn, it joins the running transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -7,8 +7,13 @@ import (
 )
 
 type UserRepository interface {
+	// CreateUser assigns user.ID and fails with respository.ErrConflict if
+	// the UUID, email or phone is already taken.
 	CreateUser(ctx context.Context, user *domainuser.User) error
 	GetUser(ctx context.Context, id int64) (*domainuser.User, error)
+	GetUserByUUID(ctx context.Context, uuid string) (*domainuser.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*domainuser.User, error)
+	GetUserByPhone(ctx context.Context, phone string) (*domainuser.User, error)
 	UpdateUser(ctx context.Context, user *domainuser.User) error
 	DeleteUser(ctx context.Context, id int64) error
 }
@@ -1,12 +1,36 @@
 package auth
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Principal struct {
 	UserID   string `json:"user_id"`
 	DeviceID string `json:"device_id"`
+	// Platform is the one the credential was issued for; see
+	// domaintoken.ValidPlatform.
+	Platform string `json:"platform"`
+	// TokenID identifies the credential for revocation. It is empty when the
+	// authenticator has nothing to revoke.
+	TokenID string `json:"token_id"`
+	// ExpiresAt ends the session when the token lapses; zero means never.
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type Authenticator interface {
 	Authenticate(ctx context.Context, token string) (*Principal, error)
 }
+
+// ChainAuthenticator tries each authenticator in order and returns the
+// first principal accepted.
+type ChainAuthenticator []Authenticator
+
+func (c ChainAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	for _, a := range c {
+		if p, err := a.Authenticate(ctx, token); err == nil {
+			return p, nil
+		}
+	}
+	return nil, ErrUnauthorized
+}
@@ -19,13 +19,18 @@ func (a *DummyAuthenticator) Authenticate(ctx context.Context, token string) (*P
 		return nil, ErrUnauthorized
 	}
 
+	// test:<user>:<device>[:<platform>]
 	parts := strings.Split(token, ":")
-	if len(parts) != 3 || parts[0] != "test" {
+	if len(parts) < 3 || len(parts) > 4 || parts[0] != "test" {
 		return nil, ErrUnauthorized
 	}
 
-	return &Principal{
+	p := &Principal{
 		UserID:   parts[1],
 		DeviceID: parts[2],
-	}, nil
+	}
+	if len(parts) == 4 {
+		p.Platform = parts[3]
+	}
+	return p, nil
 }
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Supported JWT signature algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgEdDSA = "EdDSA"
+)
+
+var (
+	ErrMalformedToken = errors.New("malformed token")
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrBadSignature   = errors.New("invalid token signature")
+	ErrTokenExpired   = errors.New("token expired")
+	ErrInvalidClaims  = errors.New("invalid token claims")
+)
+
+// Key is a JWT signing or verification key identified by the "kid" header.
+// Ed25519 keys without a private half can only verify, which is how retired
+// keys are kept around during rotation.
+type Key struct {
+	ID        string
+	Algorithm string
+	// Secret is the HS256 shared secret.
+	Secret []byte
+	// PublicKey and PrivateKey are the EdDSA key pair.
+	PublicKey  ed25519.PublicKey
+	PrivateKey ed25519.PrivateKey
+}
+
+func NewHMACKey(id string, secret []byte) Key {
+	return Key{ID: id, Algorithm: AlgHS256, Secret: secret}
+}
+
+// NewEd25519Key builds an EdDSA key; priv may be nil for verify-only keys.
+func NewEd25519Key(id string, pub ed25519.PublicKey, priv ed25519.PrivateKey) Key {
+	if pub == nil && priv != nil {
+		pub = priv.Public().(ed25519.PublicKey)
+	}
+	return Key{ID: id, Algorithm: AlgEdDSA, PublicKey: pub, PrivateKey: priv}
+}
+
+func (k Key) sign(data []byte) ([]byte, error) {
+	switch k.Algorithm {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.Secret)
+		mac.Write(data)
+		return mac.Sum(nil), nil
+	case AlgEdDSA:
+		if k.PrivateKey == nil {
+			return nil, fmt.Errorf("key %q cannot sign", k.ID)
+		}
+		return ed25519.Sign(k.PrivateKey, data), nil
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+}
+
+func (k Key) verify(data, sig []byte) bool {
+	switch k.Algorithm {
+	case AlgHS256:
+		want, _ := k.sign(data)
+		return hmac.Equal(want, sig)
+	case AlgEdDSA:
+		return len(k.PublicKey) == ed25519.PublicKeySize && ed25519.Verify(k.PublicKey, data, sig)
+	default:
+		return false
+	}
+}
+
+// Claims are the registered JWT claims plus "did" and "plt", the device and
+// platform the token is bound to.
+type Claims struct {
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud,omitempty"`
+	DeviceID  string   `json:"did"`
+	Platform  string   `json:"plt,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+}
+
+// audience accepts both the string and the array form of "aud".
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		return json.Unmarshal(b, (*[]string)(a))
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*a = audience{s}
+	return nil
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid"`
+}
+
+type JWTOptions struct {
+	// Issuer and Audience are required to match when set.
+	Issuer   string
+	Audience string
+	Keys     []Key
+	// SigningKeyID selects the key used by Sign.
+	SigningKeyID string
+	// Leeway tolerates clock skew when checking exp and nbf.
+	Leeway time.Duration
+}
+
+// JWTAuthenticator verifies compact JWS tokens signed with HS256 or EdDSA.
+// It is stateless; revocation is layered on top by TokenService.
+type JWTAuthenticator struct {
+	options JWTOptions
+	keys    map[string]Key
+	now     func() time.Time
+}
+
+func NewJWTAuthenticator(options JWTOptions) (*JWTAuthenticator, error) {
+	keys := make(map[string]Key, len(options.Keys))
+	for _, k := range options.Keys {
+		if k.ID == "" {
+			return nil, fmt.Errorf("jwt key without id")
+		}
+		if k.Algorithm != AlgHS256 && k.Algorithm != AlgEdDSA {
+			return nil, fmt.Errorf("jwt key %q: unsupported algorithm %q", k.ID, k.Algorithm)
+		}
+		if _, dup := keys[k.ID]; dup {
+			return nil, fmt.Errorf("duplicate jwt key %q", k.ID)
+		}
+		keys[k.ID] = k
+	}
+	if options.SigningKeyID != "" {
+		if _, ok := keys[options.SigningKeyID]; !ok {
+			return nil, fmt.Errorf("signing key %q is not configured", options.SigningKeyID)
+		}
+	}
+	return &JWTAuthenticator{options: options, keys: keys, now: time.Now}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims, err := a.Verify(token)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	return &Principal{
+		UserID:    claims.Subject,
+		DeviceID:  claims.DeviceID,
+		Platform:  claims.Platform,
+		TokenID:   claims.ID,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+// Verify checks the signature and the time, issuer and audience claims.
+func (a *JWTAuthenticator) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrMalformedToken
+	}
+	key, ok := a.keys[h.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	// The key decides the algorithm, never the token.
+	if h.Algorithm != key.Algorithm {
+		return nil, ErrBadSignature
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if !key.verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrBadSignature
+	}
+
+	var c Claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, ErrMalformedToken
+	}
+	now := a.now()
+	if c.ExpiresAt == 0 || !now.Before(time.Unix(c.ExpiresAt, 0).Add(a.options.Leeway)) {
+		return nil, ErrTokenExpired
+	}
+	if c.NotBefore != 0 && now.Add(a.options.Leeway).Before(time.Unix(c.NotBefore, 0)) {
+		return nil, ErrInvalidClaims
+	}
+	if a.options.Issuer != "" && c.Issuer != a.options.Issuer {
+		return nil, ErrInvalidClaims
+	}
+	if a.options.Audience != "" && !slices.Contains(c.Audience, a.options.Audience) {
+		return nil, ErrInvalidClaims
+	}
+	if c.Subject == "" || c.DeviceID == "" {
+		return nil, ErrInvalidClaims
+	}
+	return &c, nil
+}
+
+// Sign issues a token for claims with the signing key. Issuer and audience
+// default to the configured ones.
+func (a *JWTAuthenticator) Sign(c Claims) (string, error) {
+	key, ok := a.keys[a.options.SigningKeyID]
+	if !ok {
+		return "", fmt.Errorf("no signing key configured")
+	}
+	if c.Issuer == "" {
+		c.Issuer = a.options.Issuer
+	}
+	if len(c.Audience) == 0 && a.options.Audience != "" {
+		c.Audience = audience{a.options.Audience}
+	}
+
+	h, err := encodeSegment(header{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+	p, err := encodeSegment(c)
+	if err != nil {
+		return "", err
+	}
+	sig, err := key.sign([]byte(h + "." + p))
+	if err != nil {
+		return "", err
+	}
+	return h + "." + p + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func encodeSegment(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeSegment(s string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+)
+
+func newTestJWT(t *testing.T, signingKeyID string, keys ...Key) *JWTAuthenticator {
+	t.Helper()
+	a, err := NewJWTAuthenticator(JWTOptions{
+		Issuer:       "im",
+		Audience:     "im-clients",
+		Keys:         keys,
+		SigningKeyID: signingKeyID,
+	})
+	if err != nil {
+		t.Fatalf("failed to build authenticator: %v", err)
+	}
+	return a
+}
+
+func validClaims() Claims {
+	return Claims{Subject: "u1", DeviceID: "d1", ExpiresAt: time.Now().Add(time.Hour).Unix(), ID: "j1"}
+}
+
+func TestJWT_RoundTrip(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	for _, key := range []Key{
+		NewHMACKey("h1", []byte("0123456789abcdef0123456789abcdef")),
+		NewEd25519Key("e1", pub, priv),
+	} {
+		a := newTestJWT(t, key.ID, key)
+		token, err := a.Sign(validClaims())
+		if err != nil {
+			t.Fatalf("%s: sign: %v", key.Algorithm, err)
+		}
+		p, err := a.Authenticate(context.Background(), token)
+		if err != nil {
+			t.Fatalf("%s: authenticate: %v", key.Algorithm, err)
+		}
+		if p.UserID != "u1" || p.DeviceID != "d1" || p.TokenID != "j1" {
+			t.Fatalf("%s: unexpected principal: %+v", key.Algorithm, p)
+		}
+	}
+}
+
+func TestJWT_Rejects(t *testing.T) {
+	key := NewHMACKey("h1", []byte("0123456789abcdef0123456789abcdef"))
+	a := newTestJWT(t, "h1", key)
+	sign := func(mutate func(*Claims)) string {
+		c := validClaims()
+		mutate(&c)
+		token, err := a.Sign(c)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return token
+	}
+	good := sign(func(*Claims) {})
+	// Change the first signature character; the last ones carry padding
+	// bits a lenient decoder may ignore.
+	sig := strings.LastIndex(good, ".") + 1
+	flip := byte('A')
+	if good[sig] == flip {
+		flip = 'B'
+	}
+	tampered := good[:sig] + string(flip) + good[sig+1:]
+
+	cases := map[string]struct {
+		token string
+		want  error
+	}{
+		"expired":      {sign(func(c *Claims) { c.ExpiresAt = time.Now().Add(-time.Minute).Unix() }), ErrTokenExpired},
+		"no expiry":    {sign(func(c *Claims) { c.ExpiresAt = 0 }), ErrTokenExpired},
+		"not yet":      {sign(func(c *Claims) { c.NotBefore = time.Now().Add(time.Hour).Unix() }), ErrInvalidClaims},
+		"issuer":       {sign(func(c *Claims) { c.Issuer = "other" }), ErrInvalidClaims},
+		"audience":     {sign(func(c *Claims) { c.Audience = audience{"other"} }), ErrInvalidClaims},
+		"no device":    {sign(func(c *Claims) { c.DeviceID = "" }), ErrInvalidClaims},
+		"tampered":     {tampered, ErrBadSignature},
+		"malformed":    {"a.b", ErrMalformedToken},
+		"unknown key":  {newTestJWT(t, "h2", NewHMACKey("h2", []byte("fedcba9876543210fedcba9876543210"))).mustSign(t, validClaims()), ErrUnknownKey},
+		"wrong secret": {newTestJWT(t, "h1", NewHMACKey("h1", []byte("fedcba9876543210fedcba9876543210"))).mustSign(t, validClaims()), ErrBadSignature},
+	}
+	for name, tc := range cases {
+		if _, err := a.Verify(tc.token); err != tc.want {
+			t.Errorf("%s: got %v, want %v", name, err, tc.want)
+		}
+	}
+}
+
+// A token must not pick its own algorithm: an HS256 token whose secret is
+// the public key of an EdDSA key with the same id is rejected.
+func TestJWT_AlgorithmConfusion(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	a := newTestJWT(t, "", NewEd25519Key("k1", pub, nil))
+	forged := newTestJWT(t, "k1", NewHMACKey("k1", pub)).mustSign(t, validClaims())
+	if _, err := a.Verify(forged); err != ErrBadSignature {
+		t.Fatalf("got %v, want %v", err, ErrBadSignature)
+	}
+}
+
+// Tokens signed with a retired key keep working while the key is configured
+// for verification only.
+func TestJWT_KeyRotation(t *testing.T) {
+	oldPub, oldPriv, _ := ed25519.GenerateKey(rand.Reader)
+	newPub, newPriv, _ := ed25519.GenerateKey(rand.Reader)
+	oldToken := newTestJWT(t, "old", NewEd25519Key("old", oldPub, oldPriv)).mustSign(t, validClaims())
+
+	a := newTestJWT(t, "new", NewEd25519Key("old", oldPub, nil), NewEd25519Key("new", newPub, newPriv))
+	if _, err := a.Verify(oldToken); err != nil {
+		t.Fatalf("old token should verify: %v", err)
+	}
+	newToken := a.mustSign(t, validClaims())
+	if !strings.Contains(newToken, ".") || newToken == oldToken {
+		t.Fatalf("unexpected token: %s", newToken)
+	}
+	if _, err := a.Verify(newToken); err != nil {
+		t.Fatalf("new token should verify: %v", err)
+	}
+}
+
+func TestSignedTokenService_Revoke(t *testing.T) {
+	ctx := context.Background()
+	signer := newTestJWT(t, "h1", NewHMACKey("h1", []byte("0123456789abcdef0123456789abcdef")))
+	tokens := NewSignedTokenService(memory.NewTokenRepository(), time.Hour, signer)
+
+	raw, _, err := tokens.Issue(ctx, "u1", "d1", "")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	p, err := tokens.Authenticate(ctx, raw)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if err := tokens.Revoke(ctx, p); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := tokens.Authenticate(ctx, raw); err != ErrUnauthorized {
+		t.Fatalf("revoked token: got %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+// Signed tokens stand on their signature: tokens the repository never saw,
+// such as ones issued by another node or before a restart, are accepted
+// until their jti is revoked.
+func TestSignedTokenService_Stateless(t *testing.T) {
+	ctx := context.Background()
+	signer := newTestJWT(t, "h1", NewHMACKey("h1", []byte("0123456789abcdef0123456789abcdef")))
+	elsewhere := NewSignedTokenService(memory.NewTokenRepository(), time.Hour, signer)
+	tokens := NewSignedTokenService(memory.NewTokenRepository(), time.Hour, signer)
+
+	raw, _, err := elsewhere.Issue(ctx, "u1", "d1", "web")
+	if err != nil {
+		t.Fatalf("issue: %v", err)
+	}
+	p, err := tokens.Authenticate(ctx, raw)
+	if err != nil {
+		t.Fatalf("token issued elsewhere: %v", err)
+	}
+	if p.UserID != "u1" || p.DeviceID != "d1" || p.Platform != "web" || p.TokenID == "" {
+		t.Fatalf("unexpected principal: %+v", p)
+	}
+
+	c := validClaims()
+	c.ID = ""
+	p2, err := tokens.Authenticate(ctx, signer.mustSign(t, c))
+	if err != nil || p2.TokenID != "" {
+		t.Fatalf("token without jti: %+v, %v", p2, err)
+	}
+
+	// refreshing revokes the old token even though it was not issued here
+	fresh, _, err := tokens.Refresh(ctx, p)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if _, err := tokens.Authenticate(ctx, raw); err != ErrUnauthorized {
+		t.Fatalf("refreshed token: got %v, want %v", err, ErrUnauthorized)
+	}
+	if _, _, err := tokens.Refresh(ctx, p); err != ErrUnauthorized {
+		t.Fatalf("second refresh: got %v, want %v", err, ErrUnauthorized)
+	}
+	p, err = tokens.Authenticate(ctx, fresh)
+	if err != nil {
+		t.Fatalf("new token: %v", err)
+	}
+	if err := tokens.Revoke(ctx, p); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := tokens.Authenticate(ctx, fresh); err != ErrUnauthorized {
+		t.Fatalf("revoked token: got %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+func (a *JWTAuthenticator) mustSign(t *testing.T, c Claims) string {
+	t.Helper()
+	token, err := a.Sign(c)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return token
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	domaintoken "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/token"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/token"
+)
+
+// TokenService issues access tokens and authenticates them, so a revoked
+// token stops working immediately. Tokens are opaque random strings looked
+// up in the token repository unless a signer is configured. Then they are
+// JWTs that stand on their own signature, and the repository is only a
+// deny-list of revoked "jti" claims.
+type TokenService struct {
+	tokenRepository token.TokenRepository
+	ttl             time.Duration
+	signer          *JWTAuthenticator
+}
+
+func NewTokenService(tokenRepository token.TokenRepository, ttl time.Duration) *TokenService {
+	return &TokenService{tokenRepository: tokenRepository, ttl: ttl}
+}
+
+// NewSignedTokenService issues and verifies JWTs signed by signer.
+func NewSignedTokenService(tokenRepository token.TokenRepository, ttl time.Duration, signer *JWTAuthenticator) *TokenService {
+	return &TokenService{tokenRepository: tokenRepository, ttl: ttl, signer: signer}
+}
+
+// Issue creates a token for the user's device and returns its plain value,
+// which is not stored anywhere.
+func (s *TokenService) Issue(ctx context.Context, userID, deviceID, platform string) (string, *domaintoken.Token, error) {
+	secret, err := randomString()
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	t := &domaintoken.Token{
+		Hash:      hashToken(secret),
+		UserID:    userID,
+		DeviceID:  deviceID,
+		Platform:  platform,
+		ExpiresAt: now.Add(s.ttl),
+		CreatedAt: now,
+	}
+	raw := secret
+	if s.signer != nil {
+		raw, err = s.signer.Sign(Claims{
+			Subject:   userID,
+			DeviceID:  deviceID,
+			Platform:  platform,
+			ExpiresAt: t.ExpiresAt.Unix(),
+			IssuedAt:  now.Unix(),
+			ID:        secret,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if err := s.tokenRepository.CreateToken(ctx, t); err != nil {
+		return "", nil, err
+	}
+	return raw, t, nil
+}
+
+func (s *TokenService) Authenticate(ctx context.Context, raw string) (*Principal, error) {
+	if raw == "" {
+		return nil, ErrUnauthorized
+	}
+	if s.signer != nil {
+		return s.authenticateSigned(ctx, raw)
+	}
+	t, err := s.tokenRepository.GetToken(ctx, hashToken(raw))
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	if !t.Valid(time.Now()) {
+		return nil, ErrUnauthorized
+	}
+	return &Principal{UserID: t.UserID, DeviceID: t.DeviceID, Platform: t.Platform, TokenID: t.Hash, ExpiresAt: t.ExpiresAt}, nil
+}
+
+// authenticateSigned accepts any token the signer verifies, including ones
+// issued by another node, before a restart or outside this process, unless
+// its jti was revoked. Principal.TokenID is the hash of the jti, and empty
+// for tokens without one.
+func (s *TokenService) authenticateSigned(ctx context.Context, raw string) (*Principal, error) {
+	p, err := s.signer.Authenticate(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	if p.TokenID == "" {
+		return p, nil
+	}
+	p.TokenID = hashToken(p.TokenID)
+	revoked, err := s.revoked(ctx, p.TokenID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrUnauthorized
+	}
+	return p, nil
+}
+
+// revoked reports whether the token hash is on the deny-list.
+func (s *TokenService) revoked(ctx context.Context, hash string) (bool, error) {
+	t, err := s.tokenRepository.GetToken(ctx, hash)
+	if errors.Is(err, respository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return t.RevokedAt != nil, nil
+}
+
+// Revoke invalidates the token p authenticated with. A signed token the
+// repository has no record of is recorded as revoked.
+func (s *TokenService) Revoke(ctx context.Context, p *Principal) error {
+	if s.signer != nil {
+		now := time.Now()
+		err := s.tokenRepository.CreateToken(ctx, &domaintoken.Token{
+			Hash:      p.TokenID,
+			UserID:    p.UserID,
+			DeviceID:  p.DeviceID,
+			Platform:  p.Platform,
+			ExpiresAt: p.ExpiresAt,
+			RevokedAt: &now,
+			CreatedAt: now,
+		})
+		// A conflict means the token was issued here and is revoked below.
+		if !errors.Is(err, respository.ErrConflict) {
+			return err
+		}
+	}
+	return s.tokenRepository.RevokeToken(ctx, p.TokenID)
+}
+
+// RevokeAll invalidates the tokens of userID, or of one of its devices. It
+// only reaches the tokens in the repository: signed tokens issued elsewhere
+// stay valid until they expire or are revoked one by one.
+func (s *TokenService) RevokeAll(ctx context.Context, userID, deviceID string) (int64, error) {
+	return s.tokenRepository.RevokeUserTokens(ctx, userID, deviceID)
+}
+
+// Refresh replaces the still valid token p authenticated with by a new one
+// for the same user, device and platform, and revokes the old one.
+func (s *TokenService) Refresh(ctx context.Context, p *Principal) (string, *domaintoken.Token, error) {
+	if s.signer != nil {
+		revoked, err := s.revoked(ctx, p.TokenID)
+		if err != nil {
+			return "", nil, err
+		}
+		if revoked || !time.Now().Before(p.ExpiresAt) {
+			return "", nil, ErrUnauthorized
+		}
+	} else if old, err := s.tokenRepository.GetToken(ctx, p.TokenID); err != nil || !old.Valid(time.Now()) {
+		return "", nil, ErrUnauthorized
+	}
+	raw, t, err := s.Issue(ctx, p.UserID, p.DeviceID, p.Platform)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := s.Revoke(ctx, p); err != nil {
+		return "", nil, err
+	}
+	return raw, t, nil
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,234 @@
+// Package blob stores chat attachments and hands out short-lived signed URLs
+// to upload and download them over HTTP.
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	domainblob "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+)
+
+var (
+	ErrBlobNotFound     = errors.New("blob not found")
+	ErrEmptyBlob        = errors.New("blob is empty")
+	ErrTooLarge         = errors.New("blob is too large")
+	ErrQuotaExceeded    = errors.New("storage quota exceeded")
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// PathPrefix is where the HTTP handler of the signed URLs is mounted.
+const PathPrefix = "/blobs/"
+
+const (
+	uploadPath    = PathPrefix + "upload"
+	defaultURLTTL = 10 * time.Minute
+	// sniffLen is how much content http.DetectContentType looks at.
+	sniffLen = 512
+)
+
+type Options struct {
+	// SigningKey authenticates the URLs. A random key is used when empty,
+	// so URLs do not survive a restart and only work on the issuing node.
+	SigningKey []byte
+	// BaseURL is the public origin the URLs start with, such as
+	// "https://im.example.com". URLs are relative when it is empty.
+	BaseURL string
+	// URLTTL is how long a URL stays valid; 10 minutes by default.
+	URLTTL time.Duration
+	// MaxUploadBytes bounds a single upload; 0 means unlimited.
+	MaxUploadBytes int64
+	// UserQuotaBytes bounds the total size of the blobs a user uploaded;
+	// 0 means unlimited.
+	UserQuotaBytes int64
+}
+
+type BlobService struct {
+	store   blob.BlobStore
+	options Options
+	now     func() time.Time
+}
+
+func NewBlobService(store blob.BlobStore, opts Options) (*BlobService, error) {
+	if len(opts.SigningKey) == 0 {
+		opts.SigningKey = make([]byte, 32)
+		if _, err := rand.Read(opts.SigningKey); err != nil {
+			return nil, err
+		}
+	}
+	if opts.URLTTL <= 0 {
+		opts.URLTTL = defaultURLTTL
+	}
+	return &BlobService{store: store, options: opts, now: time.Now}, nil
+}
+
+// UploadURL returns a URL that lets p upload one blob with an HTTP PUT.
+func (s *BlobService) UploadURL(p auth.Principal) (string, time.Time) {
+	return s.signedURL(uploadPath, "upload", p)
+}
+
+// DownloadURL returns a URL that lets p fetch blob id with an HTTP GET.
+func (s *BlobService) DownloadURL(ctx context.Context, p auth.Principal, id string) (string, time.Time, error) {
+	if _, err := s.Stat(ctx, id); err != nil {
+		return "", time.Time{}, err
+	}
+	u, expiresAt := s.signedURL(PathPrefix+id, "download:"+id, p)
+	return u, expiresAt, nil
+}
+
+func (s *BlobService) signedURL(path, action string, p auth.Principal) (string, time.Time) {
+	expiresAt := s.now().Add(s.options.URLTTL).Truncate(time.Second)
+	q := url.Values{}
+	q.Set("user", p.UserID)
+	q.Set("device", p.DeviceID)
+	q.Set("expires", strconv.FormatInt(expiresAt.Unix(), 10))
+	q.Set("sig", s.sign(action, q))
+	return s.options.BaseURL + path + "?" + q.Encode(), expiresAt
+}
+
+// VerifyUpload checks the query of an upload URL and returns the principal
+// it was issued to.
+func (s *BlobService) VerifyUpload(q url.Values) (auth.Principal, error) {
+	return s.verify("upload", q)
+}
+
+// VerifyDownload checks the query of a download URL of blob id.
+func (s *BlobService) VerifyDownload(id string, q url.Values) (auth.Principal, error) {
+	return s.verify("download:"+id, q)
+}
+
+func (s *BlobService) verify(action string, q url.Values) (auth.Principal, error) {
+	expires, err := strconv.ParseInt(q.Get("expires"), 10, 64)
+	if err != nil || s.now().After(time.Unix(expires, 0)) {
+		return auth.Principal{}, ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(q.Get("sig")), []byte(s.sign(action, q))) {
+		return auth.Principal{}, ErrInvalidSignature
+	}
+	return auth.Principal{UserID: q.Get("user"), DeviceID: q.Get("device")}, nil
+}
+
+// sign MACs action together with the principal and expiry of q.
+func (s *BlobService) sign(action string, q url.Values) string {
+	mac := hmac.New(sha256.New, s.options.SigningKey)
+	for _, part := range []string{action, q.Get("user"), q.Get("device"), q.Get("expires")} {
+		mac.Write([]byte(part))
+		mac.Write([]byte{0})
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Upload stores the content of r for userID within the upload size limit
+// and the user's quota. The content type is sniffed from the content;
+// declaredType is only used when sniffing finds nothing specific.
+func (s *BlobService) Upload(ctx context.Context, userID, declaredType string, r io.Reader) (*domainblob.Blob, error) {
+	limit, limitErr := s.options.MaxUploadBytes, ErrTooLarge
+	if quota := s.options.UserQuotaBytes; quota > 0 {
+		used, err := s.store.Usage(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= quota {
+			return nil, ErrQuotaExceeded
+		}
+		if left := quota - used; limit <= 0 || left < limit {
+			limit, limitErr = left, ErrQuotaExceeded
+		}
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrEmptyBlob
+	}
+	head = head[:n]
+
+	body := io.MultiReader(bytes.NewReader(head), r)
+	if limit > 0 {
+		body = &maxReader{r: body, left: limit, err: limitErr}
+	}
+	return s.store.Put(ctx, userID, sniff(head, declaredType), body)
+}
+
+func (s *BlobService) Stat(ctx context.Context, id string) (*domainblob.Blob, error) {
+	b, err := s.store.Stat(ctx, id)
+	if errors.Is(err, respository.ErrNotFound) {
+		return nil, ErrBlobNotFound
+	}
+	return b, err
+}
+
+// StatOwned returns blob id if owner uploaded it. Blobs of other users are
+// reported as ErrBlobNotFound.
+func (s *BlobService) StatOwned(ctx context.Context, owner, id string) (*domainblob.Blob, error) {
+	owns, err := s.store.Owns(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if !owns {
+		return nil, ErrBlobNotFound
+	}
+	return s.Stat(ctx, id)
+}
+
+func (s *BlobService) Open(ctx context.Context, id string) (io.ReadSeekCloser, *domainblob.Blob, error) {
+	rc, b, err := s.store.Open(ctx, id)
+	if errors.Is(err, respository.ErrNotFound) {
+		return nil, nil, ErrBlobNotFound
+	}
+	return rc, b, err
+}
+
+// sniff detects the content type of head, falling back to a well-formed
+// declaredType when detection only finds generic binary data.
+func sniff(head []byte, declaredType string) string {
+	detected := http.DetectContentType(head)
+	if detected != "application/octet-stream" || declaredType == "" {
+		return detected
+	}
+	mediaType, params, err := mime.ParseMediaType(declaredType)
+	if err != nil {
+		return detected
+	}
+	return mime.FormatMediaType(mediaType, params)
+}
+
+// maxReader fails with err once more than left bytes are read.
+type maxReader struct {
+	r    io.Reader
+	left int64
+	err  error
+}
+
+func (m *maxReader) Read(p []byte) (int, error) {
+	if m.left < 0 {
+		return 0, m.err
+	}
+	// Read one byte past the limit to tell an exact fit from an overflow.
+	if int64(len(p)) > m.left+1 {
+		p = p[:m.left+1]
+	}
+	n, err := m.r.Read(p)
+	m.left -= int64(n)
+	if m.left < 0 {
+		return n, m.err
+	}
+	return n, err
+}
@@ -0,0 +1,43 @@
+// Package cluster routes deliveries to users connected to other im-server nodes.
+package cluster
+
+import "context"
+
+// Delivery is a payload forwarded to the node that holds the user's sessions.
+type Delivery struct {
+	UserID string `json:"user_id"`
+	// MessageID is non-zero for message deliveries the client has to acknowledge.
+	MessageID int64  `json:"message_id"`
+	Payload   []byte `json:"payload"`
+	// Disconnect, when set, closes the user's sessions instead of sending Payload.
+	Disconnect *Disconnect `json:"disconnect,omitempty"`
+	// Displace, when set, kicks the sessions the device policy does not allow
+	// next to a session bound on the sending node.
+	Displace *Displace `json:"displace,omitempty"`
+}
+
+// Disconnect closes sessions with a websocket close code.
+type Disconnect struct {
+	// DeviceID limits the disconnect to one device; empty means all of them.
+	DeviceID string `json:"device_id"`
+	Code     int    `json:"code"`
+	Reason   string `json:"reason"`
+}
+
+// Displace describes the new session that displaces older ones.
+type Displace struct {
+	DeviceID string `json:"device_id"`
+	Platform string `json:"platform"`
+}
+
+// Bus carries deliveries between nodes.
+type Bus interface {
+	// Publish sends d to nodeID.
+	Publish(ctx context.Context, nodeID string, d *Delivery) error
+	// Subscribe hands every delivery addressed to nodeID to handler until ctx is done.
+	Subscribe(ctx context.Context, nodeID string, handler func(context.Context, *Delivery)) error
+	// Ready fails while the bus cannot carry deliveries, such as while it
+	// reconnects to a broker.
+	Ready() error
+	Close() error
+}
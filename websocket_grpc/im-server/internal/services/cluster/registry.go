@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/presence"
+)
+
+// presenceRegistry keeps the directory in sync with a node-local registry:
+// a user is registered on the node when its first session binds and
+// unregistered when its last session leaves.
+type presenceRegistry struct {
+	contract.Registry
+	directory presence.Directory
+	nodeID    string
+	mu        sync.Mutex
+}
+
+func NewPresenceRegistry(local contract.Registry, directory presence.Directory, nodeID string) contract.Registry {
+	return &presenceRegistry{Registry: local, directory: directory, nodeID: nodeID}
+}
+
+func (r *presenceRegistry) Bind(ctx context.Context, session contract.Session) ([]contract.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	displaced, err := r.Registry.Bind(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return displaced, r.directory.Register(ctx, session.UserID(), r.nodeID)
+}
+
+func (r *presenceRegistry) Unbind(ctx context.Context, session contract.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	userID := session.UserID()
+	if err := r.Registry.Unbind(ctx, session); err != nil {
+		return err
+	}
+	remaining, err := r.Registry.GetUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(remaining) > 0 {
+		return nil
+	}
+	return r.directory.Unregister(ctx, userID, r.nodeID)
+}
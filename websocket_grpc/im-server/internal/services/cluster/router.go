@@ -0,0 +1,167 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/presence"
+	"go.uber.org/zap"
+)
+
+type RouterOptions struct {
+	NodeID   string
+	Registry contract.Registry
+	// Directory and Bus are optional; without them the router only reaches
+	// sessions of this node.
+	Directory presence.Directory
+	Bus       Bus
+	Logger    *zap.Logger
+}
+
+// Router delivers payloads to every session of a user, forwarding to the
+// owning nodes over the bus when the user is connected elsewhere.
+type Router struct {
+	options RouterOptions
+}
+
+func NewRouter(options RouterOptions) *Router {
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	return &Router{options: options}
+}
+
+func (r *Router) Deliver(ctx context.Context, userID string, messageID int64, data []byte) error {
+	if err := r.deliverLocal(ctx, userID, messageID, data); err != nil {
+		return err
+	}
+	// The inbox keeps the message for replay, so a lost forward only delays it.
+	return r.forward(ctx, &Delivery{UserID: userID, MessageID: messageID, Payload: data})
+}
+
+// Online reports whether userID has a session on this node or, with a
+// directory, on any node.
+func (r *Router) Online(ctx context.Context, userID string) (bool, error) {
+	sessions, err := r.options.Registry.GetUserSessions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if len(sessions) > 0 || r.options.Directory == nil {
+		return len(sessions) > 0, nil
+	}
+	nodes, err := r.options.Directory.Nodes(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return len(nodes) > 0, nil
+}
+
+// Disconnect closes the user's sessions on every node, limited to deviceID
+// unless it is empty.
+func (r *Router) Disconnect(ctx context.Context, userID, deviceID string, code int, reason string) error {
+	d := &Delivery{UserID: userID, Disconnect: &Disconnect{DeviceID: deviceID, Code: code, Reason: reason}}
+	if err := r.disconnectLocal(ctx, userID, d.Disconnect); err != nil {
+		return err
+	}
+	return r.forward(ctx, d)
+}
+
+// Displace kicks the sessions of userID on the other nodes that the device
+// policy does not allow next to a new session of deviceID on platform.
+// Sessions on this node are displaced when the new one binds.
+func (r *Router) Displace(ctx context.Context, userID, deviceID, platform string) error {
+	return r.forward(ctx, &Delivery{UserID: userID, Displace: &Displace{DeviceID: deviceID, Platform: platform}})
+}
+
+// forward publishes d to the other nodes the user is connected to.
+func (r *Router) forward(ctx context.Context, d *Delivery) error {
+	if r.options.Directory == nil || r.options.Bus == nil {
+		return nil
+	}
+
+	nodes, err := r.options.Directory.Nodes(ctx, d.UserID)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node == r.options.NodeID {
+			continue
+		}
+		if err := r.options.Bus.Publish(ctx, node, d); err != nil {
+			r.options.Logger.Warn("failed to forward delivery",
+				zap.String("node_id", node), zap.String("user_id", d.UserID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Start consumes deliveries forwarded to this node until ctx is done.
+func (r *Router) Start(ctx context.Context) error {
+	if r.options.Bus == nil {
+		return nil
+	}
+	return r.options.Bus.Subscribe(ctx, r.options.NodeID, func(ctx context.Context, d *Delivery) {
+		if d.Disconnect != nil {
+			if err := r.disconnectLocal(ctx, d.UserID, d.Disconnect); err != nil {
+				r.options.Logger.Warn("failed to disconnect forwarded sessions", zap.String("user_id", d.UserID), zap.Error(err))
+			}
+			return
+		}
+		if d.Displace != nil {
+			if err := r.displaceLocal(ctx, d.UserID, d.Displace); err != nil {
+				r.options.Logger.Warn("failed to displace forwarded sessions", zap.String("user_id", d.UserID), zap.Error(err))
+			}
+			return
+		}
+		if err := r.deliverLocal(ctx, d.UserID, d.MessageID, d.Payload); err != nil {
+			r.options.Logger.Warn("failed to deliver forwarded payload", zap.String("user_id", d.UserID), zap.Error(err))
+		}
+	})
+}
+
+func (r *Router) deliverLocal(ctx context.Context, userID string, messageID int64, data []byte) error {
+	sessions, err := r.options.Registry.GetUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s == nil {
+			continue
+		}
+		// Message deliveries are retransmitted until acked, so send failures are not fatal.
+		if messageID != 0 {
+			_ = s.Deliver(messageID, data)
+		} else {
+			_ = s.Send(data)
+		}
+	}
+	return nil
+}
+
+func (r *Router) disconnectLocal(ctx context.Context, userID string, d *Disconnect) error {
+	sessions, err := r.options.Registry.GetUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, s := range sessions {
+		if s == nil || (d.DeviceID != "" && s.DeviceID() != d.DeviceID) {
+			continue
+		}
+		s.Disconnect(d.Code, d.Reason)
+	}
+	return nil
+}
+
+func (r *Router) displaceLocal(ctx context.Context, userID string, d *Displace) error {
+	displaced, err := r.options.Registry.Displace(ctx, userID, d.DeviceID, d.Platform)
+	if err != nil {
+		return err
+	}
+	for _, s := range displaced {
+		r.options.Logger.Info("session displaced from another node",
+			zap.String("user_id", userID), zap.String("device_id", s.DeviceID()), zap.String("by_device_id", d.DeviceID))
+		protocol.Kick(s, d.DeviceID, d.Platform)
+	}
+	return nil
+}
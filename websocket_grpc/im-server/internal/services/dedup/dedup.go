@@ -0,0 +1,131 @@
+// Package dedup recognizes message sends that a client retried, so a send
+// lost between the server storing it and the client seeing the ack is not
+// stored and delivered twice.
+package dedup
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultWindow       = 24 * time.Hour
+	defaultMaxPerSender = 1000
+)
+
+// Options bound what is remembered per sender; zero values use the defaults.
+type Options struct {
+	// Window is how long a send is remembered; 24 hours by default.
+	Window time.Duration
+	// MaxPerSender bounds the sends remembered per sender, newest first;
+	// 1000 by default.
+	MaxPerSender int
+	Logger       *zap.Logger
+}
+
+type sendKey struct {
+	sender      string
+	clientMsgID string
+}
+
+type DedupService struct {
+	clientSendRepository message.ClientSendRepository
+	options              Options
+	now                  func() time.Time
+
+	mu sync.Mutex
+	// inflight holds the sends being stored; concurrent retries wait on
+	// the channel, which is closed once the first one finished.
+	inflight map[sendKey]chan struct{}
+}
+
+func NewDedupService(clientSendRepository message.ClientSendRepository, opts Options) *DedupService {
+	if opts.Window <= 0 {
+		opts.Window = defaultWindow
+	}
+	if opts.MaxPerSender <= 0 {
+		opts.MaxPerSender = defaultMaxPerSender
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	return &DedupService{
+		clientSendRepository: clientSendRepository,
+		options:              opts,
+		now:                  time.Now,
+		inflight:             make(map[sendKey]chan struct{}),
+	}
+}
+
+// Send calls store, which stores a message and returns its ID, unless sender
+// already sent clientMsgID within the window. It returns the message ID and
+// whether the send is such a duplicate, in which case store was not called.
+//
+// Retries are only serialized within this process; two nodes receiving the
+// same retry at the same moment may both store it.
+func (s *DedupService) Send(ctx context.Context, sender, clientMsgID string, store func() (int64, error)) (int64, bool, error) {
+	unlock, err := s.lock(ctx, sendKey{sender: sender, clientMsgID: clientMsgID})
+	if err != nil {
+		return 0, false, err
+	}
+	defer unlock()
+
+	prev, err := s.clientSendRepository.GetClientSend(ctx, sender, clientMsgID)
+	switch {
+	case err == nil && s.now().Sub(prev.CreatedAt) < s.options.Window:
+		return prev.MessageID, true, nil
+	case err != nil && !errors.Is(err, respository.ErrNotFound):
+		return 0, false, err
+	}
+
+	id, err := store()
+	if err != nil {
+		return 0, false, err
+	}
+
+	// The message is stored, so failing to remember it only weakens the
+	// protection against a retry.
+	now := s.now()
+	send := &domainmessage.ClientSend{Sender: sender, ClientMsgID: clientMsgID, MessageID: id, CreatedAt: now}
+	if err := s.clientSendRepository.PutClientSend(ctx, send); err != nil {
+		s.options.Logger.Warn("failed to remember client send", zap.String("user_id", sender), zap.Error(err))
+		return id, false, nil
+	}
+	if err := s.clientSendRepository.TrimClientSends(ctx, sender, s.options.MaxPerSender, now.Add(-s.options.Window)); err != nil {
+		s.options.Logger.Warn("failed to trim client sends", zap.String("user_id", sender), zap.Error(err))
+	}
+	return id, false, nil
+}
+
+// lock waits until no other send of k is in flight and claims it.
+func (s *DedupService) lock(ctx context.Context, k sendKey) (func(), error) {
+	for {
+		s.mu.Lock()
+		busy, ok := s.inflight[k]
+		if !ok {
+			done := make(chan struct{})
+			s.inflight[k] = done
+			s.mu.Unlock()
+			return func() {
+				s.mu.Lock()
+				delete(s.inflight, k)
+				s.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-busy:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package friend
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domainfriend "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+)
+
+var (
+	ErrSelfRequest      = errors.New("cannot befriend yourself")
+	ErrAlreadyFriends   = errors.New("already friends")
+	ErrDuplicateRequest = errors.New("friend request already exists")
+	ErrRequestNotFound  = errors.New("friend request not found")
+	ErrNotRecipient     = errors.New("friend request is addressed to another user")
+	ErrRequestResolved  = errors.New("friend request was already answered")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+type FriendService struct {
+	friendRepository friend.FriendRepository
+	userRepository   user.UserRepository
+	transactor       respository.Transactor
+}
+
+// NewFriendService returns the friend service. userRepository is used to check
+// that the addressee of a request exists.
+func NewFriendService(friendRepository friend.FriendRepository, userRepository user.UserRepository, transactor respository.Transactor) *FriendService {
+	return &FriendService{friendRepository: friendRepository, userRepository: userRepository, transactor: transactor}
+}
+
+// Apply records a pending request from fromUser to toUser.
+func (s *FriendService) Apply(ctx context.Context, requestID, fromUser, toUser string) (*domainfriend.FriendRequest, error) {
+	if fromUser == toUser {
+		return nil, ErrSelfRequest
+	}
+	if _, err := s.userRepository.GetUserByUUID(ctx, toUser); err != nil {
+		if errors.Is(err, respository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	friends, err := s.friendRepository.IsFriend(ctx, fromUser, toUser)
+	if err != nil {
+		return nil, err
+	}
+	if friends {
+		return nil, ErrAlreadyFriends
+	}
+	pending, err := s.friendRepository.HasPendingRequest(ctx, fromUser, toUser)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrDuplicateRequest
+	}
+
+	now := time.Now()
+	req := &domainfriend.FriendRequest{
+		RequestID: requestID,
+		FromUser:  fromUser,
+		ToUser:    toUser,
+		Status:    domainfriend.RequestPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.friendRepository.CreateRequest(ctx, req); err != nil {
+		if errors.Is(err, respository.ErrConflict) {
+			return nil, ErrDuplicateRequest
+		}
+		return nil, err
+	}
+	return req, nil
+}
+
+// Accept makes both users friends. Only the addressee may accept. The request
+// is only marked accepted together with the friendship.
+func (s *FriendService) Accept(ctx context.Context, userID, requestID string) (*domainfriend.FriendRequest, error) {
+	var req *domainfriend.FriendRequest
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		req, err = s.resolve(ctx, userID, requestID, domainfriend.RequestAccepted)
+		if err != nil {
+			return err
+		}
+		return s.friendRepository.AddFriend(ctx, req.FromUser, req.ToUser)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Reject declines the request. Only the addressee may reject.
+func (s *FriendService) Reject(ctx context.Context, userID, requestID string) (*domainfriend.FriendRequest, error) {
+	return s.resolve(ctx, userID, requestID, domainfriend.RequestRejected)
+}
+
+func (s *FriendService) IsFriend(ctx context.Context, userID, friendID string) (bool, error) {
+	return s.friendRepository.IsFriend(ctx, userID, friendID)
+}
+
+// FriendIDs returns every friend of userID, for fan-out.
+func (s *FriendService) FriendIDs(ctx context.Context, userID string) ([]string, error) {
+	return s.friendRepository.ListFriendIDs(ctx, userID)
+}
+
+func (s *FriendService) resolve(ctx context.Context, userID, requestID string, status domainfriend.RequestStatus) (*domainfriend.FriendRequest, error) {
+	req, err := s.friendRepository.GetRequest(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, respository.ErrNotFound) {
+			return nil, ErrRequestNotFound
+		}
+		return nil, err
+	}
+	if req.ToUser != userID {
+		return nil, ErrNotRecipient
+	}
+	ok, err := s.friendRepository.ResolveRequest(ctx, requestID, status)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrRequestResolved
+	}
+	req.Status = status
+	return req, nil
+}
@@ -0,0 +1,419 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+)
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group already exists")
+
+	ErrAlreadyMember    = errors.New("already a member of the group")
+	ErrNotMember        = errors.New("not a member of the group")
+	ErrPermissionDenied = errors.New("group role does not allow this operation")
+	ErrInvalidRole      = errors.New("invalid group role")
+	ErrInvalidNotify    = errors.New("invalid notification level")
+	ErrDuplicateRequest = errors.New("join request already exists")
+	ErrRequestNotFound  = errors.New("join request not found")
+	ErrRequestResolved  = errors.New("join request was already answered")
+	ErrUserNotFound     = errors.New("user not found")
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type GroupService struct {
+	groupRepository group.GroupRepository
+	userRepository  user.UserRepository
+	transactor      respository.Transactor
+}
+
+// NewGroupService returns the group service. userRepository is used to check
+// that invited users exist.
+func NewGroupService(groupRepository group.GroupRepository, userRepository user.UserRepository, transactor respository.Transactor) *GroupService {
+	return &GroupService{groupRepository: groupRepository, userRepository: userRepository, transactor: transactor}
+}
+
+// Create stores a new group owned by ownerID and makes the owner its first member.
+func (s *GroupService) Create(ctx context.Context, ownerID, uuid, name string) (*domaingroup.Group, error) {
+	now := time.Now()
+	g := &domaingroup.Group{
+		UUID:      uuid,
+		Name:      name,
+		Owner:     ownerID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.groupRepository.CreateGroup(ctx, g); err != nil {
+			return err
+		}
+		return s.addMember(ctx, g.ID, ownerID, domaingroup.RoleOwner)
+	})
+	if errors.Is(err, respository.ErrConflict) {
+		return nil, ErrGroupExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Authorize checks that userID is a member of the group whose role allows
+// action. It returns ErrNotMember or ErrPermissionDenied otherwise.
+func (s *GroupService) Authorize(ctx context.Context, uuid, userID string, action domaingroup.Action) (*domaingroup.Group, error) {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.authorize(ctx, g, userID, action); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *GroupService) authorize(ctx context.Context, g *domaingroup.Group, userID string, action domaingroup.Action) (*domaingroup.GroupMember, error) {
+	m, err := s.member(ctx, g.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !domaingroup.Can(m.Role, action) {
+		return nil, ErrPermissionDenied
+	}
+	return m, nil
+}
+
+func (s *GroupService) member(ctx context.Context, groupID int64, userID string) (*domaingroup.GroupMember, error) {
+	m, err := s.groupRepository.GetGroupMember(ctx, groupID, userID)
+	if errors.Is(err, respository.ErrNotFound) {
+		return nil, ErrNotMember
+	}
+	return m, err
+}
+
+// Invite records actorID's pending invitation of userID to the group.
+// userID joins by accepting it.
+func (s *GroupService) Invite(ctx context.Context, requestID, actorID, uuid, userID string) (*domaingroup.GroupRequest, *domaingroup.Group, error) {
+	g, err := s.Authorize(ctx, uuid, actorID, domaingroup.ActionInvite)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := s.userRepository.GetUserByUUID(ctx, userID); err != nil {
+		if errors.Is(err, respository.ErrNotFound) {
+			return nil, nil, ErrUserNotFound
+		}
+		return nil, nil, err
+	}
+	req, err := s.request(ctx, g, requestID, userID, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, g, nil
+}
+
+// Kick removes userID from the group. actorID must outrank the target.
+func (s *GroupService) Kick(ctx context.Context, actorID, uuid, userID string) (*domaingroup.Group, error) {
+	var g *domaingroup.Group
+	// The ranks are checked in the same transaction that applies the change.
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if g, err = s.manage(ctx, actorID, uuid, userID, domaingroup.ActionKick); err != nil {
+			return err
+		}
+		return s.groupRepository.RemoveGroupMember(ctx, g.ID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetRole promotes or demotes userID. Ownership cannot be transferred this way.
+func (s *GroupService) SetRole(ctx context.Context, actorID, uuid, userID string, role domaingroup.Role) (*domaingroup.Group, error) {
+	if role != domaingroup.RoleMember && role != domaingroup.RoleAdmin {
+		return nil, ErrInvalidRole
+	}
+	var g *domaingroup.Group
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if g, err = s.manage(ctx, actorID, uuid, userID, domaingroup.ActionSetRole); err != nil {
+			return err
+		}
+		return s.groupRepository.UpdateGroupMemberRole(ctx, g.ID, userID, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// SetNotify stores userID's notification preference for the group.
+func (s *GroupService) SetNotify(ctx context.Context, uuid, userID string, level domaingroup.NotifyLevel) error {
+	if !level.Valid() {
+		return ErrInvalidNotify
+	}
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	err = s.groupRepository.UpdateGroupMemberNotify(ctx, g.ID, userID, level)
+	if errors.Is(err, respository.ErrNotFound) {
+		return ErrNotMember
+	}
+	return err
+}
+
+// manage authorizes actorID to perform action on the member userID.
+func (s *GroupService) manage(ctx context.Context, actorID, uuid, userID string, action domaingroup.Action) (*domaingroup.Group, error) {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := s.authorize(ctx, g, actorID, action)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.member(ctx, g.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !domaingroup.CanManage(actor.Role, target.Role, action) {
+		return nil, ErrPermissionDenied
+	}
+	return g, nil
+}
+
+// Rename changes the display name of the group.
+func (s *GroupService) Rename(ctx context.Context, actorID, uuid, name string) (*domaingroup.Group, error) {
+	g, err := s.Authorize(ctx, uuid, actorID, domaingroup.ActionRename)
+	if err != nil {
+		return nil, err
+	}
+	g.Name = name
+	g.UpdatedAt = time.Now()
+	if err := s.groupRepository.UpdateGroup(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Dissolve deletes the group and returns the members it had, so they can
+// be told about it.
+func (s *GroupService) Dissolve(ctx context.Context, actorID, uuid string) (*domaingroup.Group, []string, error) {
+	g, err := s.Authorize(ctx, uuid, actorID, domaingroup.ActionDissolve)
+	if err != nil {
+		return nil, nil, err
+	}
+	memberIDs, err := s.groupRepository.ListGroupMemberIDs(ctx, g.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.groupRepository.DeleteGroup(ctx, g.ID); err != nil {
+		return nil, nil, err
+	}
+	return g, memberIDs, nil
+}
+
+func (s *GroupService) Get(ctx context.Context, uuid string) (*domaingroup.Group, error) {
+	g, err := s.groupRepository.GetGroupByUUID(ctx, uuid)
+	if errors.Is(err, respository.ErrNotFound) {
+		return nil, ErrGroupNotFound
+	}
+	return g, err
+}
+
+// ListForUser returns the groups userID belongs to.
+func (s *GroupService) ListForUser(ctx context.Context, userID string, page, pageSize int) ([]*domaingroup.Group, error) {
+	page, pageSize = normalizePage(page, pageSize)
+	return s.groupRepository.ListUserGroups(ctx, userID, page, pageSize)
+}
+
+func (s *GroupService) Members(ctx context.Context, uuid string, page, pageSize int) ([]*domaingroup.GroupMember, error) {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+	return s.groupRepository.ListGroupMembers(ctx, g.ID, page, pageSize)
+}
+
+// MemberIDs returns every member of the group, for fan-out.
+func (s *GroupService) MemberIDs(ctx context.Context, uuid string) ([]string, error) {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepository.ListGroupMemberIDs(ctx, g.ID)
+}
+
+// MutedMembers returns the members of group uuid that are not alerted about
+// every message, with their notification level.
+func (s *GroupService) MutedMembers(ctx context.Context, uuid string) (map[string]domaingroup.NotifyLevel, error) {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupRepository.ListMutedGroupMembers(ctx, g.ID)
+}
+
+func (s *GroupService) IsMember(ctx context.Context, uuid, userID string) (bool, error) {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return false, err
+	}
+	return s.groupRepository.IsGroupMember(ctx, g.ID, userID)
+}
+
+// AddMember adds userID to the group as a plain member; adding an existing
+// member is a no-op.
+func (s *GroupService) AddMember(ctx context.Context, uuid, userID string) error {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	return s.addMember(ctx, g.ID, userID, domaingroup.RoleMember)
+}
+
+// Apply records userID's pending request to join the group. The group is
+// returned so the caller can notify its owner.
+func (s *GroupService) Apply(ctx context.Context, requestID, userID, uuid string) (*domaingroup.GroupRequest, *domaingroup.Group, error) {
+	g, err := s.Get(ctx, uuid)
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := s.request(ctx, g, requestID, userID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, g, nil
+}
+
+// request records a pending request for userID to join g, an invitation if
+// invitedBy is set.
+func (s *GroupService) request(ctx context.Context, g *domaingroup.Group, requestID, userID, invitedBy string) (*domaingroup.GroupRequest, error) {
+	member, err := s.groupRepository.IsGroupMember(ctx, g.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, ErrAlreadyMember
+	}
+	pending, err := s.groupRepository.HasPendingJoinRequest(ctx, g.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrDuplicateRequest
+	}
+
+	now := time.Now()
+	req := &domaingroup.GroupRequest{
+		RequestID: requestID,
+		GroupID:   g.ID,
+		UserID:    userID,
+		InvitedBy: invitedBy,
+		Status:    domaingroup.RequestPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.groupRepository.CreateJoinRequest(ctx, req); err != nil {
+		if errors.Is(err, respository.ErrConflict) {
+			return nil, ErrDuplicateRequest
+		}
+		return nil, err
+	}
+	return req, nil
+}
+
+// Accept adds the requested user to the group. Only the owner may accept an
+// application, and only the invitee an invitation.
+func (s *GroupService) Accept(ctx context.Context, userID, requestID string) (*domaingroup.GroupRequest, *domaingroup.Group, error) {
+	var (
+		req *domaingroup.GroupRequest
+		g   *domaingroup.Group
+	)
+	// The request is only answered once the applicant is in.
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if req, g, err = s.resolve(ctx, userID, requestID, domaingroup.RequestAccepted); err != nil {
+			return err
+		}
+		return s.addMember(ctx, g.ID, req.UserID, domaingroup.RoleMember)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, g, nil
+}
+
+// Reject declines the request. Only the owner may reject an application,
+// and only the invitee an invitation.
+func (s *GroupService) Reject(ctx context.Context, userID, requestID string) (*domaingroup.GroupRequest, *domaingroup.Group, error) {
+	return s.resolve(ctx, userID, requestID, domaingroup.RequestRejected)
+}
+
+func (s *GroupService) resolve(ctx context.Context, userID, requestID string, status domaingroup.RequestStatus) (*domaingroup.GroupRequest, *domaingroup.Group, error) {
+	req, err := s.groupRepository.GetJoinRequest(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, respository.ErrNotFound) {
+			return nil, nil, ErrRequestNotFound
+		}
+		return nil, nil, err
+	}
+	g, err := s.groupRepository.GetGroup(ctx, req.GroupID)
+	if err != nil {
+		if errors.Is(err, respository.ErrNotFound) {
+			return nil, nil, ErrGroupNotFound
+		}
+		return nil, nil, err
+	}
+	if req.InvitedBy != "" {
+		if req.UserID != userID {
+			return nil, nil, ErrPermissionDenied
+		}
+	} else if _, err := s.authorize(ctx, g, userID, domaingroup.ActionApprove); err != nil {
+		return nil, nil, err
+	}
+	ok, err := s.groupRepository.ResolveJoinRequest(ctx, requestID, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, ErrRequestResolved
+	}
+	req.Status = status
+	return req, g, nil
+}
+
+func (s *GroupService) addMember(ctx context.Context, groupID int64, userID string, role domaingroup.Role) error {
+	now := time.Now()
+	return s.groupRepository.AddGroupMember(ctx, &domaingroup.GroupMember{
+		GroupID:   groupID,
+		UserID:    userID,
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
@@ -0,0 +1,127 @@
+package history
+
+import (
+	"context"
+	"errors"
+
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+)
+
+var ErrInvalidConversation = errors.New("exactly one of peer and group is required")
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// Page is one batch of history in the order of its cursor's direction.
+type Page struct {
+	Messages []*domainmessage.Message
+	// HasMore reports whether more messages lie beyond the last one.
+	HasMore bool
+}
+
+type HistoryService struct {
+	messageRepository message.MessageRepository
+	groups            *group.GroupService
+}
+
+func NewHistoryService(messageRepository message.MessageRepository, groups *group.GroupService) *HistoryService {
+	return &HistoryService{messageRepository: messageRepository, groups: groups}
+}
+
+// Pull returns a page of userID's conversation with peer, or of the group
+// groupUUID, which userID must be a member of.
+func (s *HistoryService) Pull(ctx context.Context, userID, peer, groupUUID string, cursor message.Cursor) (*Page, error) {
+	if _, err := s.Conversation(ctx, userID, peer, groupUUID); err != nil {
+		return nil, err
+	}
+	limit := clampLimit(cursor.Limit)
+	// One extra row tells whether another page follows.
+	cursor.Limit = limit + 1
+
+	var (
+		messages []*domainmessage.Message
+		err      error
+	)
+	if groupUUID != "" {
+		messages, err = s.messageRepository.ListGroupMessages(ctx, groupUUID, cursor)
+	} else {
+		messages, err = s.messageRepository.ListConversation(ctx, userID, peer, cursor)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newPage(messages, limit), nil
+}
+
+// Sync returns the messages of the conversation with fromSeq <= Seq <=
+// toSeq, or up to the latest one if toSeq is zero, together with the
+// conversation's last sequence number.
+func (s *HistoryService) Sync(ctx context.Context, userID, peer, groupUUID string, fromSeq, toSeq int64, limit int) (*Page, int64, error) {
+	conversationID, err := s.Conversation(ctx, userID, peer, groupUUID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lastSeq, err := s.messageRepository.LastSeq(ctx, conversationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fromSeq < 1 {
+		fromSeq = 1
+	}
+	if toSeq <= 0 || toSeq > lastSeq {
+		toSeq = lastSeq
+	}
+	if fromSeq > toSeq {
+		return &Page{}, lastSeq, nil
+	}
+	limit = clampLimit(limit)
+	messages, err := s.messageRepository.ListSeqRange(ctx, conversationID, fromSeq, toSeq, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	return newPage(messages, limit), lastSeq, nil
+}
+
+// Conversation returns the ID of userID's conversation with peer or of the
+// group groupUUID, exactly one of which must be set, after checking that
+// userID may read it. Everybody may read their own 1:1 conversations.
+func (s *HistoryService) Conversation(ctx context.Context, userID, peer, groupUUID string) (string, error) {
+	if (peer == "") == (groupUUID == "") {
+		return "", ErrInvalidConversation
+	}
+	if groupUUID == "" {
+		return domainmessage.PairConversation(userID, peer), nil
+	}
+	if s.groups == nil {
+		return "", group.ErrGroupNotFound
+	}
+	if _, err := s.groups.Authorize(ctx, groupUUID, userID, domaingroup.ActionRead); err != nil {
+		return "", err
+	}
+	return domainmessage.GroupConversation(groupUUID), nil
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > maxLimit {
+		return maxLimit
+	}
+	return limit
+}
+
+// newPage trims messages, fetched with one extra row, to limit.
+func newPage(messages []*domainmessage.Message, limit int) *Page {
+	page := &Page{Messages: messages}
+	if len(messages) > limit {
+		page.Messages, page.HasMore = messages[:limit], true
+	}
+	return page
+}
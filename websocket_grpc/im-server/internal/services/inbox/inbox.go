@@ -2,19 +2,85 @@ package inbox
 
 import (
 	"context"
+	"time"
 
 	domaininbox "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/inbox"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
 	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
 )
 
+// Entry is an inbox entry together with the message it points to.
+type Entry struct {
+	Inbox   *domaininbox.Inbox
+	Message *domainmessage.Message
+}
+
 type InboxService struct {
-	inboxRepository inbox.InboxRepository
+	inboxRepository   inbox.InboxRepository
+	messageRepository message.MessageRepository
+	transactor        respository.Transactor
 }
 
-func NewInboxService(inboxRepository inbox.InboxRepository) *InboxService {
-	return &InboxService{inboxRepository: inboxRepository}
+func NewInboxService(inboxRepository inbox.InboxRepository, messageRepository message.MessageRepository, transactor respository.Transactor) *InboxService {
+	return &InboxService{inboxRepository: inboxRepository, messageRepository: messageRepository, transactor: transactor}
 }
 
 func (s *InboxService) CreateInbox(ctx context.Context, inbox *domaininbox.Inbox) error {
 	return s.inboxRepository.CreateInbox(ctx, inbox)
-}
\ No newline at end of file
+}
+
+// Store persists msg and fans it out into the inbox of every recipient.
+// The returned entries are in the same order as recipients. Either the
+// message and all entries are stored or nothing is.
+func (s *InboxService) Store(ctx context.Context, msg *domainmessage.Message, recipients []string) ([]*domaininbox.Inbox, error) {
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = time.Now()
+	}
+	var entries []*domaininbox.Inbox
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.messageRepository.CreateMessage(ctx, msg); err != nil {
+			return err
+		}
+		entries = make([]*domaininbox.Inbox, 0, len(recipients))
+		for _, uid := range recipients {
+			entries = append(entries, &domaininbox.Inbox{
+				UserID:    uid,
+				MessageID: msg.ID,
+				CreatedAt: msg.CreatedAt,
+				UpdatedAt: msg.CreatedAt,
+			})
+		}
+		return s.inboxRepository.CreateInboxes(ctx, entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Pending returns up to limit undelivered entries of userID after the entry
+// afterID, oldest first.
+func (s *InboxService) Pending(ctx context.Context, userID string, afterID int64, limit int) ([]*Entry, error) {
+	inboxes, err := s.inboxRepository.ListUndelivered(ctx, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*Entry, 0, len(inboxes))
+	for _, in := range inboxes {
+		msg, err := s.messageRepository.GetMessage(ctx, in.MessageID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, &Entry{Inbox: in, Message: msg})
+	}
+	return entries, nil
+}
+
+// MarkDelivered records that userID acknowledged messageIDs. Entries belong
+// to the user, so an acknowledgement from one device covers all of them.
+func (s *InboxService) MarkDelivered(ctx context.Context, userID string, messageIDs ...int64) error {
+	return s.inboxRepository.MarkDelivered(ctx, userID, messageIDs...)
+}
@@ -0,0 +1,202 @@
+// Package presence produces the ephemeral events of the protocol: online and
+// offline changes of users and typing indicators. Nothing here is persisted.
+package presence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	presencedir "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/presence"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+	"go.uber.org/zap"
+)
+
+type PresenceOptions struct {
+	// Grace delays offline announcements; a user reconnecting within it
+	// produces no event at all. Zero announces immediately.
+	Grace  time.Duration
+	Logger *zap.Logger
+}
+
+// PresenceService tells the online friends of a user when the user comes
+// online or goes offline.
+type PresenceService struct {
+	router  contract.Router
+	friends *friend.FriendService
+	options PresenceOptions
+
+	mu sync.Mutex
+	// pending holds the offline announcements waiting for Grace.
+	pending map[string]*time.Timer
+}
+
+func NewPresenceService(router contract.Router, friends *friend.FriendService, options PresenceOptions) *PresenceService {
+	if options.Logger == nil {
+		options.Logger = zap.NewNop()
+	}
+	return &PresenceService{
+		router:  router,
+		friends: friends,
+		options: options,
+		pending: make(map[string]*time.Timer),
+	}
+}
+
+// Online is called when the first session of userID binds.
+func (s *PresenceService) Online(ctx context.Context, userID string) {
+	s.mu.Lock()
+	if t, ok := s.pending[userID]; ok {
+		// Back within the grace period: friends never saw the user leave.
+		delete(s.pending, userID)
+		t.Stop()
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	s.announce(ctx, userID, true)
+}
+
+// Offline is called when the last session of userID unbinds.
+func (s *PresenceService) Offline(ctx context.Context, userID string) {
+	if s.options.Grace <= 0 {
+		s.announce(ctx, userID, false)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pending[userID]; ok {
+		return
+	}
+	var t *time.Timer
+	t = time.AfterFunc(s.options.Grace, func() {
+		s.mu.Lock()
+		if s.pending[userID] != t {
+			s.mu.Unlock()
+			return
+		}
+		delete(s.pending, userID)
+		s.mu.Unlock()
+		s.announce(context.Background(), userID, false)
+	})
+	s.pending[userID] = t
+}
+
+func (s *PresenceService) announce(ctx context.Context, userID string, online bool) {
+	if s.friends == nil || s.router == nil {
+		return
+	}
+	friendIDs, err := s.friends.FriendIDs(ctx, userID)
+	if err != nil {
+		s.options.Logger.Warn("failed to load friends for presence", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+	if len(friendIDs) == 0 {
+		return
+	}
+	data, err := protocol.EncodeServerMessage(&imv1.ServerEnvelope{
+		Payload: &imv1.ServerEnvelope_PresenceEvent{
+			PresenceEvent: &imv1.PresenceEvent{User: userID, Online: online, At: time.Now().UnixMilli()},
+		},
+	})
+	if err != nil {
+		return
+	}
+	for _, id := range friendIDs {
+		if err := s.router.Deliver(ctx, id, 0, data); err != nil {
+			s.options.Logger.Warn("failed to deliver presence", zap.String("user_id", id), zap.Error(err))
+		}
+	}
+}
+
+// registry reports the presence transitions of the registry it wraps.
+type registry struct {
+	contract.Registry
+	presence  *PresenceService
+	directory presencedir.Directory
+
+	mu sync.Mutex
+	// users holds a lock per user with binds or unbinds in flight, so the
+	// transitions of one user are ordered without blocking everyone else.
+	users map[string]*userLock
+}
+
+type userLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewRegistry wraps local so that the first bind and the last unbind of a
+// user are reported to presence. If directory is set, users still connected
+// to another node stay online.
+func NewRegistry(local contract.Registry, presence *PresenceService, directory presencedir.Directory) contract.Registry {
+	return &registry{Registry: local, presence: presence, directory: directory, users: make(map[string]*userLock)}
+}
+
+func (r *registry) Bind(ctx context.Context, session contract.Session) ([]contract.Session, error) {
+	unlock := r.lock(session.UserID())
+	defer unlock()
+
+	wasOnline := r.online(ctx, session.UserID())
+	displaced, err := r.Registry.Bind(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	if !wasOnline {
+		r.presence.Online(ctx, session.UserID())
+	}
+	return displaced, nil
+}
+
+func (r *registry) Unbind(ctx context.Context, session contract.Session) error {
+	unlock := r.lock(session.UserID())
+	defer unlock()
+
+	if err := r.Registry.Unbind(ctx, session); err != nil {
+		return err
+	}
+	if !r.online(ctx, session.UserID()) {
+		r.presence.Offline(ctx, session.UserID())
+	}
+	return nil
+}
+
+// lock takes the lock of userID and returns the function releasing it.
+func (r *registry) lock(userID string) func() {
+	r.mu.Lock()
+	l, ok := r.users[userID]
+	if !ok {
+		l = &userLock{}
+		r.users[userID] = l
+	}
+	l.refs++
+	r.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		r.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(r.users, userID)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// online reports whether userID has a session on this node or, with a
+// directory, on any node.
+func (r *registry) online(ctx context.Context, userID string) bool {
+	sessions, err := r.Registry.GetUserSessions(ctx, userID)
+	if err == nil && len(sessions) > 0 {
+		return true
+	}
+	if r.directory == nil {
+		return false
+	}
+	nodes, err := r.directory.Nodes(ctx, userID)
+	return err == nil && len(nodes) > 0
+}
@@ -0,0 +1,128 @@
+package presence
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+)
+
+// ErrNotFriend is returned for a 1:1 indicator to someone who is not a friend.
+var ErrNotFriend = errors.New("peer is not a friend")
+
+// staleTypingFactor times Interval is how long a start without a stop is
+// remembered.
+const staleTypingFactor = 10
+
+type TypingOptions struct {
+	// Interval is the minimum time between two forwarded starts of the same
+	// user in the same conversation; starts in between are coalesced.
+	Interval time.Duration
+	// MaxGroupSize disables typing indicators in larger groups. Zero means
+	// no limit.
+	MaxGroupSize int
+}
+
+// TypingService decides which typing indicators are forwarded and to whom.
+type TypingService struct {
+	history *history.HistoryService
+	groups  *group.GroupService
+	friends *friend.FriendService
+	options TypingOptions
+
+	mu        sync.Mutex
+	started   map[typingKey]time.Time // last forwarded start
+	lastSweep time.Time
+}
+
+type typingKey struct {
+	userID         string
+	conversationID string
+}
+
+// NewTypingService returns the typing service. friends, when set, limits 1:1
+// indicators to friends, like direct messages.
+func NewTypingService(history *history.HistoryService, groups *group.GroupService, friends *friend.FriendService, options TypingOptions) *TypingService {
+	return &TypingService{
+		history: history,
+		groups:  groups,
+		friends: friends,
+		options: options,
+		started: make(map[typingKey]time.Time),
+	}
+}
+
+// Typing returns the users to forward userID's typing indicator in the
+// conversation with peer or in the group groupUUID to, or none if the
+// indicator is coalesced. A stop is only forwarded after a start.
+func (s *TypingService) Typing(ctx context.Context, userID, peer, groupUUID string, typing bool) ([]string, error) {
+	conversationID, err := s.history.Conversation(ctx, userID, peer, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+	if groupUUID == "" && s.friends != nil && peer != userID {
+		ok, err := s.friends.IsFriend(ctx, userID, peer)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrNotFriend
+		}
+	}
+	if !s.allow(typingKey{userID: userID, conversationID: conversationID}, typing, time.Now()) {
+		return nil, nil
+	}
+	if groupUUID == "" {
+		return []string{peer}, nil
+	}
+
+	memberIDs, err := s.groups.MemberIDs(ctx, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+	if s.options.MaxGroupSize > 0 && len(memberIDs) > s.options.MaxGroupSize {
+		return nil, nil
+	}
+	recipients := memberIDs[:0]
+	for _, id := range memberIDs {
+		if id != userID {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients, nil
+}
+
+func (s *TypingService) allow(key typingKey, typing bool, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	last, active := s.started[key]
+	if !typing {
+		delete(s.started, key)
+		return active
+	}
+	if active && now.Sub(last) < s.options.Interval {
+		return false
+	}
+	s.started[key] = now
+	return true
+}
+
+// sweep forgets starts that were never followed by a stop.
+func (s *TypingService) sweep(now time.Time) {
+	ttl := staleTypingFactor * s.options.Interval
+	if now.Sub(s.lastSweep) < ttl {
+		return
+	}
+	s.lastSweep = now
+	for key, at := range s.started {
+		if now.Sub(at) >= ttl {
+			delete(s.started, key)
+		}
+	}
+}
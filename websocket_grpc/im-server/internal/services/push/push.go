@@ -0,0 +1,318 @@
+// Package push alerts offline users about the messages they missed through
+// an external notification gateway. Delivery is best effort: the inbox keeps
+// every message, so a lost notification only means a silent phone.
+package push
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"go.uber.org/zap"
+)
+
+// ErrRejected is wrapped by notifiers when the gateway refused a batch for
+// good. Such batches are not retried.
+var ErrRejected = errors.New("notification batch rejected")
+
+// Notification asks the gateway to alert UserID about a message.
+type Notification struct {
+	UserID      string `json:"user_id"`
+	MessageID   int64  `json:"message_id"`
+	From        string `json:"from"`
+	GroupUUID   string `json:"group_uuid,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	// Preview is the beginning of a text message; empty for other types.
+	Preview   string `json:"preview,omitempty"`
+	Mentioned bool   `json:"mentioned,omitempty"`
+	SentAt    int64  `json:"sent_at"` // unix milliseconds
+}
+
+// Notifier hands batches of notifications to a gateway.
+type Notifier interface {
+	Notify(ctx context.Context, batch []Notification) error
+	Close() error
+}
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultQueueSize     = 10000
+	defaultDedupWindow   = 10 * time.Minute
+	defaultMaxAttempts   = 5
+	defaultRetryBackoff  = time.Second
+)
+
+// Options tune batching and retries; zero values use the defaults.
+type Options struct {
+	// BatchSize caps the notifications per Notify call; 100 by default.
+	BatchSize int
+	// FlushInterval bounds how long a notification waits for its batch to
+	// fill; 1 second by default.
+	FlushInterval time.Duration
+	// QueueSize bounds the notifications waiting to be sent, and apart from
+	// them those waiting to be retried; more are dropped. 10000 by default.
+	QueueSize int
+	// DedupWindow is how long a user is not alerted again about the same
+	// message; 10 minutes by default.
+	DedupWindow time.Duration
+	// MaxAttempts bounds the tries per batch; 5 by default.
+	MaxAttempts int
+	// RetryBackoff is the wait before the first retry, doubled after each
+	// further failure; 1 second by default. Retries are checked every
+	// FlushInterval.
+	RetryBackoff time.Duration
+	Logger       *zap.Logger
+}
+
+type dedupKey struct {
+	userID    string
+	messageID int64
+}
+
+// PushService queues notifications for offline users and sends them to the
+// notifier in batches from a background goroutine.
+type PushService struct {
+	notifier Notifier
+	groups   *group.GroupService
+	options  Options
+	now      func() time.Time
+
+	queue   chan Notification
+	done    chan struct{}
+	stopped chan struct{}
+	closed  sync.Once
+
+	mu   sync.Mutex
+	seen map[dedupKey]time.Time
+}
+
+// NewPushService starts sending to notifier until Close. groups is optional;
+// without it group mute settings are ignored.
+func NewPushService(notifier Notifier, groups *group.GroupService, opts Options) *PushService {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.DedupWindow <= 0 {
+		opts.DedupWindow = defaultDedupWindow
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	if opts.Logger == nil {
+		opts.Logger = zap.NewNop()
+	}
+	s := &PushService{
+		notifier: notifier,
+		groups:   groups,
+		options:  opts,
+		now:      time.Now,
+		queue:    make(chan Notification, opts.QueueSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		seen:     make(map[dedupKey]time.Time),
+	}
+	go s.run()
+	return s
+}
+
+// Enqueue queues notifications, skipping group members whose notification
+// level does not cover the message and users already alerted about it. It
+// never blocks; notifications are dropped when the queue is full.
+func (s *PushService) Enqueue(ctx context.Context, notifications []Notification) {
+	muted := make(map[string]map[string]domaingroup.NotifyLevel)
+	for _, n := range notifications {
+		if n.GroupUUID != "" && s.groups != nil {
+			levels, ok := muted[n.GroupUUID]
+			if !ok {
+				var err error
+				if levels, err = s.groups.MutedMembers(ctx, n.GroupUUID); err != nil {
+					s.options.Logger.Warn("failed to load group notification levels", zap.String("group_uuid", n.GroupUUID), zap.Error(err))
+				}
+				muted[n.GroupUUID] = levels
+			}
+			if level, ok := levels[n.UserID]; ok && !level.Notifies(n.Mentioned) {
+				continue
+			}
+		}
+		if !s.firstSeen(n) {
+			continue
+		}
+
+		select {
+		case <-s.done:
+			return
+		case s.queue <- n:
+		default:
+			s.options.Logger.Warn("push queue is full, dropping notification",
+				zap.String("user_id", n.UserID), zap.Int64("message_id", n.MessageID))
+		}
+	}
+}
+
+// firstSeen records n and reports whether it is new within DedupWindow.
+// Messages without an ID, which were not persisted, cannot be told apart.
+func (s *PushService) firstSeen(n Notification) bool {
+	if n.MessageID == 0 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := dedupKey{userID: n.UserID, messageID: n.MessageID}
+	now := s.now()
+	if at, ok := s.seen[k]; ok && now.Sub(at) < s.options.DedupWindow {
+		return false
+	}
+	s.seen[k] = now
+	return true
+}
+
+// forget drops the dedup entries older than DedupWindow.
+func (s *PushService) forget() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for k, at := range s.seen {
+		if now.Sub(at) >= s.options.DedupWindow {
+			delete(s.seen, k)
+		}
+	}
+}
+
+// retry is a failed batch waiting for its next attempt.
+type retry struct {
+	batch    []Notification
+	attempts int
+	backoff  time.Duration
+	at       time.Time
+}
+
+func (s *PushService) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.options.FlushInterval)
+	defer ticker.Stop()
+
+	var (
+		batch   []Notification
+		retries []*retry
+	)
+	for {
+		select {
+		case n := <-s.queue:
+			batch = append(batch, n)
+			if len(batch) >= s.options.BatchSize {
+				retries = s.send(retries, &retry{batch: batch})
+				batch = nil
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				retries = s.send(retries, &retry{batch: batch})
+				batch = nil
+			}
+			retries = s.retryDue(retries)
+			s.forget()
+		case <-s.done:
+		drain:
+			for {
+				select {
+				case n := <-s.queue:
+					batch = append(batch, n)
+				default:
+					break drain
+				}
+			}
+			for len(batch) > 0 {
+				n := min(len(batch), s.options.BatchSize)
+				retries = s.send(retries, &retry{batch: batch[:n]})
+				batch = batch[n:]
+			}
+			for _, r := range retries {
+				s.options.Logger.Error("dropping push notifications on shutdown", zap.Int("count", len(r.batch)))
+			}
+			return
+		}
+	}
+}
+
+// send makes one attempt at r and returns retries with r added if it has to
+// be tried again. Retries wait for a later tick instead of blocking the
+// loop, so new notifications keep flowing while the gateway is down.
+// Retries hold at most QueueSize notifications; once the service is
+// closing a failed batch is dropped instead of retried.
+func (s *PushService) send(retries []*retry, r *retry) []*retry {
+	r.attempts++
+	err := s.notifier.Notify(context.Background(), r.batch)
+	if err == nil {
+		return retries
+	}
+	if errors.Is(err, ErrRejected) || r.attempts >= s.options.MaxAttempts {
+		s.options.Logger.Error("dropping push notifications",
+			zap.Int("count", len(r.batch)), zap.Int("attempts", r.attempts), zap.Error(err))
+		return retries
+	}
+	select {
+	case <-s.done:
+		s.options.Logger.Error("dropping push notifications on shutdown", zap.Int("count", len(r.batch)), zap.Error(err))
+		return retries
+	default:
+	}
+	pending := 0
+	for _, p := range retries {
+		pending += len(p.batch)
+	}
+	if pending+len(r.batch) > s.options.QueueSize {
+		s.options.Logger.Error("push retry queue is full, dropping notifications",
+			zap.Int("count", len(r.batch)), zap.Error(err))
+		return retries
+	}
+
+	if r.backoff == 0 {
+		r.backoff = s.options.RetryBackoff
+	} else {
+		r.backoff *= 2
+	}
+	r.at = s.now().Add(r.backoff)
+	s.options.Logger.Warn("failed to send push notifications, retrying",
+		zap.Int("count", len(r.batch)), zap.Duration("backoff", r.backoff), zap.Error(err))
+	return append(retries, r)
+}
+
+// retryDue retries the batches whose backoff has passed.
+func (s *PushService) retryDue(retries []*retry) []*retry {
+	now := s.now()
+	var waiting []*retry
+	for _, r := range retries {
+		if now.Before(r.at) {
+			waiting = append(waiting, r)
+			continue
+		}
+		waiting = s.send(waiting, r)
+	}
+	return waiting
+}
+
+// Close sends the queued notifications, then closes the notifier.
+// Notifications enqueued afterwards are dropped.
+func (s *PushService) Close() error {
+	var err error
+	s.closed.Do(func() {
+		close(s.done)
+		<-s.stopped
+		err = s.notifier.Close()
+	})
+	return err
+}
@@ -0,0 +1,98 @@
+package receipt
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/receipt"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+)
+
+var ErrInvalidSeq = errors.New("seq is past the last message of the conversation")
+
+// Unread is the read state of one conversation for one user.
+type Unread struct {
+	ReadSeq int64
+	LastSeq int64
+	// Count excludes the user's own messages.
+	Count int64
+}
+
+type ReceiptService struct {
+	readCursorRepository receipt.ReadCursorRepository
+	messageRepository    message.MessageRepository
+	history              *history.HistoryService
+	groups               *group.GroupService
+}
+
+func NewReceiptService(readCursorRepository receipt.ReadCursorRepository, messageRepository message.MessageRepository,
+	history *history.HistoryService, groups *group.GroupService) *ReceiptService {
+	return &ReceiptService{
+		readCursorRepository: readCursorRepository,
+		messageRepository:    messageRepository,
+		history:              history,
+		groups:               groups,
+	}
+}
+
+// MarkRead moves userID's read cursor in the conversation with peer, or in
+// the group groupUUID, to seq; zero marks the whole conversation read. It
+// returns the cursor reached and the users to send a receipt to, or no users
+// if the cursor was already there. The users include userID so all of its
+// devices can clear their badges.
+func (s *ReceiptService) MarkRead(ctx context.Context, userID, peer, groupUUID string, seq int64) (int64, []string, error) {
+	conversationID, err := s.history.Conversation(ctx, userID, peer, groupUUID)
+	if err != nil {
+		return 0, nil, err
+	}
+	lastSeq, err := s.messageRepository.LastSeq(ctx, conversationID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if seq == 0 {
+		seq = lastSeq
+	}
+	if seq < 0 || seq > lastSeq {
+		return 0, nil, ErrInvalidSeq
+	}
+
+	advanced, err := s.readCursorRepository.AdvanceReadCursor(ctx, userID, conversationID, seq)
+	if err != nil || !advanced {
+		return seq, nil, err
+	}
+	if groupUUID == "" {
+		if peer == userID {
+			return seq, []string{userID}, nil
+		}
+		return seq, []string{peer, userID}, nil
+	}
+	memberIDs, err := s.groups.MemberIDs(ctx, groupUUID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return seq, memberIDs, nil
+}
+
+// Unread returns userID's read state of the conversation with peer or of
+// the group groupUUID.
+func (s *ReceiptService) Unread(ctx context.Context, userID, peer, groupUUID string) (*Unread, error) {
+	conversationID, err := s.history.Conversation(ctx, userID, peer, groupUUID)
+	if err != nil {
+		return nil, err
+	}
+	readSeq, err := s.readCursorRepository.GetReadCursor(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	lastSeq, err := s.messageRepository.LastSeq(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.messageRepository.CountAfter(ctx, conversationID, readSeq, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Unread{ReadSeq: readSeq, LastSeq: lastSeq, Count: count}, nil
+}
@@ -0,0 +1,149 @@
+// Package revision lets senders edit or recall messages after they were sent.
+package revision
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+)
+
+var (
+	ErrMessageNotFound = errors.New("message not found")
+	ErrNotSender       = errors.New("only the sender can change a message")
+	ErrWindowClosed    = errors.New("the message is too old to be changed")
+	ErrRecalled        = errors.New("message was recalled")
+	ErrEmptyContent    = errors.New("message content is required")
+)
+
+const (
+	defaultRecallWindow = 2 * time.Minute
+	defaultEditWindow   = 15 * time.Minute
+)
+
+// Options bounds how long after sending a message may still be changed.
+// Zero values use 2 minutes for recalls and 15 minutes for edits.
+type Options struct {
+	RecallWindow time.Duration
+	EditWindow   time.Duration
+}
+
+type RevisionService struct {
+	messageRepository message.MessageRepository
+	inboxRepository   inbox.InboxRepository
+	transactor        respository.Transactor
+	options           Options
+	now               func() time.Time
+}
+
+func NewRevisionService(messageRepository message.MessageRepository, inboxRepository inbox.InboxRepository, transactor respository.Transactor, opts Options) *RevisionService {
+	if opts.RecallWindow <= 0 {
+		opts.RecallWindow = defaultRecallWindow
+	}
+	if opts.EditWindow <= 0 {
+		opts.EditWindow = defaultEditWindow
+	}
+	return &RevisionService{
+		messageRepository: messageRepository,
+		inboxRepository:   inboxRepository,
+		transactor:        transactor,
+		options:           opts,
+		now:               time.Now,
+	}
+}
+
+// Recall turns message messageID of userID into a tombstone. It returns the
+// updated message and the recipients whose inbox entries were reset so the
+// tombstone is delivered to them like a new message. Recalling a tombstone
+// again is a no-op that returns no recipients.
+func (s *RevisionService) Recall(ctx context.Context, userID string, messageID int64) (*domainmessage.Message, []string, error) {
+	m, err := s.load(ctx, userID, messageID, s.options.RecallWindow)
+	if err != nil {
+		if errors.Is(err, ErrRecalled) {
+			return m, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	now := s.now()
+	m.ContentType = domainmessage.ContentUntyped
+	m.Content = nil
+	m.RecalledAt = &now
+	saved, recipients, err := s.save(ctx, m)
+	if errors.Is(err, ErrRecalled) {
+		// recalled by a concurrent request since load; a no-op like above
+		m, err = s.messageRepository.GetMessage(ctx, messageID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return m, nil, nil
+	}
+	return saved, recipients, err
+}
+
+// Edit replaces the content of message messageID of userID, which may change
+// its content type. It returns the updated message and the recipients to
+// deliver it to again, as Recall.
+func (s *RevisionService) Edit(ctx context.Context, userID string, messageID int64, contentType string, content []byte) (*domainmessage.Message, []string, error) {
+	if len(content) == 0 {
+		return nil, nil, ErrEmptyContent
+	}
+	m, err := s.load(ctx, userID, messageID, s.options.EditWindow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := s.now()
+	m.ContentType = contentType
+	m.Content = content
+	m.EditedAt = &now
+	return s.save(ctx, m)
+}
+
+// load returns the message if userID sent it less than window ago. A
+// tombstone is returned together with ErrRecalled.
+func (s *RevisionService) load(ctx context.Context, userID string, messageID int64, window time.Duration) (*domainmessage.Message, error) {
+	m, err := s.messageRepository.GetMessage(ctx, messageID)
+	if errors.Is(err, respository.ErrNotFound) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.FromUser != userID {
+		return nil, ErrNotSender
+	}
+	if m.IsRecalled() {
+		return m, ErrRecalled
+	}
+	if s.now().Sub(m.CreatedAt) > window {
+		return nil, ErrWindowClosed
+	}
+	return m, nil
+}
+
+// save stores m and resets its inbox entries, so recipients that are offline
+// get the new state when their inbox is replayed. Both happen or neither. It
+// fails with ErrRecalled if the message was recalled since it was loaded.
+func (s *RevisionService) save(ctx context.Context, m *domainmessage.Message) (*domainmessage.Message, []string, error) {
+	var recipients []string
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.messageRepository.UpdateMessage(ctx, m); err != nil {
+			return err
+		}
+		var err error
+		recipients, err = s.inboxRepository.Redeliver(ctx, m.ID)
+		return err
+	})
+	if errors.Is(err, message.ErrRecalled) {
+		return nil, nil, ErrRecalled
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, recipients, nil
+}
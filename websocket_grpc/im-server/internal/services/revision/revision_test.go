@@ -0,0 +1,43 @@
+package revision
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+)
+
+func TestRevision_EditAfterRecallFromStaleLoad(t *testing.T) {
+	ctx := context.Background()
+	messages := memory.NewMessageRepository()
+	s := NewRevisionService(messages, memory.NewInboxRepository(), memory.NewTransactor(), Options{})
+
+	m := &domainmessage.Message{FromUser: "u1", ToUser: "u2", ContentType: domainmessage.ContentText, Content: []byte("hi"), CreatedAt: time.Now()}
+	if err := messages.CreateMessage(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	// the edit reads the message before the recall lands
+	stale, err := s.load(ctx, "u1", m.ID, s.options.EditWindow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Recall(ctx, "u1", m.ID); err != nil {
+		t.Fatal(err)
+	}
+	stale.Content = []byte("edited")
+	if _, _, err := s.save(ctx, stale); !errors.Is(err, ErrRecalled) {
+		t.Fatalf("stale edit: %v, want ErrRecalled", err)
+	}
+
+	got, err := messages.GetMessage(ctx, m.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsRecalled() || len(got.Content) != 0 {
+		t.Fatalf("recall was undone: %+v", got)
+	}
+}
@@ -0,0 +1,131 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domaintoken "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/token"
+	domainuser "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/user"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+)
+
+var (
+	ErrMissingIdentity    = errors.New("email or phone is required")
+	ErrUserExists         = errors.New("user already exists")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrPhoneTaken         = errors.New("phone is already registered")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// LoginBy selects the identity a login field is matched against.
+type LoginBy int
+
+const (
+	LoginByEmail LoginBy = iota + 1
+	LoginByPhone
+)
+
+type Options struct {
+	// PasswordCost is the bcrypt cost of new password hashes. Zero means
+	// bcrypt.DefaultCost; tests lower it to bcrypt.MinCost.
+	PasswordCost int
+}
+
+type UserService struct {
+	userRepository user.UserRepository
+	tokens         *auth.TokenService
+	options        Options
+}
+
+func NewUserService(userRepository user.UserRepository, tokens *auth.TokenService, opts Options) *UserService {
+	return &UserService{userRepository: userRepository, tokens: tokens, options: opts}
+}
+
+// Register creates an account identified by uuid. At least one of email and
+// phone is required, and both must be unused.
+func (s *UserService) Register(ctx context.Context, uuid, email, phone, name, password string) (*domainuser.User, error) {
+	if email == "" && phone == "" {
+		return nil, ErrMissingIdentity
+	}
+	if email != "" {
+		if _, err := s.userRepository.GetUserByEmail(ctx, email); err == nil {
+			return nil, ErrEmailTaken
+		} else if !errors.Is(err, respository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	if phone != "" {
+		if _, err := s.userRepository.GetUserByPhone(ctx, phone); err == nil {
+			return nil, ErrPhoneTaken
+		} else if !errors.Is(err, respository.ErrNotFound) {
+			return nil, err
+		}
+	}
+	hash, err := auth.HashPassword(password, s.options.PasswordCost)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	u := &domainuser.User{
+		UUID:         uuid,
+		Email:        email,
+		Phone:        phone,
+		Name:         name,
+		PasswordHash: hash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.userRepository.CreateUser(ctx, u); err != nil {
+		// Lost a race with a concurrent registration, or the uuid is taken.
+		if errors.Is(err, respository.ErrConflict) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// Login checks the password of the account matching field and issues an
+// access token for deviceID on platform.
+func (s *UserService) Login(ctx context.Context, by LoginBy, field, password, deviceID, platform string) (string, *domaintoken.Token, error) {
+	var (
+		u   *domainuser.User
+		err error
+	)
+	switch by {
+	case LoginByEmail:
+		u, err = s.userRepository.GetUserByEmail(ctx, field)
+	case LoginByPhone:
+		u, err = s.userRepository.GetUserByPhone(ctx, field)
+	default:
+		return "", nil, ErrInvalidCredentials
+	}
+	if errors.Is(err, respository.ErrNotFound) {
+		return "", nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if !auth.CheckPassword(u.PasswordHash, password) {
+		return "", nil, ErrInvalidCredentials
+	}
+	return s.tokens.Issue(ctx, u.UUID, deviceID, platform)
+}
+
+// Refresh exchanges the session's token for a new one.
+func (s *UserService) Refresh(ctx context.Context, p *auth.Principal) (string, *domaintoken.Token, error) {
+	raw, t, err := s.tokens.Refresh(ctx, p)
+	if errors.Is(err, auth.ErrUnauthorized) {
+		return "", nil, ErrInvalidCredentials
+	}
+	return raw, t, err
+}
+
+// Logout revokes the token the session authenticated with.
+func (s *UserService) Logout(ctx context.Context, p *auth.Principal) error {
+	return s.tokens.Revoke(ctx, p)
+}
@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/admin"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/user"
+	"github.com/gorilla/websocket"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func register(uuid, email, password string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_REGISTER,
+		Payload: &imv1.ClientEnvelope_Register{
+			Register: &imv1.Register{Uuid: uuid, Email: email, Name: uuid, Password: password},
+		},
+	}
+}
+
+func login(email, password string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_LOGIN,
+		Payload: &imv1.ClientEnvelope_Login{
+			Login: &imv1.Login{Type: imv1.LoginType_EMAIL, Field: email, Password: password, DeviceId: "d1"},
+		},
+	}
+}
+
+func TestWS_Account_RegisterLoginLogout(t *testing.T) {
+	tokens := auth.NewTokenService(memory.NewTokenRepository(), time.Hour)
+	users := user.NewUserService(memory.NewUserRepository(), tokens, user.Options{PasswordCost: bcrypt.MinCost})
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Authenticator = tokens
+		o.AllowAnonymous = true
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Users: users})
+	})
+	anon := dial(t, u, "")
+
+	// anonymous connections may only register and log in
+	writeEnvelope(t, anon, singleMessage("t1", "u2", "hi"))
+	expectError(t, anon, protocol.CodeUnauthorized)
+
+	writeEnvelope(t, anon, register("u1", "u1@example.com", "short"))
+	expectError(t, anon, protocol.CodeBadRequest)
+	writeEnvelope(t, anon, register("u1", "u1@example.com", "secret-password"))
+	expectAck(t, anon)
+	writeEnvelope(t, anon, register("u2", "u1@example.com", "secret-password"))
+	expectError(t, anon, protocol.CodeConflict)
+
+	writeEnvelope(t, anon, login("u1@example.com", "wrong-password"))
+	expectError(t, anon, protocol.CodeUnauthorized)
+	writeEnvelope(t, anon, login("u1@example.com", "secret-password"))
+	resp := readEnvelope(t, anon).GetLoginResp()
+	if resp.GetUuid() != "u1" || resp.GetToken() == "" || resp.GetExpiresAt() <= time.Now().Unix() {
+		t.Fatalf("unexpected login response: %v", resp)
+	}
+
+	// the issued token authenticates, and logging out revokes it
+	conn := dial(t, u, resp.GetToken())
+	writeEnvelope(t, conn, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_LOGOUT,
+		Payload: &imv1.ClientEnvelope_Logout{Logout: &imv1.Logout{Uuid: "u1"}},
+	})
+	expectAck(t, conn)
+
+	h := make(http.Header)
+	h.Add("Authorization", "Bearer "+resp.GetToken())
+	_, httpResp, err := websocket.DefaultDialer.Dial(u, h)
+	if err == nil || httpResp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("revoked token should be rejected, got %v", err)
+	}
+}
+
+func TestWS_Account_RefreshExpiryRevoke(t *testing.T) {
+	const ttl = time.Second
+	tokens := auth.NewTokenService(memory.NewTokenRepository(), ttl)
+	users := user.NewUserService(memory.NewUserRepository(), tokens, user.Options{PasswordCost: bcrypt.MinCost})
+	var router *cluster.Router
+	u := startServer(t, func(o *ws.ServerOptions) {
+		router = cluster.NewRouter(cluster.RouterOptions{Registry: o.Registry})
+		o.Router = router
+		o.Authenticator = tokens
+		o.AllowAnonymous = true
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Router: router, Users: users})
+	})
+	adminSrv := httptest.NewServer(admin.NewHandler(admin.Options{Token: "admin-secret", Tokens: tokens, Router: router}))
+	t.Cleanup(adminSrv.Close)
+
+	anon := dial(t, u, "")
+	writeEnvelope(t, anon, register("u1", "u1@example.com", "secret-password"))
+	expectAck(t, anon)
+	writeEnvelope(t, anon, login("u1@example.com", "secret-password"))
+	first := readEnvelope(t, anon).GetLoginResp().GetToken()
+
+	// refreshing moves the expiry and revokes the old token
+	conn := dial(t, u, first)
+	time.Sleep(ttl / 2)
+	writeEnvelope(t, conn, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_REFRESH_TOKEN,
+		Payload: &imv1.ClientEnvelope_RefreshToken{RefreshToken: &imv1.RefreshToken{}},
+	})
+	second := readEnvelope(t, conn).GetLoginResp().GetToken()
+	if second == "" || second == first {
+		t.Fatalf("expected a new token, got %q", second)
+	}
+	if _, err := tokens.Authenticate(t.Context(), first); err == nil {
+		t.Fatalf("old token should be revoked after refresh")
+	}
+	time.Sleep(ttl / 2)
+	writeEnvelope(t, conn, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ECHO,
+		Payload: &imv1.ClientEnvelope_Echo{Echo: &imv1.Echo{Message: []byte("still open")}},
+	})
+	if _, ok := readEnvelope(t, conn).Payload.(*imv1.ServerEnvelope_Echo); !ok {
+		t.Fatalf("refreshed session should stay open past the first token's expiry")
+	}
+
+	// without another refresh the session is closed when the token lapses
+	expectClose(t, conn, protocol.CloseTokenExpired)
+
+	// an admin revoke kicks the live session and invalidates the token
+	writeEnvelope(t, anon, login("u1@example.com", "secret-password"))
+	third := readEnvelope(t, anon).GetLoginResp().GetToken()
+	conn = dial(t, u, third)
+
+	req, _ := http.NewRequest(http.MethodPost, adminSrv.URL+"/admin/revoke?user_id=u1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("admin request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without admin token, got %d", resp.StatusCode)
+	}
+
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("admin request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	expectClose(t, conn, protocol.CloseRevoked)
+	if _, err := tokens.Authenticate(t.Context(), third); err == nil {
+		t.Fatalf("revoked token should be rejected")
+	}
+}
@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	blobtransport "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/blob"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/filesystem"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/blob"
+	"github.com/gorilla/websocket"
+)
+
+// pngHeader is enough for content sniffing to report image/png.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func requestBlobURL(t *testing.T, conn *websocket.Conn, env *imv1.ClientEnvelope) string {
+	t.Helper()
+	writeEnvelope(t, conn, env)
+	resp := readEnvelope(t, conn).GetBlobUrl()
+	if resp == nil || resp.GetUrl() == "" || resp.GetExpiresAt() == 0 {
+		t.Fatalf("expected blob_url, got %v", resp)
+	}
+	return resp.GetUrl()
+}
+
+func uploadURL(t *testing.T, conn *websocket.Conn) string {
+	return requestBlobURL(t, conn, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_CREATE_UPLOAD_URL,
+		Payload: &imv1.ClientEnvelope_CreateUploadUrl{CreateUploadUrl: &imv1.CreateUploadUrl{}},
+	})
+}
+
+func downloadURL(blobID string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_CREATE_DOWNLOAD_URL,
+		Payload: &imv1.ClientEnvelope_CreateDownloadUrl{CreateDownloadUrl: &imv1.CreateDownloadUrl{BlobId: blobID}},
+	}
+}
+
+type uploadResult struct {
+	BlobID      string `json:"blob_id"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+func put(t *testing.T, url string, body []byte) (int, uploadResult) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	defer resp.Body.Close()
+	var res uploadResult
+	if resp.StatusCode == http.StatusCreated {
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatalf("decode upload result: %v", err)
+		}
+	}
+	return resp.StatusCode, res
+}
+
+// startBlobServer starts a server whose dispatcher has a blob service with
+// opts.
+func startBlobServer(t *testing.T, opts blob.Options) string {
+	t.Helper()
+	// the blob endpoint runs on its own server here, so the signed URLs
+	// need its origin
+	mux := http.NewServeMux()
+	files := httptest.NewServer(mux)
+	t.Cleanup(files.Close)
+	store, err := filesystem.NewBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+	opts.BaseURL = files.URL
+	blobs, err := blob.NewBlobService(store, opts)
+	if err != nil {
+		t.Fatalf("blob service: %v", err)
+	}
+	mux.Handle(blob.PathPrefix, blobtransport.NewHandler(blobtransport.Options{Blobs: blobs}))
+
+	return startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Blobs:    blobs,
+			Content:  dispatch.ContentLimits{MaxImageBytes: 64},
+		})
+	})
+}
+
+func TestWS_Blob_UploadDownload(t *testing.T) {
+	u := startBlobServer(t, blob.Options{MaxUploadBytes: 64, UserQuotaBytes: 100})
+	conn := dial(t, u, "test:u1:d1")
+
+	image := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{1}, 32)...)
+	status, first := put(t, uploadURL(t, conn), image)
+	if status != http.StatusCreated || first.ContentType != "image/png" || first.Size != int64(len(image)) {
+		t.Fatalf("unexpected upload: %d %+v", status, first)
+	}
+	// the same bytes are stored once and do not count twice
+	status, second := put(t, uploadURL(t, conn), image)
+	if status != http.StatusCreated || second.BlobID != first.BlobID {
+		t.Fatalf("expected dedup, got %d %+v", status, second)
+	}
+
+	// over the upload limit
+	if status, _ := put(t, uploadURL(t, conn), bytes.Repeat([]byte("a"), 65)); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for a large upload, got %d", status)
+	}
+	// a tampered signature
+	if status, _ := put(t, uploadURL(t, conn)+"x", image); status != http.StatusForbidden {
+		t.Fatalf("expected 403 for a bad signature, got %d", status)
+	}
+	// 40 bytes used, so 60 more exhaust the quota of 100
+	if status, _ := put(t, uploadURL(t, conn), bytes.Repeat([]byte("b"), 60)); status != http.StatusCreated {
+		t.Fatalf("expected upload within quota, got %d", status)
+	}
+	if status, _ := put(t, uploadURL(t, conn), []byte("c")); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 over quota, got %d", status)
+	}
+
+	resp, err := http.Get(requestBlobURL(t, conn, downloadURL(first.BlobID)))
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	defer resp.Body.Close()
+	got, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" || !bytes.Equal(got, image) {
+		t.Fatalf("unexpected download: %d %s %d bytes", resp.StatusCode, resp.Header.Get("Content-Type"), len(got))
+	}
+
+	writeEnvelope(t, conn, downloadURL("missing"))
+	expectError(t, conn, protocol.CodeNotFound)
+}
+
+func imageMessage(to, blobID string, size int64) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_SINGLE_MESSAGE,
+		Payload: &imv1.ClientEnvelope_SingleMessage{SingleMessage: &imv1.SingleMessage{To: to, Content: &imv1.Content{
+			Body: &imv1.Content_Image{Image: &imv1.ImageContent{
+				Attachment: &imv1.Attachment{BlobId: blobID, MimeType: "image/png", Size: size},
+			}},
+		}}},
+	}
+}
+
+func TestWS_Blob_AttachmentsAreChecked(t *testing.T) {
+	u := startBlobServer(t, blob.Options{})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn2 := dial(t, u, "test:u2:d1")
+
+	small := append(append([]byte{}, pngHeader...), 1)
+	_, own := put(t, uploadURL(t, conn1), small)
+	large := append(append([]byte{}, pngHeader...), bytes.Repeat([]byte{2}, 64)...)
+	_, big := put(t, uploadURL(t, conn1), large)
+	_, other := put(t, uploadURL(t, conn2), append(append([]byte{}, pngHeader...), 3))
+	_, text := put(t, uploadURL(t, conn1), []byte("plain text"))
+
+	rejected := []*imv1.ClientEnvelope{
+		// a blob that was never uploaded
+		imageMessage("u2", strings.Repeat("0", 64), 9),
+		// a blob of another user
+		imageMessage("u2", other.BlobID, other.Size),
+		// a size under the limit for a blob over it
+		imageMessage("u2", big.BlobID, 9),
+		// an image attachment that is not an image
+		imageMessage("u2", text.BlobID, text.Size),
+	}
+	for _, env := range rejected {
+		writeEnvelope(t, conn1, env)
+		expectError(t, conn1, protocol.CodeBadRequest)
+	}
+
+	writeEnvelope(t, conn1, imageMessage("u2", own.BlobID, own.Size))
+	expectAck(t, conn1)
+	if ds := readDelivery(t, conn2); ds.GetContent().GetImage().GetAttachment().GetBlobId() != own.BlobID {
+		t.Fatalf("unexpected delivery: %v", ds)
+	}
+}
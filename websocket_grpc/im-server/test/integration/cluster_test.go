@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/presence"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+)
+
+// startNode runs one im-server node sharing directory, bus and inbox with its peers.
+func startNode(t *testing.T, ctx context.Context, nodeID string, directory presence.Directory, bus cluster.Bus, inboxSvc *inbox.InboxService) string {
+	t.Helper()
+	return startPolicyNode(t, ctx, nodeID, directory, bus, inboxSvc, ws.DevicePolicy{})
+}
+
+// startPolicyNode is startNode with a device policy for every user.
+func startPolicyNode(t *testing.T, ctx context.Context, nodeID string, directory presence.Directory, bus cluster.Bus, inboxSvc *inbox.InboxService, policy ws.DevicePolicy) string {
+	t.Helper()
+	return startServer(t, func(o *ws.ServerOptions) {
+		local := ws.NewRegistryWithPolicy(func(string) ws.DevicePolicy { return policy })
+		reg := cluster.NewPresenceRegistry(local, directory, nodeID)
+		router := cluster.NewRouter(cluster.RouterOptions{NodeID: nodeID, Registry: reg, Directory: directory, Bus: bus})
+		if err := router.Start(ctx); err != nil {
+			t.Fatalf("failed to start router: %v", err)
+		}
+		o.NodeID = nodeID
+		o.Registry = reg
+		o.Router = router
+		o.Inbox = inboxSvc
+	})
+}
+
+func TestWS_SingleMessage_CrossNode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	directory := memory.NewPresenceDirectory()
+	bus := memory.NewBus()
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), memory.NewMessageRepository(), memory.NewTransactor())
+	uA := startNode(t, ctx, "node-a", directory, bus, inboxSvc)
+	uB := startNode(t, ctx, "node-b", directory, bus, inboxSvc)
+
+	// receiver u2 on node b, sender u1 on node a
+	conn2 := dial(t, uB, "test:u2:d1")
+	conn1 := dial(t, uA, "test:u1:d1")
+
+	writeEnvelope(t, conn1, singleMessage("t-cross-node", "u2", "hi from a"))
+
+	ds := readDelivery(t, conn2)
+	if ds.GetFrom() != "u1" || !bytes.Equal(ds.GetMessage(), []byte("hi from a")) {
+		t.Fatalf("unexpected delivery: from=%s message=%q", ds.GetFrom(), ds.GetMessage())
+	}
+}
+
+func TestWS_Session_DisplacedAcrossNodes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	directory := memory.NewPresenceDirectory()
+	bus := memory.NewBus()
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), memory.NewMessageRepository(), memory.NewTransactor())
+	uA := startPolicyNode(t, ctx, "node-a", directory, bus, inboxSvc, ws.DevicePolicy{Mode: ws.DeviceModeSingle})
+	uB := startPolicyNode(t, ctx, "node-b", directory, bus, inboxSvc, ws.DevicePolicy{Mode: ws.DeviceModeSingle})
+
+	phone := dial(t, uA, "test:u1:d1:ios")
+	expectOpen(t, phone)
+	web := dial(t, uB, "test:u1:d2:web")
+	expectKicked(t, phone, imv1.Kicked_OTHER_DEVICE, "d2")
+	expectOpen(t, web)
+
+	// the kicked session left node a
+	nodes, err := directory.Nodes(ctx, "u1")
+	if err != nil || len(nodes) != 1 || nodes[0] != "node-b" {
+		t.Fatalf("expected u1 only on node-b, got %v (%v)", nodes, err)
+	}
+}
@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"google.golang.org/protobuf/proto"
+)
+
+func contentMessage(to string, c *imv1.Content) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_SINGLE_MESSAGE,
+		Payload: &imv1.ClientEnvelope_SingleMessage{
+			SingleMessage: &imv1.SingleMessage{To: to, Content: c},
+		},
+	}
+}
+
+func TestWS_Content_TypedBodies(t *testing.T) {
+	messages := memory.NewMessageRepository()
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), messages, memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inboxSvc
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Inbox:    inboxSvc,
+			Groups:   groups,
+			History:  history.NewHistoryService(messages, groups),
+			Content:  dispatch.ContentLimits{MaxTextBytes: 16, MaxImageBytes: 1024, MaxMentions: 1},
+		})
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn2 := dial(t, u, "test:u2:d1")
+
+	text := &imv1.Content{
+		Body: &imv1.Content_Text{Text: &imv1.TextContent{
+			Text:     "hi @u2",
+			Mentions: []*imv1.Mention{{User: "u2", Offset: 3, Length: 3}},
+		}},
+		ReplyTo: &imv1.ReplyTo{MessageId: 1},
+	}
+	image := &imv1.Content{Body: &imv1.Content_Image{Image: &imv1.ImageContent{
+		Attachment: &imv1.Attachment{BlobId: "b1", MimeType: "image/png", Size: 512},
+		Width:      10,
+		Height:     10,
+	}}}
+	for _, c := range []*imv1.Content{text, image} {
+		writeEnvelope(t, conn1, contentMessage("u2", c))
+		expectAck(t, conn1)
+		ds := readDelivery(t, conn2)
+		if len(ds.GetMessage()) != 0 || !proto.Equal(ds.GetContent(), c) {
+			t.Fatalf("expected content %v, got %v", c, ds)
+		}
+	}
+
+	// typed content survives storage
+	writeEnvelope(t, conn2, pullHistory(&imv1.PullHistory{Peer: "u1", Limit: 1}))
+	_, resp := readHistory(t, conn2)
+	if got := resp.GetMessages(); len(got) != 1 || !proto.Equal(got[0].GetContent(), image) {
+		t.Fatalf("unexpected history: %v", got)
+	}
+
+	rejected := []*imv1.ClientEnvelope{
+		// no body at all
+		contentMessage("u2", &imv1.Content{}),
+		// bytes and content together
+		{
+			Type: imv1.MessageType_SINGLE_MESSAGE,
+			Payload: &imv1.ClientEnvelope_SingleMessage{
+				SingleMessage: &imv1.SingleMessage{To: "u2", Message: []byte("x"), Content: text},
+			},
+		},
+		// text over the limit
+		contentMessage("u2", &imv1.Content{Body: &imv1.Content_Text{Text: &imv1.TextContent{Text: "this text is far too long"}}}),
+		// mention outside the text
+		contentMessage("u2", &imv1.Content{Body: &imv1.Content_Text{Text: &imv1.TextContent{
+			Text:     "hi",
+			Mentions: []*imv1.Mention{{User: "u2", Offset: 1, Length: 5}},
+		}}}),
+		// image over the limit, and an image that is not one
+		contentMessage("u2", &imv1.Content{Body: &imv1.Content_Image{Image: &imv1.ImageContent{
+			Attachment: &imv1.Attachment{BlobId: "b2", MimeType: "image/png", Size: 4096},
+		}}}),
+		contentMessage("u2", &imv1.Content{Body: &imv1.Content_Image{Image: &imv1.ImageContent{
+			Attachment: &imv1.Attachment{BlobId: "b3", MimeType: "application/pdf", Size: 10},
+		}}}),
+		contentMessage("u2", &imv1.Content{Body: &imv1.Content_Location{Location: &imv1.LocationContent{Latitude: 91}}}),
+		contentMessage("u2", &imv1.Content{Body: &imv1.Content_Custom{Custom: &imv1.CustomContent{Type: "card", Json: "{"}}}),
+	}
+	for _, env := range rejected {
+		writeEnvelope(t, conn1, env)
+		expectError(t, conn1, protocol.CodeBadRequest)
+	}
+}
@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/dedup"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+)
+
+func taggedMessage(to, clientMsgID, text string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_SINGLE_MESSAGE,
+		Payload: &imv1.ClientEnvelope_SingleMessage{
+			SingleMessage: &imv1.SingleMessage{To: to, ClientMsgId: clientMsgID, Message: []byte(text)},
+		},
+	}
+}
+
+// readSendAck reads the ack of a message send.
+func readSendAck(t *testing.T, conn *websocket.Conn) *imv1.AckResp {
+	t.Helper()
+	ack := readEnvelope(t, conn).GetAckResp()
+	if ack == nil || ack.GetMessageId() == 0 {
+		t.Fatalf("expected ack with a message ID, got %v", ack)
+	}
+	return ack
+}
+
+func TestWS_Dedup_RetriedSends(t *testing.T) {
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), memory.NewMessageRepository(), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inboxSvc
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Inbox:    inboxSvc,
+			Groups:   groups,
+			Sends:    dedup.NewDedupService(memory.NewClientSendRepository(), dedup.Options{}),
+		})
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn2 := dial(t, u, "test:u2:d1")
+
+	writeEnvelope(t, conn1, taggedMessage("u2", "c1", "hello"))
+	first := readSendAck(t, conn1)
+	if first.GetDuplicate() {
+		t.Fatalf("first send reported as duplicate: %v", first)
+	}
+	if ds := readDelivery(t, conn2); ds.GetMessageId() != first.GetMessageId() {
+		t.Fatalf("expected delivery of %d, got %v", first.GetMessageId(), ds)
+	}
+
+	// the retry is acked with the original ID and not delivered again, so
+	// the next delivery u2 sees is the next message
+	writeEnvelope(t, conn1, taggedMessage("u2", "c1", "hello"))
+	retry := readSendAck(t, conn1)
+	if !retry.GetDuplicate() || retry.GetMessageId() != first.GetMessageId() {
+		t.Fatalf("expected duplicate of %d, got %v", first.GetMessageId(), retry)
+	}
+	writeEnvelope(t, conn1, taggedMessage("u2", "c2", "again"))
+	second := readSendAck(t, conn1)
+	if second.GetDuplicate() || second.GetMessageId() == first.GetMessageId() {
+		t.Fatalf("expected a new message, got %v", second)
+	}
+	if ds := readDelivery(t, conn2); ds.GetMessageId() != second.GetMessageId() {
+		t.Fatalf("expected delivery of %d, got %v", second.GetMessageId(), ds)
+	}
+
+	// client message IDs are per sender
+	writeEnvelope(t, conn2, taggedMessage("u1", "c1", "hi"))
+	if ack := readSendAck(t, conn2); ack.GetDuplicate() {
+		t.Fatalf("send of another user reported as duplicate: %v", ack)
+	}
+	readDelivery(t, conn1)
+
+	// group sends are recognized the same way
+	writeEnvelope(t, conn1, createGroup("g1", "group one"))
+	expectAck(t, conn1)
+	groupSend := &imv1.ClientEnvelope{
+		Type: imv1.MessageType_GROUP_MESSAGE,
+		Payload: &imv1.ClientEnvelope_GroupMessage{GroupMessage: &imv1.GroupMessage{
+			Uuid: "g1", ClientMsgId: "g-1", Message: []byte("hi all"),
+		}},
+	}
+	writeEnvelope(t, conn1, groupSend)
+	sent := readSendAck(t, conn1)
+	readEnvelope(t, conn1) // the sender's own copy
+	writeEnvelope(t, conn1, groupSend)
+	if ack := readSendAck(t, conn1); !ack.GetDuplicate() || ack.GetMessageId() != sent.GetMessageId() {
+		t.Fatalf("expected duplicate of %d, got %v", sent.GetMessageId(), ack)
+	}
+
+	writeEnvelope(t, conn1, taggedMessage("u2", strings.Repeat("x", 65), "hello"))
+	expectError(t, conn1, protocol.CodeBadRequest)
+}
+
+func TestWS_Dedup_ConcurrentRetries(t *testing.T) {
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), memory.NewMessageRepository(), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inboxSvc
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Inbox:    inboxSvc,
+			Sends:    dedup.NewDedupService(memory.NewClientSendRepository(), dedup.Options{}),
+		})
+	})
+	// the same send arrives over two connections of the sender at once
+	conns := []*websocket.Conn{dial(t, u, "test:u1:d1"), dial(t, u, "test:u1:d2")}
+	for _, conn := range conns {
+		writeEnvelope(t, conn, taggedMessage("u2", "c1", "hello"))
+	}
+	acks := []*imv1.AckResp{readSendAck(t, conns[0]), readSendAck(t, conns[1])}
+	if acks[0].GetMessageId() != acks[1].GetMessageId() || acks[0].GetDuplicate() == acks[1].GetDuplicate() {
+		t.Fatalf("expected one message and one duplicate, got %v", acks)
+	}
+}
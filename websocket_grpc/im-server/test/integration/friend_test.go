@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+)
+
+func TestWS_Friend_ApplyAccept(t *testing.T) {
+	friends := friend.NewFriendService(memory.NewFriendRepository(), newUsers(t, "u1", "u2"), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Friends: friends})
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn2 := dial(t, u, "test:u2:d1")
+
+	// requests to unknown users are refused
+	writeEnvelope(t, conn1, &imv1.ClientEnvelope{
+		Type: imv1.MessageType_APPLY_FOR_FRIEND,
+		Payload: &imv1.ClientEnvelope_ApplyForFriend{
+			ApplyForFriend: &imv1.ApplyForFriend{RequestId: "r0", Uuid: "nobody"},
+		},
+	})
+	expectError(t, conn1, protocol.CodeNotFound)
+
+	// u1 applies, u2 is notified
+	writeEnvelope(t, conn1, &imv1.ClientEnvelope{
+		TraceId: "t-apply",
+		Type:    imv1.MessageType_APPLY_FOR_FRIEND,
+		Payload: &imv1.ClientEnvelope_ApplyForFriend{
+			ApplyForFriend: &imv1.ApplyForFriend{RequestId: "r1", Uuid: "u2"},
+		},
+	})
+	expectAck(t, conn1)
+	ev := readEnvelope(t, conn2).GetFriendEvent()
+	if ev.GetKind() != imv1.FriendEvent_APPLIED || ev.GetRequestId() != "r1" || ev.GetFrom() != "u1" {
+		t.Fatalf("unexpected friend event: %v", ev)
+	}
+
+	// only the addressee may answer
+	writeEnvelope(t, conn1, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ACCEPT_FRIEND,
+		Payload: &imv1.ClientEnvelope_AcceptFriend{AcceptFriend: &imv1.AcceptFriend{RequestId: "r1"}},
+	})
+	expectError(t, conn1, protocol.CodeForbidden)
+
+	// u2 accepts, u1 is notified
+	writeEnvelope(t, conn2, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ACCEPT_FRIEND,
+		Payload: &imv1.ClientEnvelope_AcceptFriend{AcceptFriend: &imv1.AcceptFriend{RequestId: "r1"}},
+	})
+	expectAck(t, conn2)
+	ev = readEnvelope(t, conn1).GetFriendEvent()
+	if ev.GetKind() != imv1.FriendEvent_ACCEPTED || ev.GetRequestId() != "r1" {
+		t.Fatalf("unexpected friend event: %v", ev)
+	}
+
+	// answering twice is a conflict
+	writeEnvelope(t, conn2, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_REJECT_FRIEND,
+		Payload: &imv1.ClientEnvelope_RejectFriend{RejectFriend: &imv1.RejectFriend{RequestId: "r1"}},
+	})
+	expectError(t, conn2, protocol.CodeConflict)
+}
+
+func TestWS_Friend_MessageNeedsFriendship(t *testing.T) {
+	friends := friend.NewFriendService(memory.NewFriendRepository(), newUsers(t, "u1", "u2"), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Friends: friends})
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn2 := dial(t, u, "test:u2:d1")
+	expectOpen(t, conn2) // bound before it is notified
+
+	// strangers cannot message each other
+	writeEnvelope(t, conn1, singleMessage("t-stranger", "u2", "hi"))
+	expectError(t, conn1, protocol.CodeForbidden)
+
+	// a pending request is not enough either
+	writeEnvelope(t, conn1, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_APPLY_FOR_FRIEND,
+		Payload: &imv1.ClientEnvelope_ApplyForFriend{ApplyForFriend: &imv1.ApplyForFriend{RequestId: "r1", Uuid: "u2"}},
+	})
+	expectAck(t, conn1)
+	readEnvelope(t, conn2) // the request notification
+	writeEnvelope(t, conn1, singleMessage("t-pending", "u2", "hi"))
+	expectError(t, conn1, protocol.CodeForbidden)
+
+	writeEnvelope(t, conn2, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ACCEPT_FRIEND,
+		Payload: &imv1.ClientEnvelope_AcceptFriend{AcceptFriend: &imv1.AcceptFriend{RequestId: "r1"}},
+	})
+	expectAck(t, conn2)
+	readEnvelope(t, conn1) // the acceptance notification
+
+	// friends can, both ways
+	writeEnvelope(t, conn2, singleMessage("t-friend", "u1", "hello"))
+	expectAck(t, conn2)
+	if ds := readDelivery(t, conn1); ds.GetFrom() != "u2" {
+		t.Fatalf("unexpected delivery from %s", ds.GetFrom())
+	}
+}
@@ -0,0 +1,310 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	domainuser "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/user"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/user"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+)
+
+// newUsers returns a user repository holding accounts for ids.
+func newUsers(t *testing.T, ids ...string) user.UserRepository {
+	t.Helper()
+	users := memory.NewUserRepository()
+	for _, id := range ids {
+		if err := users.CreateUser(context.Background(), &domainuser.User{UUID: id, Name: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return users
+}
+
+func inviteGroupMember(uuid, user, requestID string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_INVITE_GROUP_MEMBER,
+		Payload: &imv1.ClientEnvelope_InviteGroupMember{
+			InviteGroupMember: &imv1.InviteGroupMember{Uuid: uuid, User: user, RequestId: requestID},
+		},
+	}
+}
+
+// inviteAndJoin has inviter invite the user of invitee, who accepts.
+func inviteAndJoin(t *testing.T, inviter, invitee *websocket.Conn, uuid, user, requestID string) {
+	t.Helper()
+	writeEnvelope(t, inviter, inviteGroupMember(uuid, user, requestID))
+	expectAck(t, inviter)
+	if ev := readEnvelope(t, invitee).GetGroupEvent(); ev.GetKind() != imv1.GroupEvent_INVITED || ev.GetRequestId() != requestID {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+	writeEnvelope(t, invitee, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ACCEPT_GROUP,
+		Payload: &imv1.ClientEnvelope_AcceptGroup{AcceptGroup: &imv1.AcceptGroup{RequestId: requestID}},
+	})
+	expectAck(t, invitee)
+	if ev := readEnvelope(t, inviter).GetGroupEvent(); ev.GetKind() != imv1.GroupEvent_JOIN_ACCEPTED || ev.GetUser() != user {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+}
+
+func createGroup(uuid, name string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_CREATE_GROUP,
+		Payload: &imv1.ClientEnvelope_CreateGroup{CreateGroup: &imv1.CreateGroup{Uuid: uuid, Name: name}},
+	}
+}
+
+func TestWS_Group_CreateListMembers(t *testing.T) {
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Groups: groups})
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+
+	writeEnvelope(t, conn1, createGroup("g1", "group one"))
+	expectAck(t, conn1)
+	writeEnvelope(t, conn1, createGroup("g1", "again"))
+	expectError(t, conn1, protocol.CodeConflict)
+
+	writeEnvelope(t, conn1, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_LIST_GROUPS,
+		Payload: &imv1.ClientEnvelope_ListGroups{ListGroups: &imv1.ListGroups{}},
+	})
+	list := readEnvelope(t, conn1).GetListGroupsResp()
+	if len(list.GetGroupInfo()) != 1 {
+		t.Fatalf("expected one group, got %v", list)
+	}
+	if gi := list.GetGroupInfo()[0]; gi.GetUuid() != "g1" || gi.GetName() != "group one" || gi.GetOwner() != "u1" {
+		t.Fatalf("unexpected group info: %v", gi)
+	}
+
+	writeEnvelope(t, conn1, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_LIST_GROUP_MEMBER,
+		Payload: &imv1.ClientEnvelope_ListGroupMemeber{ListGroupMemeber: &imv1.ListGroupMember{Uuid: "g1"}},
+	})
+	members := readEnvelope(t, conn1).GetListGroupMemeberResp()
+	if len(members.GetGroupMember()) != 1 || members.GetGroupMember()[0].GetUuid() != "u1" {
+		t.Fatalf("expected owner as only member, got %v", members)
+	}
+
+	writeEnvelope(t, conn1, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_LIST_GROUP_MEMBER,
+		Payload: &imv1.ClientEnvelope_ListGroupMemeber{ListGroupMemeber: &imv1.ListGroupMember{Uuid: "missing"}},
+	})
+	expectError(t, conn1, protocol.CodeNotFound)
+
+	// outsiders cannot see who is in the group
+	conn2 := dial(t, u, "test:u2:d1")
+	writeEnvelope(t, conn2, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_LIST_GROUP_MEMBER,
+		Payload: &imv1.ClientEnvelope_ListGroupMemeber{ListGroupMemeber: &imv1.ListGroupMember{Uuid: "g1"}},
+	})
+	expectError(t, conn2, protocol.CodeNotMember)
+}
+
+func groupMessage(uuid, text string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_GROUP_MESSAGE,
+		Payload: &imv1.ClientEnvelope_GroupMessage{GroupMessage: &imv1.GroupMessage{Uuid: uuid, Message: []byte(text)}},
+	}
+}
+
+func TestWS_Group_JoinApproval(t *testing.T) {
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Groups: groups})
+	})
+	owner := dial(t, u, "test:u1:d1")
+	applicant := dial(t, u, "test:u2:d1")
+
+	writeEnvelope(t, owner, createGroup("g1", "group one"))
+	expectAck(t, owner)
+
+	// non-members may not post
+	writeEnvelope(t, applicant, groupMessage("g1", "hi"))
+	expectError(t, applicant, protocol.CodeNotMember)
+
+	// u2 applies, the owner is notified
+	writeEnvelope(t, applicant, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_APPLY_FOR_GROUP,
+		Payload: &imv1.ClientEnvelope_ApplyForGroup{ApplyForGroup: &imv1.ApplyForGroup{RequestId: "r1", Uuid: "g1"}},
+	})
+	expectAck(t, applicant)
+	ev := readEnvelope(t, owner).GetGroupEvent()
+	if ev.GetKind() != imv1.GroupEvent_JOIN_APPLIED || ev.GetRequestId() != "r1" || ev.GetGroupUuid() != "g1" || ev.GetUser() != "u2" {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+
+	// only the owner may answer
+	writeEnvelope(t, applicant, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ACCEPT_GROUP,
+		Payload: &imv1.ClientEnvelope_AcceptGroup{AcceptGroup: &imv1.AcceptGroup{RequestId: "r1"}},
+	})
+	expectError(t, applicant, protocol.CodeNotMember)
+
+	// the owner accepts, the applicant is notified
+	writeEnvelope(t, owner, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ACCEPT_GROUP,
+		Payload: &imv1.ClientEnvelope_AcceptGroup{AcceptGroup: &imv1.AcceptGroup{RequestId: "r1"}},
+	})
+	expectAck(t, owner)
+	ev = readEnvelope(t, applicant).GetGroupEvent()
+	if ev.GetKind() != imv1.GroupEvent_JOIN_ACCEPTED || ev.GetRequestId() != "r1" {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+
+	// answering twice is a conflict
+	writeEnvelope(t, owner, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_REJECT_GROUP,
+		Payload: &imv1.ClientEnvelope_RejectGroup{RejectGroup: &imv1.RejectGroup{RequestId: "r1"}},
+	})
+	expectError(t, owner, protocol.CodeConflict)
+
+	// the new member can post and the owner receives it
+	writeEnvelope(t, applicant, groupMessage("g1", "hello"))
+	expectAck(t, applicant)
+	got := readEnvelope(t, owner).GetDeliverGroupMessage()
+	if got.GetFrom() != "u2" || string(got.GetMessage()) != "hello" {
+		t.Fatalf("unexpected group delivery: %v", got)
+	}
+}
+
+func TestWS_Group_RolePolicy(t *testing.T) {
+	groups := group.NewGroupService(memory.NewGroupRepository(), newUsers(t, "u1", "u2", "u3"), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Groups: groups})
+	})
+	owner := dial(t, u, "test:u1:d1")
+	admin := dial(t, u, "test:u2:d1")
+	member := dial(t, u, "test:u3:d1")
+
+	writeEnvelope(t, owner, createGroup("g1", "group one"))
+	expectAck(t, owner)
+
+	// the owner invites u2 and makes them an admin once they joined
+	inviteAndJoin(t, owner, admin, "g1", "u2", "i1")
+	writeEnvelope(t, owner, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_SET_GROUP_ROLE,
+		Payload: &imv1.ClientEnvelope_SetGroupRole{SetGroupRole: &imv1.SetGroupRole{Uuid: "g1", User: "u2", Role: imv1.GroupRole_GROUP_ROLE_ADMIN}},
+	})
+	expectAck(t, owner)
+	if ev := readEnvelope(t, admin).GetGroupEvent(); ev.GetKind() != imv1.GroupEvent_ROLE_CHANGED || ev.GetRole() != imv1.GroupRole_GROUP_ROLE_ADMIN {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+
+	// admins may invite
+	inviteAndJoin(t, admin, member, "g1", "u3", "i2")
+
+	// members may neither kick nor rename
+	writeEnvelope(t, member, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_KICK_GROUP_MEMBER,
+		Payload: &imv1.ClientEnvelope_KickGroupMember{KickGroupMember: &imv1.KickGroupMember{Uuid: "g1", User: "u2"}},
+	})
+	expectError(t, member, protocol.CodePermissionDenied)
+	writeEnvelope(t, member, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_RENAME_GROUP,
+		Payload: &imv1.ClientEnvelope_RenameGroup{RenameGroup: &imv1.RenameGroup{Uuid: "g1", Name: "mine"}},
+	})
+	expectError(t, member, protocol.CodePermissionDenied)
+
+	// admins cannot kick the owner or dissolve the group
+	writeEnvelope(t, admin, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_KICK_GROUP_MEMBER,
+		Payload: &imv1.ClientEnvelope_KickGroupMember{KickGroupMember: &imv1.KickGroupMember{Uuid: "g1", User: "u1"}},
+	})
+	expectError(t, admin, protocol.CodePermissionDenied)
+	writeEnvelope(t, admin, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_DISSOLVE_GROUP,
+		Payload: &imv1.ClientEnvelope_DissolveGroup{DissolveGroup: &imv1.DissolveGroup{Uuid: "g1"}},
+	})
+	expectError(t, admin, protocol.CodePermissionDenied)
+
+	// an admin kicks the member, who can no longer post
+	writeEnvelope(t, admin, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_KICK_GROUP_MEMBER,
+		Payload: &imv1.ClientEnvelope_KickGroupMember{KickGroupMember: &imv1.KickGroupMember{Uuid: "g1", User: "u3"}},
+	})
+	expectAck(t, admin)
+	if ev := readEnvelope(t, member).GetGroupEvent(); ev.GetKind() != imv1.GroupEvent_KICKED || ev.GetOperator() != "u2" {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+	writeEnvelope(t, member, groupMessage("g1", "hi"))
+	expectError(t, member, protocol.CodeNotMember)
+
+	// the owner dissolves the group and the remaining members are told
+	writeEnvelope(t, owner, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_DISSOLVE_GROUP,
+		Payload: &imv1.ClientEnvelope_DissolveGroup{DissolveGroup: &imv1.DissolveGroup{Uuid: "g1"}},
+	})
+	expectAck(t, owner)
+	for _, conn := range []*websocket.Conn{owner, admin} {
+		if ev := readEnvelope(t, conn).GetGroupEvent(); ev.GetKind() != imv1.GroupEvent_DISSOLVED || ev.GetGroupUuid() != "g1" {
+			t.Fatalf("unexpected group event: %v", ev)
+		}
+	}
+	writeEnvelope(t, owner, groupMessage("g1", "hi"))
+	expectError(t, owner, protocol.CodeNotFound)
+}
+
+func TestWS_Group_Invitation(t *testing.T) {
+	groups := group.NewGroupService(memory.NewGroupRepository(), newUsers(t, "u1", "u2", "u3"), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Groups: groups})
+	})
+	owner := dial(t, u, "test:u1:d1")
+	invitee := dial(t, u, "test:u2:d1")
+	other := dial(t, u, "test:u3:d1")
+	expectOpen(t, invitee) // bound before it is invited
+
+	writeEnvelope(t, owner, createGroup("g1", "group one"))
+	expectAck(t, owner)
+
+	// unknown users cannot be invited
+	writeEnvelope(t, owner, inviteGroupMember("g1", "ghost", "i0"))
+	expectError(t, owner, protocol.CodeNotFound)
+
+	// an invitation does not make a member
+	writeEnvelope(t, owner, inviteGroupMember("g1", "u2", "i1"))
+	expectAck(t, owner)
+	if ev := readEnvelope(t, invitee).GetGroupEvent(); ev.GetKind() != imv1.GroupEvent_INVITED || ev.GetOperator() != "u1" || ev.GetRequestId() != "i1" {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+	writeEnvelope(t, invitee, groupMessage("g1", "hi"))
+	expectError(t, invitee, protocol.CodeNotMember)
+	writeEnvelope(t, owner, inviteGroupMember("g1", "u2", "i2"))
+	expectError(t, owner, protocol.CodeConflict)
+
+	// only the invitee answers, not the inviter or anyone else
+	for _, conn := range []*websocket.Conn{owner, other} {
+		writeEnvelope(t, conn, &imv1.ClientEnvelope{
+			Type:    imv1.MessageType_ACCEPT_GROUP,
+			Payload: &imv1.ClientEnvelope_AcceptGroup{AcceptGroup: &imv1.AcceptGroup{RequestId: "i1"}},
+		})
+		expectError(t, conn, protocol.CodePermissionDenied)
+	}
+
+	// declining tells the inviter and leaves the invitee out
+	writeEnvelope(t, invitee, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_REJECT_GROUP,
+		Payload: &imv1.ClientEnvelope_RejectGroup{RejectGroup: &imv1.RejectGroup{RequestId: "i1"}},
+	})
+	expectAck(t, invitee)
+	if ev := readEnvelope(t, owner).GetGroupEvent(); ev.GetKind() != imv1.GroupEvent_JOIN_REJECTED || ev.GetUser() != "u2" {
+		t.Fatalf("unexpected group event: %v", ev)
+	}
+	writeEnvelope(t, invitee, groupMessage("g1", "hi"))
+	expectError(t, invitee, protocol.CodeNotMember)
+
+	// a new invitation can be accepted
+	inviteAndJoin(t, owner, invitee, "g1", "u2", "i3")
+	writeEnvelope(t, invitee, groupMessage("g1", "hi"))
+	expectAck(t, invitee)
+}
@@ -0,0 +1,143 @@
+package integration
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/bootstrap"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/auth"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	proto "google.golang.org/protobuf/proto"
+)
+
+// startServer runs a ws server with default config; configure may override options.
+// It returns the websocket URL.
+func startServer(t *testing.T, configure func(*ws.ServerOptions)) string {
+	t.Helper()
+	cfg := bootstrap.DefaultConfig()
+	opts := ws.ServerOptions{
+		NodeID:         cfg.NodeID,
+		Path:           cfg.WSPath,
+		ReadLimitBytes: cfg.ReadLimitBytes,
+		SendQueueSize:  cfg.SendQueueSize,
+		WriteTimeout:   cfg.WriteTimeout,
+		PingInterval:   cfg.PingInterval,
+		PongWait:       cfg.PongWait,
+		Logger:         zap.NewNop(),
+		Registry:       ws.NewRegistry(),
+		Authenticator:  auth.NewDummyAuthenticator(),
+	}
+	if configure != nil {
+		configure(&opts)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(cfg.WSPath, ws.NewServer(opts))
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return "ws://" + strings.TrimPrefix(ts.URL, "http://") + cfg.WSPath
+}
+
+func dial(t *testing.T, u, token string) *websocket.Conn {
+	t.Helper()
+	h := make(http.Header)
+	h.Add("Authorization", "Bearer "+token)
+	conn, _, err := websocket.DefaultDialer.Dial(u, h)
+	if err != nil {
+		t.Fatalf("failed to dial websocket (%s): %v", token, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func writeEnvelope(t *testing.T, conn *websocket.Conn, env *imv1.ClientEnvelope) {
+	t.Helper()
+	b, err := protocol.EncodeClientMessage(env)
+	if err != nil {
+		t.Fatalf("failed to encode client message: %v", err)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, b); err != nil {
+		t.Fatalf("failed to write message: %v", err)
+	}
+}
+
+func readEnvelope(t *testing.T, conn *websocket.Conn) *imv1.ServerEnvelope {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, b, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	var env imv1.ServerEnvelope
+	if err := proto.Unmarshal(b, &env); err != nil {
+		t.Fatalf("failed to unmarshal server message: %v", err)
+	}
+	return &env
+}
+
+// expectAck reads the next envelope and fails unless it is an ack.
+func expectAck(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	env := readEnvelope(t, conn)
+	if _, ok := env.Payload.(*imv1.ServerEnvelope_AckResp); !ok {
+		t.Fatalf("expected ack_resp, got %v", env.Payload)
+	}
+}
+
+// expectError reads the next envelope and fails unless it is an error with code.
+func expectError(t *testing.T, conn *websocket.Conn, code string) {
+	t.Helper()
+	env := readEnvelope(t, conn)
+	e, ok := env.Payload.(*imv1.ServerEnvelope_Error)
+	if !ok {
+		t.Fatalf("expected error %s, got %v", code, env.Payload)
+	}
+	if e.Error.GetCode() != code {
+		t.Fatalf("expected error %s, got %s: %s", code, e.Error.GetCode(), e.Error.GetMessage())
+	}
+}
+
+// expectClose reads until the server closes the connection and fails unless
+// it closed with code.
+func expectClose(t *testing.T, conn *websocket.Conn, code int) {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var ce *websocket.CloseError
+		if !errors.As(err, &ce) || ce.Code != code {
+			t.Fatalf("expected close %d, got %v", code, err)
+		}
+		return
+	}
+}
+
+func readDelivery(t *testing.T, conn *websocket.Conn) *imv1.DeliverSingleMessage {
+	t.Helper()
+	env := readEnvelope(t, conn)
+	ds, ok := env.Payload.(*imv1.ServerEnvelope_DeliverSingleMessage)
+	if !ok {
+		t.Fatalf("expected deliver_single_message, got %T", env.Payload)
+	}
+	return ds.DeliverSingleMessage
+}
+
+func writeDeliveryAck(t *testing.T, conn *websocket.Conn, messageID int64) {
+	t.Helper()
+	writeEnvelope(t, conn, &imv1.ClientEnvelope{
+		Type: imv1.MessageType_DELIVERY_ACK,
+		Payload: &imv1.ClientEnvelope_DeliveryAck{
+			DeliveryAck: &imv1.DeliveryAck{MessageId: messageID},
+		},
+	})
+}
@@ -0,0 +1,163 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/gorilla/websocket"
+)
+
+func pullHistory(p *imv1.PullHistory) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_PULL_HISTORY,
+		Payload: &imv1.ClientEnvelope_PullHistory{PullHistory: p},
+	}
+}
+
+// readHistory reads a HistoryResp and returns its message texts.
+func readHistory(t *testing.T, conn *websocket.Conn) ([]string, *imv1.HistoryResp) {
+	t.Helper()
+	resp := readEnvelope(t, conn).GetHistoryResp()
+	if resp == nil {
+		t.Fatalf("expected history_resp")
+	}
+	texts := make([]string, 0, len(resp.GetMessages()))
+	for _, m := range resp.GetMessages() {
+		texts = append(texts, string(m.GetMessage()))
+	}
+	return texts, resp
+}
+
+func TestWS_History_CursorPagination(t *testing.T) {
+	messages := memory.NewMessageRepository()
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), messages, memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inboxSvc
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Inbox:    inboxSvc,
+			Groups:   groups,
+			History:  history.NewHistoryService(messages, groups),
+		})
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+	for i := 1; i <= 5; i++ {
+		writeEnvelope(t, conn1, singleMessage("", "u2", fmt.Sprintf("m%d", i)))
+		expectAck(t, conn1)
+	}
+	// a message to somebody else is not part of the conversation
+	writeEnvelope(t, conn1, singleMessage("", "u3", "other"))
+	expectAck(t, conn1)
+
+	// the recipient scrolls back two at a time
+	conn2 := dial(t, u, "test:u2:d1")
+	for range 5 {
+		readDelivery(t, conn2)
+	}
+	var (
+		got    []string
+		cursor int64
+	)
+	for {
+		writeEnvelope(t, conn2, pullHistory(&imv1.PullHistory{Peer: "u1", Cursor: cursor, Limit: 2}))
+		texts, resp := readHistory(t, conn2)
+		got = append(got, texts...)
+		if !resp.GetHasMore() {
+			break
+		}
+		cursor = resp.GetNextCursor()
+	}
+	if fmt.Sprint(got) != "[m5 m4 m3 m2 m1]" {
+		t.Fatalf("unexpected backward history: %v", got)
+	}
+
+	// and forward from the second message
+	writeEnvelope(t, conn2, pullHistory(&imv1.PullHistory{Peer: "u1", Cursor: cursor, Direction: imv1.PullHistory_FORWARD}))
+	if texts, resp := readHistory(t, conn2); fmt.Sprint(texts) != "[m3 m4 m5]" || resp.GetHasMore() {
+		t.Fatalf("unexpected forward history: %v", texts)
+	}
+
+	writeEnvelope(t, conn2, pullHistory(&imv1.PullHistory{}))
+	expectError(t, conn2, protocol.CodeBadRequest)
+
+	// group history is only readable by members
+	writeEnvelope(t, conn1, createGroup("g1", "group one"))
+	expectAck(t, conn1)
+	writeEnvelope(t, conn1, groupMessage("g1", "hello group"))
+	expectAck(t, conn1)
+	readEnvelope(t, conn1)
+
+	writeEnvelope(t, conn1, pullHistory(&imv1.PullHistory{GroupUuid: "g1"}))
+	if texts, _ := readHistory(t, conn1); fmt.Sprint(texts) != "[hello group]" {
+		t.Fatalf("unexpected group history: %v", texts)
+	}
+	writeEnvelope(t, conn2, pullHistory(&imv1.PullHistory{GroupUuid: "g1"}))
+	expectError(t, conn2, protocol.CodeNotMember)
+}
+
+func TestWS_Sync_ConversationSequences(t *testing.T) {
+	messages := memory.NewMessageRepository()
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), messages, memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Inbox:    inboxSvc,
+			History:  history.NewHistoryService(messages, nil),
+		})
+	})
+	phone := dial(t, u, "test:u1:d1")
+	laptop := dial(t, u, "test:u1:d2")
+	peer := dial(t, u, "test:u2:d1")
+
+	// both devices of u1 and u2 share one sequence per conversation
+	send := func(conn *websocket.Conn, to, text string) {
+		t.Helper()
+		writeEnvelope(t, conn, singleMessage("", to, text))
+		expectAck(t, conn)
+	}
+	send(phone, "u2", "m1")
+	send(laptop, "u2", "m2")
+	var seqs []int64
+	for range 2 {
+		seqs = append(seqs, readDelivery(t, peer).GetSeq())
+	}
+	send(peer, "u1", "m3")
+	seqs = append(seqs, readDelivery(t, phone).GetSeq(), readDelivery(t, laptop).GetSeq())
+	send(phone, "u2", "m4")
+	seqs = append(seqs, readDelivery(t, peer).GetSeq())
+	if fmt.Sprint(seqs) != "[1 2 3 3 4]" {
+		t.Fatalf("unexpected seqs: %v", seqs)
+	}
+
+	// another conversation starts its own sequence
+	writeEnvelope(t, phone, singleMessage("", "u3", "hi u3"))
+	expectAck(t, phone)
+
+	// u2 asks for seq 3 again
+	writeEnvelope(t, peer, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_SYNC,
+		Payload: &imv1.ClientEnvelope_Sync{Sync: &imv1.Sync{Peer: "u1", FromSeq: 3, ToSeq: 3}},
+	})
+	resp := readEnvelope(t, peer).GetSyncResp()
+	if len(resp.GetMessages()) != 1 || string(resp.GetMessages()[0].GetMessage()) != "m3" || resp.GetLastSeq() != 4 {
+		t.Fatalf("unexpected sync response: %v", resp)
+	}
+
+	writeEnvelope(t, phone, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_SYNC,
+		Payload: &imv1.ClientEnvelope_Sync{Sync: &imv1.Sync{Peer: "u3", FromSeq: 1, Limit: 10}},
+	})
+	resp = readEnvelope(t, phone).GetSyncResp()
+	if len(resp.GetMessages()) != 1 || resp.GetMessages()[0].GetSeq() != 1 || resp.GetLastSeq() != 1 || resp.GetHasMore() {
+		t.Fatalf("unexpected sync response: %v", resp)
+	}
+}
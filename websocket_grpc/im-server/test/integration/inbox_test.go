@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	domaininbox "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/inbox"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	inboxrepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+)
+
+func singleMessage(traceID, to, text string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		TraceId: traceID,
+		Type:    imv1.MessageType_SINGLE_MESSAGE,
+		Payload: &imv1.ClientEnvelope_SingleMessage{
+			SingleMessage: &imv1.SingleMessage{To: to, Message: []byte(text)},
+		},
+	}
+}
+
+func TestWS_SingleMessage_OfflineReplay(t *testing.T) {
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inbox.NewInboxService(memory.NewInboxRepository(), memory.NewMessageRepository(), memory.NewTransactor())
+	})
+
+	// sender u1, receiver u2 is offline
+	conn1 := dial(t, u, "test:u1:d1")
+	for _, text := range []string{"first", "second"} {
+		writeEnvelope(t, conn1, singleMessage("t-offline-"+text, "u2", text))
+		// wait for the ack so the message is persisted before u2 connects
+		expectAck(t, conn1)
+	}
+
+	// u2 comes online and gets both messages replayed in order, but only acks the first
+	conn2 := dial(t, u, "test:u2:d1")
+	var lastSeq int64
+	for _, want := range []string{"first", "second"} {
+		ds := readDelivery(t, conn2)
+		if ds.GetFrom() != "u1" {
+			t.Fatalf("expected from=u1, got %s", ds.GetFrom())
+		}
+		if !bytes.Equal(ds.GetMessage(), []byte(want)) {
+			t.Fatalf("expected %q, got %q", want, ds.GetMessage())
+		}
+		if ds.GetMessageId() == 0 || ds.GetSeq() <= lastSeq {
+			t.Fatalf("expected message id and increasing seq, got id=%d seq=%d", ds.GetMessageId(), ds.GetSeq())
+		}
+		lastSeq = ds.GetSeq()
+		if want == "first" {
+			writeDeliveryAck(t, conn2, ds.GetMessageId())
+		}
+	}
+	// let the server process the ack before the socket goes away
+	time.Sleep(50 * time.Millisecond)
+	conn2.Close()
+
+	// the unacked message is replayed again on reconnect
+	conn3 := dial(t, u, "test:u2:d1")
+	if ds := readDelivery(t, conn3); !bytes.Equal(ds.GetMessage(), []byte("second")) {
+		t.Fatalf("expected only the unacked message, got %q", ds.GetMessage())
+	}
+}
+
+func TestWS_SingleMessage_RetransmitUntilAck(t *testing.T) {
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inbox.NewInboxService(memory.NewInboxRepository(), memory.NewMessageRepository(), memory.NewTransactor())
+		o.Retransmit = ws.RetransmitPolicy{InitialBackoff: 50 * time.Millisecond, MaxBackoff: 100 * time.Millisecond}
+	})
+	conn2 := dial(t, u, "test:u2:d1")
+	conn1 := dial(t, u, "test:u1:d1")
+
+	writeEnvelope(t, conn1, singleMessage("t-retransmit", "u2", "hi"))
+
+	first := readDelivery(t, conn2)
+	again := readDelivery(t, conn2)
+	if again.GetMessageId() != first.GetMessageId() {
+		t.Fatalf("expected retransmit of %d, got %d", first.GetMessageId(), again.GetMessageId())
+	}
+	writeDeliveryAck(t, conn2, first.GetMessageId())
+
+	// at most one retransmit may race the ack, then the server must go quiet
+	extra := 0
+	for {
+		_ = conn2.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+		if _, _, err := conn2.ReadMessage(); err != nil {
+			break
+		}
+		if extra++; extra > 1 {
+			t.Fatalf("delivery still retransmitted after ack")
+		}
+	}
+}
+
+// gatedInbox holds the first replay of user until open is closed.
+type gatedInbox struct {
+	inboxrepo.InboxRepository
+	user    string
+	once    sync.Once
+	entered chan struct{}
+	open    chan struct{}
+}
+
+func (r *gatedInbox) ListUndelivered(ctx context.Context, userID string, afterID int64, limit int) ([]*domaininbox.Inbox, error) {
+	if userID == r.user {
+		r.once.Do(func() {
+			close(r.entered)
+			<-r.open
+		})
+	}
+	return r.InboxRepository.ListUndelivered(ctx, userID, afterID, limit)
+}
+
+func TestWS_SingleMessage_LiveAfterReplay(t *testing.T) {
+	inboxes := &gatedInbox{InboxRepository: memory.NewInboxRepository(), user: "u2", entered: make(chan struct{}), open: make(chan struct{})}
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inbox.NewInboxService(inboxes, memory.NewMessageRepository(), memory.NewTransactor())
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+	writeEnvelope(t, conn1, singleMessage("t-old", "u2", "old"))
+	expectAck(t, conn1)
+
+	// a message sent while u2's inbox is being replayed comes after it
+	conn2 := dial(t, u, "test:u2:d1")
+	<-inboxes.entered
+	writeEnvelope(t, conn1, singleMessage("t-new", "u2", "new"))
+	expectAck(t, conn1)
+	close(inboxes.open)
+
+	for _, want := range []string{"old", "new"} {
+		if ds := readDelivery(t, conn2); !bytes.Equal(ds.GetMessage(), []byte(want)) {
+			t.Fatalf("expected %q, got %q", want, ds.GetMessage())
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+)
+
+func mentionMessage(uuid, text string, mentions ...*imv1.Mention) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_GROUP_MESSAGE,
+		Payload: &imv1.ClientEnvelope_GroupMessage{GroupMessage: &imv1.GroupMessage{
+			Uuid:    uuid,
+			Content: &imv1.Content{Body: &imv1.Content_Text{Text: &imv1.TextContent{Text: text, Mentions: mentions}}},
+		}},
+	}
+}
+
+func setGroupNotify(uuid string, level imv1.GroupNotify) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_SET_GROUP_NOTIFY,
+		Payload: &imv1.ClientEnvelope_SetGroupNotify{SetGroupNotify: &imv1.SetGroupNotify{Uuid: uuid, Level: level}},
+	}
+}
+
+func TestWS_Group_Mentions(t *testing.T) {
+	groups := group.NewGroupService(memory.NewGroupRepository(), newUsers(t, "u1", "u2", "u3"), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Groups: groups})
+	})
+	owner := dial(t, u, "test:u1:d1")
+	member := dial(t, u, "test:u2:d1")
+	other := dial(t, u, "test:u3:d1")
+	conns := map[string]*websocket.Conn{"u1": owner, "u2": member, "u3": other}
+
+	writeEnvelope(t, owner, createGroup("g1", "group one"))
+	expectAck(t, owner)
+	for _, user := range []string{"u2", "u3"} {
+		inviteAndJoin(t, owner, conns[user], "g1", user, "i-"+user)
+	}
+
+	// expectMentioned reads the delivery of every member, including the
+	// sender's own copy.
+	expectMentioned := func(want map[string]bool) {
+		t.Helper()
+		for user, conn := range conns {
+			got := readEnvelope(t, conn).GetDeliverGroupMessage()
+			if got == nil || got.GetMentioned() != want[user] {
+				t.Fatalf("%s: expected mentioned=%v, got %v", user, want[user], got)
+			}
+		}
+	}
+
+	// a member mentions u3 only
+	writeEnvelope(t, member, mentionMessage("g1", "hi @u3", &imv1.Mention{User: "u3", Offset: 3, Length: 3}))
+	expectAck(t, member)
+	expectMentioned(map[string]bool{"u3": true})
+
+	// @all is reserved to admins
+	writeEnvelope(t, member, mentionMessage("g1", "@all hi", &imv1.Mention{All: true, Offset: 0, Length: 4}))
+	expectError(t, member, protocol.CodePermissionDenied)
+	writeEnvelope(t, owner, mentionMessage("g1", "@all hi", &imv1.Mention{All: true, Offset: 0, Length: 4}))
+	expectAck(t, owner)
+	expectMentioned(map[string]bool{"u1": true, "u2": true, "u3": true})
+
+	// a mention names a user or all, never both
+	writeEnvelope(t, owner, mentionMessage("g1", "@all hi", &imv1.Mention{User: "u2", All: true, Offset: 0, Length: 4}))
+	expectError(t, owner, protocol.CodeBadRequest)
+
+	// @all makes no sense outside a group
+	writeEnvelope(t, owner, contentMessage("u2", &imv1.Content{Body: &imv1.Content_Text{Text: &imv1.TextContent{
+		Text:     "@all hi",
+		Mentions: []*imv1.Mention{{All: true, Offset: 0, Length: 4}},
+	}}}))
+	expectError(t, owner, protocol.CodeBadRequest)
+}
+
+func TestWS_Group_Notify(t *testing.T) {
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{Registry: o.Registry, Groups: groups})
+	})
+	owner := dial(t, u, "test:u1:d1")
+	outsider := dial(t, u, "test:u2:d1")
+
+	writeEnvelope(t, owner, createGroup("g1", "group one"))
+	expectAck(t, owner)
+
+	writeEnvelope(t, owner, setGroupNotify("g1", imv1.GroupNotify_GROUP_NOTIFY_MENTIONS))
+	expectAck(t, owner)
+	writeEnvelope(t, owner, setGroupNotify("g1", imv1.GroupNotify(42)))
+	expectError(t, owner, protocol.CodeBadRequest)
+	writeEnvelope(t, outsider, setGroupNotify("g1", imv1.GroupNotify_GROUP_NOTIFY_NONE))
+	expectError(t, outsider, protocol.CodeNotMember)
+	writeEnvelope(t, owner, setGroupNotify("missing", imv1.GroupNotify_GROUP_NOTIFY_NONE))
+	expectError(t, owner, protocol.CodeNotFound)
+}
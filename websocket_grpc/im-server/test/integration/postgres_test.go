@@ -0,0 +1,33 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/bootstrap"
+)
+
+// Startup gives up on a database that never answers instead of serving
+// without one.
+func TestBootstrap_UnreachableDatabase(t *testing.T) {
+	cfg := bootstrap.DefaultConfig()
+	cfg.PostgresDSN = "postgres://im:im@127.0.0.1:1/im?sslmode=disable"
+	cfg.Postgres.ConnectTimeout = 500 * time.Millisecond
+	cfg.Postgres.ConnectAttempts = 3
+	cfg.Postgres.ConnectBackoff = 10 * time.Millisecond
+
+	start := time.Now()
+	app, err := bootstrap.New(context.Background(), cfg)
+	if err == nil {
+		_ = app.Close()
+		t.Fatal("expected startup to fail")
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Fatalf("expected backoff between attempts, took %v", elapsed)
+	}
+}
@@ -0,0 +1,124 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/cluster"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/friend"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/presence"
+	"github.com/gorilla/websocket"
+)
+
+const presenceGrace = 200 * time.Millisecond
+
+func startPresenceServer(t *testing.T) string {
+	t.Helper()
+	friendRepo := memory.NewFriendRepository()
+	if err := friendRepo.AddFriend(context.Background(), "u1", "u2"); err != nil {
+		t.Fatalf("failed to add friend: %v", err)
+	}
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	historySvc := history.NewHistoryService(memory.NewMessageRepository(), groups)
+	return startServer(t, func(o *ws.ServerOptions) {
+		router := cluster.NewRouter(cluster.RouterOptions{Registry: o.Registry})
+		friends := friend.NewFriendService(friendRepo, memory.NewUserRepository(), memory.NewTransactor())
+		presenceSvc := presence.NewPresenceService(router, friends, presence.PresenceOptions{Grace: presenceGrace})
+		o.Router = router
+		o.Registry = presence.NewRegistry(o.Registry, presenceSvc, nil)
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Router:   router,
+			Typing:   presence.NewTypingService(historySvc, groups, friends, presence.TypingOptions{Interval: time.Minute}),
+		})
+	})
+}
+
+func typing(peer string, start bool) *imv1.ClientEnvelope {
+	mt := imv1.MessageType_TYPING_STOP
+	if start {
+		mt = imv1.MessageType_TYPING_START
+	}
+	return &imv1.ClientEnvelope{
+		Type:    mt,
+		Payload: &imv1.ClientEnvelope_Typing{Typing: &imv1.Typing{Conversation: &imv1.Conversation{Peer: peer}}},
+	}
+}
+
+func expectPresence(t *testing.T, conn *websocket.Conn, user string, online bool) {
+	t.Helper()
+	ev := readEnvelope(t, conn).GetPresenceEvent()
+	if ev.GetUser() != user || ev.GetOnline() != online {
+		t.Fatalf("unexpected presence event: %v", ev)
+	}
+}
+
+func TestWS_Presence_FriendsOnlineOffline(t *testing.T) {
+	u := startPresenceServer(t)
+	watcher := dial(t, u, "test:u2:d1")
+	probe := dial(t, u, "test:u2:d2")
+
+	conn := dial(t, u, "test:u1:d1")
+	expectPresence(t, watcher, "u1", true)
+	expectPresence(t, probe, "u1", true)
+	// a second device is no transition
+	second := dial(t, u, "test:u1:d2")
+	second.Close()
+
+	// a reconnect within the grace period is invisible
+	conn.Close()
+	conn = dial(t, u, "test:u1:d1")
+	_ = probe.SetReadDeadline(time.Now().Add(2 * presenceGrace))
+	if _, _, err := probe.ReadMessage(); err == nil {
+		t.Fatalf("a quick reconnect should not produce presence events")
+	}
+
+	conn.Close()
+	expectPresence(t, watcher, "u1", false)
+}
+
+func TestWS_Typing_Coalesced(t *testing.T) {
+	u := startPresenceServer(t)
+	receiver := dial(t, u, "test:u2:d1")
+	sender := dial(t, u, "test:u1:d1")
+	expectPresence(t, receiver, "u1", true)
+
+	writeEnvelope(t, sender, typing("u2", true))
+	writeEnvelope(t, sender, typing("u2", true))
+	writeEnvelope(t, sender, typing("u2", false))
+	writeEnvelope(t, sender, typing("u2", false))
+	writeEnvelope(t, sender, typing("u2", true))
+
+	for _, want := range []bool{true, false, true} {
+		ev := readEnvelope(t, receiver).GetTypingEvent()
+		if ev.GetUser() != "u1" || ev.GetConversation().GetPeer() != "u1" || ev.GetTyping() != want {
+			t.Fatalf("unexpected typing event, want typing=%v: %v", want, ev)
+		}
+	}
+	_ = receiver.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, _, err := receiver.ReadMessage(); err == nil {
+		t.Fatalf("repeated indicators should be coalesced")
+	}
+}
+
+func TestWS_Typing_NeedsFriendship(t *testing.T) {
+	u := startPresenceServer(t)
+	stranger := dial(t, u, "test:u3:d1")
+	expectOpen(t, stranger) // bound before it could be notified
+	sender := dial(t, u, "test:u1:d1")
+
+	writeEnvelope(t, sender, typing("u3", true))
+	expectError(t, sender, protocol.CodeForbidden)
+	_ = stranger.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if _, _, err := stranger.ReadMessage(); err == nil {
+		t.Fatalf("strangers should not see typing indicators")
+	}
+}
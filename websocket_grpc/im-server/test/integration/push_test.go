@@ -0,0 +1,288 @@
+package integration
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	domaingroup "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/group"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/filesystem"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/webhook"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/push"
+)
+
+// recordingNotifier hands every notification it is given to a channel.
+type recordingNotifier struct {
+	got chan push.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, batch []push.Notification) error {
+	for _, p := range batch {
+		n.got <- p
+	}
+	return nil
+}
+
+func (n *recordingNotifier) Close() error {
+	return nil
+}
+
+func (n *recordingNotifier) next(t *testing.T) push.Notification {
+	t.Helper()
+	select {
+	case p := <-n.got:
+		return p
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a push notification")
+		return push.Notification{}
+	}
+}
+
+func TestWS_Push_OfflineRecipients(t *testing.T) {
+	ctx := context.Background()
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), memory.NewMessageRepository(), memory.NewTransactor())
+	notifier := &recordingNotifier{got: make(chan push.Notification, 100)}
+	pushes := push.NewPushService(notifier, groups, push.Options{FlushInterval: 10 * time.Millisecond})
+	t.Cleanup(func() { _ = pushes.Close() })
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inboxSvc
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Inbox:    inboxSvc,
+			Groups:   groups,
+			Push:     pushes,
+		})
+	})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn3 := dial(t, u, "test:u3:d1")
+
+	// u3 is online and gets no push; offline u2 does. The queue keeps the
+	// order, so u2's notification coming first proves u3 had none.
+	writeEnvelope(t, conn1, contentMessage("u3", &imv1.Content{Body: &imv1.Content_Text{Text: &imv1.TextContent{Text: "hello"}}}))
+	expectAck(t, conn1)
+	readDelivery(t, conn3)
+	writeEnvelope(t, conn1, contentMessage("u2", &imv1.Content{Body: &imv1.Content_Text{Text: &imv1.TextContent{Text: "hi"}}}))
+	expectAck(t, conn1)
+	if n := notifier.next(t); n.UserID != "u2" || n.From != "u1" || n.Preview != "hi" || n.MessageID == 0 || n.GroupUUID != "" {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+
+	// In the group u2 muted everything and u4 only wants mentions; u3 is
+	// online and the sender is never alerted.
+	if _, err := groups.Create(ctx, "u1", "g1", "group one"); err != nil {
+		t.Fatal(err)
+	}
+	for _, uid := range []string{"u2", "u3", "u4", "u5"} {
+		if err := groups.AddMember(ctx, "g1", uid); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := groups.SetNotify(ctx, "g1", "u2", domaingroup.NotifyNone); err != nil {
+		t.Fatal(err)
+	}
+	if err := groups.SetNotify(ctx, "g1", "u4", domaingroup.NotifyMentions); err != nil {
+		t.Fatal(err)
+	}
+	sendGroup := func(env *imv1.ClientEnvelope) {
+		t.Helper()
+		writeEnvelope(t, conn1, env)
+		expectAck(t, conn1)
+		readEnvelope(t, conn1) // the sender's own copy
+		readEnvelope(t, conn3)
+	}
+
+	sendGroup(groupMessage("g1", "plain"))
+	if n := notifier.next(t); n.UserID != "u5" || n.GroupUUID != "g1" || n.Mentioned {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+
+	sendGroup(mentionMessage("g1", "hi @u4", &imv1.Mention{User: "u4", Offset: 3, Length: 3}))
+	got := map[string]push.Notification{}
+	for range 2 {
+		n := notifier.next(t)
+		got[n.UserID] = n
+	}
+	if !got["u4"].Mentioned || got["u5"].Mentioned || got["u4"].Preview != "hi @u4" || len(got) != 2 {
+		t.Fatalf("unexpected notifications: %+v", got)
+	}
+
+	// nothing else was queued
+	writeEnvelope(t, conn1, groupMessage("g1", "last"))
+	expectAck(t, conn1)
+	if n := notifier.next(t); n.UserID != "u5" {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+}
+
+func TestPush_WebhookRetriesAndDedup(t *testing.T) {
+	secret := []byte("relay-secret")
+	statuses := []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusBadRequest, http.StatusOK}
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	bodies := make(chan []push.Notification, len(statuses))
+	relay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		if r.Header.Get(webhook.SignatureHeader) != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("bad signature %q", r.Header.Get(webhook.SignatureHeader))
+		}
+		var req struct {
+			Notifications []push.Notification `json:"notifications"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("bad body %q: %v", body, err)
+		}
+
+		mu.Lock()
+		status := statuses[requests]
+		requests++
+		mu.Unlock()
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			bodies <- req.Notifications
+		}
+	}))
+	t.Cleanup(relay.Close)
+
+	pushes := push.NewPushService(webhook.NewNotifier(webhook.Options{URL: relay.URL, Secret: secret}), nil, push.Options{
+		FlushInterval: 10 * time.Millisecond,
+		RetryBackoff:  10 * time.Millisecond,
+	})
+	t.Cleanup(func() { _ = pushes.Close() })
+	expectBatch := func(users ...string) {
+		t.Helper()
+		select {
+		case batch := <-bodies:
+			if len(batch) != len(users) {
+				t.Fatalf("expected %v, got %+v", users, batch)
+			}
+			for i, n := range batch {
+				if n.UserID != users[i] {
+					t.Fatalf("expected %v, got %+v", users, batch)
+				}
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for the relay")
+		}
+	}
+
+	// the first attempt fails with 503 and is retried; the duplicate is dropped
+	pushes.Enqueue(context.Background(), []push.Notification{
+		{UserID: "u1", MessageID: 1},
+		{UserID: "u2", MessageID: 1},
+		{UserID: "u1", MessageID: 1},
+	})
+	expectBatch("u1", "u2")
+	pushes.Enqueue(context.Background(), []push.Notification{{UserID: "u1", MessageID: 1}})
+
+	// a 400 is final, so the next batch is the fourth request
+	pushes.Enqueue(context.Background(), []push.Notification{{UserID: "u3", MessageID: 2}})
+	for deadline := time.Now().Add(2 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		mu.Lock()
+		rejected := requests == 3
+		mu.Unlock()
+		if rejected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the rejected batch")
+		}
+	}
+	pushes.Enqueue(context.Background(), []push.Notification{{UserID: "u4", MessageID: 3}})
+	expectBatch("u4")
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != len(statuses) {
+		t.Fatalf("expected %d requests, got %d", len(statuses), requests)
+	}
+}
+
+// flakyNotifier fails its first call, then records like recordingNotifier.
+type flakyNotifier struct {
+	recordingNotifier
+	failed chan struct{} // closed by the first call
+	once   sync.Once
+}
+
+func (n *flakyNotifier) Notify(ctx context.Context, batch []push.Notification) error {
+	first := false
+	n.once.Do(func() { first = true })
+	if first {
+		close(n.failed)
+		return errors.New("gateway unavailable")
+	}
+	return n.recordingNotifier.Notify(ctx, batch)
+}
+
+// A batch waiting to be retried does not hold up the notifications queued
+// after it.
+func TestPush_RetryDoesNotBlockQueue(t *testing.T) {
+	notifier := &flakyNotifier{
+		recordingNotifier: recordingNotifier{got: make(chan push.Notification, 10)},
+		failed:            make(chan struct{}),
+	}
+	pushes := push.NewPushService(notifier, nil, push.Options{
+		FlushInterval: 10 * time.Millisecond,
+		RetryBackoff:  300 * time.Millisecond,
+	})
+	t.Cleanup(func() { _ = pushes.Close() })
+
+	pushes.Enqueue(context.Background(), []push.Notification{{UserID: "u1", MessageID: 1}})
+	select {
+	case <-notifier.failed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the failed attempt")
+	}
+	pushes.Enqueue(context.Background(), []push.Notification{{UserID: "u2", MessageID: 2}})
+
+	// u2 goes out while u1 waits for its retry
+	if n := notifier.next(t); n.UserID != "u2" {
+		t.Fatalf("expected u2 before the retry, got %+v", n)
+	}
+	if n := notifier.next(t); n.UserID != "u1" {
+		t.Fatalf("expected the retried u1, got %+v", n)
+	}
+}
+
+func TestPush_LogFlushesOnClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "push.jsonl")
+	notifier, err := filesystem.NewPushLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushes := push.NewPushService(notifier, nil, push.Options{FlushInterval: time.Hour})
+	pushes.Enqueue(context.Background(), []push.Notification{{UserID: "u1", MessageID: 7, From: "u2"}})
+	if err := pushes.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n push.Notification
+	if err := json.Unmarshal(data, &n); err != nil || n.UserID != "u1" || n.MessageID != 7 || n.From != "u2" {
+		t.Fatalf("unexpected log %q: %v", data, err)
+	}
+}
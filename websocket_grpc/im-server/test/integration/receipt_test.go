@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/group"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/history"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/receipt"
+	"github.com/gorilla/websocket"
+)
+
+func markRead(c *imv1.Conversation, seq int64) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_MARK_READ,
+		Payload: &imv1.ClientEnvelope_MarkRead{MarkRead: &imv1.MarkRead{Conversation: c, Seq: seq}},
+	}
+}
+
+func unreadOf(t *testing.T, conn *websocket.Conn, c *imv1.Conversation) *imv1.UnreadCount {
+	t.Helper()
+	writeEnvelope(t, conn, &imv1.ClientEnvelope{
+		Type: imv1.MessageType_GET_UNREAD,
+		Payload: &imv1.ClientEnvelope_GetUnread{
+			GetUnread: &imv1.GetUnread{Conversations: []*imv1.Conversation{c}},
+		},
+	})
+	counts := readEnvelope(t, conn).GetUnreadResp().GetCounts()
+	if len(counts) != 1 {
+		t.Fatalf("expected one unread count, got %v", counts)
+	}
+	return counts[0]
+}
+
+func TestWS_Receipt_MarkReadAndUnread(t *testing.T) {
+	messages := memory.NewMessageRepository()
+	groups := group.NewGroupService(memory.NewGroupRepository(), memory.NewUserRepository(), memory.NewTransactor())
+	inboxSvc := inbox.NewInboxService(memory.NewInboxRepository(), messages, memory.NewTransactor())
+	historySvc := history.NewHistoryService(messages, groups)
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry: o.Registry,
+			Inbox:    inboxSvc,
+			Groups:   groups,
+			History:  historySvc,
+			Receipts: receipt.NewReceiptService(memory.NewReadCursorRepository(), messages, historySvc, groups),
+		})
+	})
+	sender := dial(t, u, "test:u1:d1")
+	phone := dial(t, u, "test:u2:d1")
+	tablet := dial(t, u, "test:u2:d2")
+
+	for _, text := range []string{"m1", "m2", "m3"} {
+		writeEnvelope(t, sender, singleMessage("", "u2", text))
+		expectAck(t, sender)
+		readDelivery(t, phone)
+		readDelivery(t, tablet)
+	}
+	writeEnvelope(t, phone, singleMessage("", "u1", "reply"))
+	expectAck(t, phone)
+	readDelivery(t, sender)
+
+	// own messages do not count as unread
+	withU1 := &imv1.Conversation{Peer: "u1"}
+	if c := unreadOf(t, phone, withU1); c.GetUnread() != 3 || c.GetReadSeq() != 0 || c.GetLastSeq() != 4 {
+		t.Fatalf("unexpected unread count: %v", c)
+	}
+
+	// the sender and all of u2's devices get a receipt
+	writeEnvelope(t, phone, markRead(withU1, 2))
+	expectAck(t, phone)
+	r := readEnvelope(t, sender).GetReadReceipt()
+	if r.GetReader() != "u2" || r.GetConversation().GetPeer() != "u2" || r.GetSeq() != 2 {
+		t.Fatalf("unexpected receipt at sender: %v", r)
+	}
+	for _, conn := range []*websocket.Conn{phone, tablet} {
+		r = readEnvelope(t, conn).GetReadReceipt()
+		if r.GetReader() != "u2" || r.GetConversation().GetPeer() != "u1" || r.GetSeq() != 2 {
+			t.Fatalf("unexpected receipt at own device: %v", r)
+		}
+	}
+	if c := unreadOf(t, phone, withU1); c.GetUnread() != 1 || c.GetReadSeq() != 2 {
+		t.Fatalf("unexpected unread count: %v", c)
+	}
+
+	// cursors never move backwards and stop at the last message
+	writeEnvelope(t, tablet, markRead(withU1, 1))
+	expectAck(t, tablet)
+	writeEnvelope(t, tablet, markRead(withU1, 9))
+	expectError(t, tablet, protocol.CodeBadRequest)
+	writeEnvelope(t, tablet, markRead(withU1, 0))
+	expectAck(t, tablet)
+	if r := readEnvelope(t, sender).GetReadReceipt(); r.GetSeq() != 4 {
+		t.Fatalf("expected a receipt up to the last message, got %v", r)
+	}
+	readEnvelope(t, phone)
+	readEnvelope(t, tablet)
+	if c := unreadOf(t, phone, withU1); c.GetUnread() != 0 {
+		t.Fatalf("unexpected unread count: %v", c)
+	}
+
+	// group read state needs membership
+	writeEnvelope(t, sender, createGroup("g1", "group one"))
+	expectAck(t, sender)
+	writeEnvelope(t, phone, markRead(&imv1.Conversation{GroupUuid: "g1"}, 0))
+	expectError(t, phone, protocol.CodeNotMember)
+}
@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/dispatch"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/revision"
+)
+
+func startRevisionServer(t *testing.T, opts revision.Options) string {
+	t.Helper()
+	messages := memory.NewMessageRepository()
+	inboxes := memory.NewInboxRepository()
+	inboxSvc := inbox.NewInboxService(inboxes, messages, memory.NewTransactor())
+	return startServer(t, func(o *ws.ServerOptions) {
+		o.Inbox = inboxSvc
+		o.Dispatch = dispatch.NewDefaultDispatcher(dispatch.Deps{
+			Registry:  o.Registry,
+			Inbox:     inboxSvc,
+			Revisions: revision.NewRevisionService(messages, inboxes, memory.NewTransactor(), opts),
+		})
+	})
+}
+
+func recallMessage(messageID int64) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_RECALL_MESSAGE,
+		Payload: &imv1.ClientEnvelope_RecallMessage{RecallMessage: &imv1.RecallMessage{MessageId: messageID}},
+	}
+}
+
+func editMessage(messageID int64, text string) *imv1.ClientEnvelope {
+	return &imv1.ClientEnvelope{
+		Type: imv1.MessageType_EDIT_MESSAGE,
+		Payload: &imv1.ClientEnvelope_EditMessage{
+			EditMessage: &imv1.EditMessage{MessageId: messageID, Message: []byte(text)},
+		},
+	}
+}
+
+func TestWS_Revision_EditAndRecall(t *testing.T) {
+	u := startRevisionServer(t, revision.Options{})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn2 := dial(t, u, "test:u2:d1")
+
+	writeEnvelope(t, conn1, singleMessage("", "u2", "helo"))
+	expectAck(t, conn1)
+	original := readDelivery(t, conn2)
+	writeDeliveryAck(t, conn2, original.GetMessageId())
+
+	// the edit reaches the recipient under the same ID and sequence
+	writeEnvelope(t, conn1, editMessage(original.GetMessageId(), "hello"))
+	expectAck(t, conn1)
+	edited := readDelivery(t, conn2)
+	if edited.GetMessageId() != original.GetMessageId() || edited.GetSeq() != original.GetSeq() {
+		t.Fatalf("edit should keep id and seq, got id=%d seq=%d", edited.GetMessageId(), edited.GetSeq())
+	}
+	if string(edited.GetMessage()) != "hello" || edited.GetEditedAt() == 0 || edited.GetRecalled() {
+		t.Fatalf("unexpected edited delivery: %v", edited)
+	}
+
+	// only the sender may change a message
+	conn3 := dial(t, u, "test:u3:d1")
+	writeEnvelope(t, conn3, recallMessage(original.GetMessageId()))
+	expectError(t, conn3, protocol.CodePermissionDenied)
+	writeEnvelope(t, conn3, recallMessage(999))
+	expectError(t, conn3, protocol.CodeNotFound)
+
+	// a recall while the recipient is offline is replayed as a tombstone
+	writeDeliveryAck(t, conn2, edited.GetMessageId())
+	time.Sleep(50 * time.Millisecond)
+	conn2.Close()
+	writeEnvelope(t, conn1, recallMessage(original.GetMessageId()))
+	expectAck(t, conn1)
+
+	conn2 = dial(t, u, "test:u2:d1")
+	tombstone := readDelivery(t, conn2)
+	if tombstone.GetMessageId() != original.GetMessageId() || !tombstone.GetRecalled() || len(tombstone.GetMessage()) != 0 {
+		t.Fatalf("expected a tombstone, got %v", tombstone)
+	}
+
+	// a tombstone cannot be edited, and recalling it again is a no-op
+	writeEnvelope(t, conn1, editMessage(original.GetMessageId(), "again"))
+	expectError(t, conn1, protocol.CodeConflict)
+	writeEnvelope(t, conn1, recallMessage(original.GetMessageId()))
+	expectAck(t, conn1)
+}
+
+func TestWS_Revision_WindowExpired(t *testing.T) {
+	u := startRevisionServer(t, revision.Options{RecallWindow: 50 * time.Millisecond, EditWindow: 50 * time.Millisecond})
+	conn1 := dial(t, u, "test:u1:d1")
+	conn2 := dial(t, u, "test:u2:d1")
+
+	writeEnvelope(t, conn1, singleMessage("", "u2", "hello"))
+	expectAck(t, conn1)
+	ds := readDelivery(t, conn2)
+
+	time.Sleep(100 * time.Millisecond)
+	writeEnvelope(t, conn1, editMessage(ds.GetMessageId(), "too late"))
+	expectError(t, conn1, protocol.CodeConflict)
+	writeEnvelope(t, conn1, recallMessage(ds.GetMessageId()))
+	expectError(t, conn1, protocol.CodeConflict)
+}
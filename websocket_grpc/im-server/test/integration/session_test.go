@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/contract"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/transport/ws/protocol"
+	imv1 "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/gen/im/v1"
+	"github.com/gorilla/websocket"
+)
+
+// expectKicked reads the Kicked event and the close that follows it.
+func expectKicked(t *testing.T, conn *websocket.Conn, reason imv1.Kicked_Reason, byDevice string) {
+	t.Helper()
+	k := readEnvelope(t, conn).GetKicked()
+	if k.GetReason() != reason || k.GetDeviceId() != byDevice {
+		t.Fatalf("unexpected kicked event: %v", k)
+	}
+	expectClose(t, conn, protocol.CloseKicked)
+}
+
+// expectOpen fails unless conn still answers an echo.
+func expectOpen(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	writeEnvelope(t, conn, &imv1.ClientEnvelope{
+		Type:    imv1.MessageType_ECHO,
+		Payload: &imv1.ClientEnvelope_Echo{Echo: &imv1.Echo{Message: []byte("ping")}},
+	})
+	if _, ok := readEnvelope(t, conn).Payload.(*imv1.ServerEnvelope_Echo); !ok {
+		t.Fatalf("expected the session to stay open")
+	}
+}
+
+func startPolicyServer(t *testing.T, policy ws.DevicePolicy) string {
+	t.Helper()
+	return startServer(t, func(o *ws.ServerOptions) {
+		o.Registry = ws.NewRegistryWithPolicy(func(string) ws.DevicePolicy { return policy })
+	})
+}
+
+func TestWS_Session_SameDeviceReplaced(t *testing.T) {
+	u := startServer(t, nil)
+	old := dial(t, u, "test:u1:d1")
+	expectOpen(t, old)
+	conn := dial(t, u, "test:u1:d1")
+	expectKicked(t, old, imv1.Kicked_SAME_DEVICE, "d1")
+
+	// the closing old session must not unbind its replacement
+	sender := dial(t, u, "test:u2:d1")
+	writeEnvelope(t, sender, singleMessage("t1", "u1", "still there"))
+	if ds := readDelivery(t, conn); !bytes.Equal(ds.GetMessage(), []byte("still there")) {
+		t.Fatalf("unexpected delivery: %q", ds.GetMessage())
+	}
+}
+
+func TestWS_Session_DevicePolicies(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		u := startPolicyServer(t, ws.DevicePolicy{Mode: ws.DeviceModeSingle})
+		d1 := dial(t, u, "test:u1:d1")
+		expectOpen(t, d1)
+		d2 := dial(t, u, "test:u1:d2")
+		expectKicked(t, d1, imv1.Kicked_OTHER_DEVICE, "d2")
+		expectOpen(t, d2)
+	})
+
+	t.Run("per platform", func(t *testing.T) {
+		u := startPolicyServer(t, ws.DevicePolicy{Mode: ws.DeviceModePerPlatform})
+		phone := dial(t, u, "test:u1:d1:ios")
+		expectOpen(t, phone)
+		web := dial(t, u, "test:u1:d2:web")
+		expectOpen(t, web)
+		// the platform comes with the token; the query cannot override it
+		tablet := dial(t, u+"?platform=other", "test:u1:d3:ios")
+		expectKicked(t, phone, imv1.Kicked_OTHER_DEVICE, "d3")
+		expectOpen(t, web)
+		expectOpen(t, tablet)
+
+		_, resp, err := websocket.DefaultDialer.Dial(u, http.Header{"Authorization": {"Bearer test:u1:d4:toaster"}})
+		if err == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected an unknown platform to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("max devices", func(t *testing.T) {
+		u := startPolicyServer(t, ws.DevicePolicy{Mode: ws.DeviceModeMulti, MaxDevices: 2})
+		d1 := dial(t, u, "test:u1:d1")
+		expectOpen(t, d1)
+		d2 := dial(t, u, "test:u1:d2")
+		expectOpen(t, d2)
+		d3 := dial(t, u, "test:u1:d3")
+		expectKicked(t, d1, imv1.Kicked_OTHER_DEVICE, "d3")
+		expectOpen(t, d2)
+		expectOpen(t, d3)
+	})
+}
+
+// failingRegistry refuses every session.
+type failingRegistry struct {
+	ws.Registry
+}
+
+func (failingRegistry) Bind(context.Context, contract.Session) ([]contract.Session, error) {
+	return nil, errors.New("registry unavailable")
+}
+
+func TestWS_Session_BindFailureCloses(t *testing.T) {
+	u := startServer(t, func(o *ws.ServerOptions) {
+		o.Registry = failingRegistry{Registry: o.Registry}
+	})
+	conn := dial(t, u, "test:u1:d1")
+	expectClose(t, conn, websocket.CloseInternalServerErr)
+}
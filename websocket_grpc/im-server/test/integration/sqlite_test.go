@@ -0,0 +1,41 @@
+package integration
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/app/bootstrap"
+)
+
+// Without a DSN, SQLitePath keeps the data in a file across restarts.
+func TestBootstrap_SQLite(t *testing.T) {
+	cfg := bootstrap.DefaultConfig()
+	cfg.SQLitePath = filepath.Join(t.TempDir(), "im.db")
+	cfg.Blob.Dir = "" // keep attachments out of the source tree
+
+	app, err := bootstrap.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.DB != nil || app.SQLite == nil {
+		t.Fatalf("expected sqlite only, got %v and %v", app.DB, app.SQLite)
+	}
+	if _, err := app.SQLite.Exec("INSERT INTO groups (uuid, name, owner, created_at, updated_at) VALUES ('g1', 'group one', 'u1', ?, ?)", time.Now(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err = bootstrap.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Close()
+	var name string
+	if err := app.SQLite.QueryRow("SELECT name FROM groups WHERE uuid = 'g1'").Scan(&name); err != nil || name != "group one" {
+		t.Fatalf("group lost across restarts: %q, %v", name, err)
+	}
+}
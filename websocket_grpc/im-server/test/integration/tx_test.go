@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domaininbox "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/inbox"
+	domainmessage "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/domain/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/infra/memory"
+	inboxrepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/inbox"
+	messagerepo "github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/respository/message"
+	"github.com/brianliu-sysu/golang-knowledge/websocket_grpc/im-server/internal/services/inbox"
+)
+
+type inTx struct{}
+
+// markingTransactor marks the context of the unit so the repositories can
+// tell they were called within it.
+type markingTransactor struct {
+	units int
+	err   error // what the last unit returned
+}
+
+func (t *markingTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.units++
+	t.err = fn(context.WithValue(ctx, inTx{}, true))
+	return t.err
+}
+
+type txMessages struct {
+	messagerepo.MessageRepository
+	t *testing.T
+}
+
+func (r txMessages) CreateMessage(ctx context.Context, m *domainmessage.Message) error {
+	if ctx.Value(inTx{}) == nil {
+		r.t.Error("message stored outside the unit")
+	}
+	return r.MessageRepository.CreateMessage(ctx, m)
+}
+
+type txInboxes struct {
+	inboxrepo.InboxRepository
+	t    *testing.T
+	fail error
+}
+
+func (r txInboxes) CreateInboxes(ctx context.Context, inboxes []*domaininbox.Inbox) error {
+	if ctx.Value(inTx{}) == nil {
+		r.t.Error("inboxes stored outside the unit")
+	}
+	if r.fail != nil {
+		return r.fail
+	}
+	return r.InboxRepository.CreateInboxes(ctx, inboxes)
+}
+
+func TestInbox_StoreIsOneUnit(t *testing.T) {
+	ctx := context.Background()
+	tx := &markingTransactor{}
+	inboxes := memory.NewInboxRepository()
+	svc := inbox.NewInboxService(txInboxes{InboxRepository: inboxes, t: t}, txMessages{memory.NewMessageRepository(), t}, tx)
+
+	msg := &domainmessage.Message{FromUser: "u1", GroupUUID: "g1", Content: []byte("hi")}
+	entries, err := svc.Store(ctx, msg, []string{"u1", "u2", "u3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.units != 1 || len(entries) != 3 {
+		t.Fatalf("expected 3 entries in 1 unit, got %d in %d", len(entries), tx.units)
+	}
+	for i, uid := range []string{"u1", "u2", "u3"} {
+		if entries[i].UserID != uid || entries[i].MessageID != msg.ID || entries[i].ID == 0 {
+			t.Fatalf("unexpected entry %d: %+v", i, entries[i])
+		}
+	}
+
+	// the unit sees the failure of the fan-out, so the message is rolled back
+	boom := errors.New("boom")
+	svc = inbox.NewInboxService(txInboxes{InboxRepository: inboxes, t: t, fail: boom}, txMessages{memory.NewMessageRepository(), t}, tx)
+	if _, err := svc.Store(ctx, &domainmessage.Message{FromUser: "u1", ToUser: "u2"}, []string{"u2"}); !errors.Is(err, boom) {
+		t.Fatalf("expected %v, got %v", boom, err)
+	}
+	if !errors.Is(tx.err, boom) {
+		t.Fatalf("expected the unit to fail with %v, got %v", boom, tx.err)
+	}
+}
@@ -2,6 +2,7 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"net/http"
@@ -50,6 +51,35 @@ func TestWS_Unauthenticated(t *testing.T) {
 	}
 }
 
+// The shipped defaults only accept issued tokens; development tokens need
+// AllowDummyAuth.
+func TestBootstrap_DummyAuth(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		cfg := bootstrap.DefaultConfig()
+		cfg.Blob.Dir = ""
+		cfg.AllowDummyAuth = allow
+		app, err := bootstrap.New(context.Background(), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ts := httptest.NewServer(app.WS)
+
+		h := http.Header{"Authorization": {"Bearer test:u1:d1"}}
+		conn, resp, err := websocket.DefaultDialer.Dial("ws://"+strings.TrimPrefix(ts.URL, "http://")+cfg.WSPath, h)
+		switch {
+		case allow && err != nil:
+			t.Errorf("dummy token rejected with AllowDummyAuth: %v", err)
+		case !allow && (err == nil || resp.StatusCode != http.StatusUnauthorized):
+			t.Errorf("dummy token accepted by default")
+		}
+		if conn != nil {
+			_ = conn.Close()
+		}
+		ts.Close()
+		_ = app.Close()
+	}
+}
+
 func TestWS_Echo_OK(t *testing.T) {
 	cfg := bootstrap.DefaultConfig()
 	reg := ws.NewRegistry()